@@ -0,0 +1,100 @@
+package inject
+
+import (
+	"runtime"
+
+	"github.com/defval/inject/v2/di"
+)
+
+// Builder assembles container options by chained calls. It is an alternative to the options slice of New(): the
+// builder accumulates the same options, so both styles have the same features. Conditional providers do not need
+// temporary slices.
+//
+//   container, err := inject.NewBuilder().
+//     Provide(NewServer).
+//     Supply(cfg).
+//     Module(accounts.Module).
+//     If(cfg.Debug, func(b *inject.Builder) {
+//       b.Provide(NewDebugHandler, inject.As(new(http.Handler)))
+//     }).
+//     Build()
+//
+// Provided values and structs are located at the builder calls in errors and diagnostics, constructors are located
+// at their declarations.
+type Builder struct {
+	options []Option
+}
+
+// Module registers providers of a package into the builder.
+//
+//   func Module(b *inject.Builder) {
+//     b.Provide(NewAccountController)
+//     b.Provide(NewAccountRepository)
+//   }
+type Module func(b *Builder)
+
+// NewBuilder creates an empty container builder.
+func NewBuilder() *Builder {
+	return &Builder{}
+}
+
+// Provide adds provider like inject.Provide().
+func (b *Builder) Provide(provider interface{}, options ...ProvideOption) *Builder {
+	b.options = append(b.options, provideAt(callerLocation(provider), provider, options))
+	return b
+}
+
+// Supply adds already created value like inject.Supply().
+func (b *Builder) Supply(value interface{}, options ...ProvideOption) *Builder {
+	var location di.Location
+	if _, file, line, ok := runtime.Caller(1); ok {
+		location = di.Location{File: file, Line: line}
+	}
+	b.options = append(b.options, supplyAt(location, value, options))
+	return b
+}
+
+// Apply adds container options, for example inject.Bind() or inject.Eager().
+func (b *Builder) Apply(options ...Option) *Builder {
+	b.options = append(b.options, options...)
+	return b
+}
+
+// Module calls modules with the builder.
+func (b *Builder) Module(modules ...Module) *Builder {
+	for _, module := range modules {
+		module(b)
+	}
+	return b
+}
+
+// ModuleWithLifetime calls modules with the builder and sets default lifetime of their providers like
+// inject.ModuleLifetime().
+//
+//   b.ModuleWithLifetime(inject.SingletonLifetime, storage.Module)
+func (b *Builder) ModuleWithLifetime(lifetime Lifetime, modules ...Module) *Builder {
+	module := &Builder{}
+	module.Module(modules...)
+	b.options = append(b.options, ModuleLifetime(lifetime, module.options...))
+	return b
+}
+
+// If calls fn with the builder if condition is true.
+func (b *Builder) If(condition bool, fn func(b *Builder)) *Builder {
+	if condition {
+		fn(b)
+	}
+	return b
+}
+
+// Options returns accumulated container options.
+func (b *Builder) Options() []Option {
+	return append([]Option(nil), b.options...)
+}
+
+// Build creates a container with accumulated options. It returns errors like NewWithDiagnostics(), use errors.As()
+// with ErrCompileFailed to get diagnostics.
+func (b *Builder) Build() (*Container, error) {
+	container, _, err := NewWithDiagnostics(b.options...)
+	return container, err
+}
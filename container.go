@@ -1,26 +1,157 @@
 package inject
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
 	"github.com/defval/inject/v2/di"
 )
 
 // New creates a new container with provided options.
 func New(options ...Option) *Container {
 	var c = &Container{
-		container: di.New(),
+		container:   di.New(),
+		deprecation: os.Stderr,
+		retryLog:    os.Stderr,
+	}
+	c.assemble(options)
+	return c
+}
+
+// Diagnostics are findings of container assembly, see NewWithDiagnostics().
+type Diagnostics = di.Diagnostics
+
+// ErrCompileFailed is an error of NewWithDiagnostics(). Its Diagnostics() are the failed stage and the error finding.
+type ErrCompileFailed = di.ErrCompileFailed
+
+// NewWithDiagnostics creates a new container like New() but returns the assembly failure as error instead of panic.
+// Diagnostics of the failure have the failed stage: provider validation, binding, connection of dependencies, cycle
+// check or build of inject.Eager() container, and the error finding with the key and the location of the definition.
+// Diagnostics of the created container are issues tolerated by compile, see Report(). New() panics with the message
+// of the error.
+//
+//   container, diagnostics, err := inject.NewWithDiagnostics(options...)
+//   if err != nil {
+//     json.NewEncoder(os.Stderr).Encode(diagnostics)
+//     os.Exit(1)
+//   }
+func NewWithDiagnostics(options ...Option) (*Container, *Diagnostics, error) {
+	var c = &Container{
+		container:   di.New(),
+		deprecation: os.Stderr,
+		retryLog:    os.Stderr,
+	}
+	if err := c.container.Recover(func() { c.assemble(options) }); err != nil {
+		return nil, err.(ErrCompileFailed).Diagnostics(), err
 	}
-	// apply options.
+	diagnostics := &Diagnostics{Findings: []di.Finding{}}
+	if c.compiled {
+		diagnostics.Findings = c.Report().Findings
+	}
+	return c, diagnostics, nil
+}
+
+// SetQualifiedTypeNames renders all types in errors, dumps and graphs with package paths. By default only types with
+// the same name from different packages, for example two `config.Config` types, are rendered with package paths.
+func SetQualifiedTypeNames(enabled bool) {
+	di.SetQualifiedTypeNames(enabled)
+}
+
+// assemble applies options, compiles and runs the container unless compile is deferred.
+func (c *Container) assemble(options []Option) {
 	for _, opt := range options {
 		opt.apply(c)
 	}
-	c.compile()
-	return c
+	if !c.deferCompile {
+		c.Compile()
+	}
+	if c.eager && !c.deferCompile {
+		c.run()
+	}
+}
+
+// run builds, wires and starts the container. Created instances are cleaned up if a phase fails.
+func (c *Container) run() {
+	for _, phase := range []func() error{c.BuildAll, c.Wire, func() error { return c.Start(context.Background()) }} {
+		if err := phase(); err != nil {
+			c.Cleanup()
+			panic(err)
+		}
+	}
 }
 
 // Container is a dependency injection container.
 type Container struct {
-	providers []provide
-	container *di.Container
+	mu           sync.Mutex // guards options applying and compile
+	providers    []provide
+	binds        []bind
+	container    *di.Container
+	deferCompile bool
+	eager        bool // New builds, wires and starts the container
+	compiled     bool
+	roots        []interface{}   // roots of subset
+	entryPoints  []interface{}   // prune roots
+	maxDepth     int             // resolution depth limit, zero is the default one
+	unexported   bool            // allow unexported fields of parameter structs
+	compileLog   io.Writer       // compile summary output
+	graphLog     io.Writer       // resolved graph output
+	lifetime     Lifetime        // default lifetime of definitions
+	module       *Lifetime       // default lifetime of applied module options
+	deprecation  io.Writer       // deprecation warnings output, nil disables warnings
+	retryLog     io.Writer       // failed retry attempts output, nil disables the log
+	dev          io.Writer       // development mode warnings output, nil disables development mode
+	strict       bool            // resolution of deprecated definitions fails
+	recoverMode  RecoverMode     // panic policy
+	convert      bool            // missing types resolve by conversion
+	explicit     bool            // interfaces resolve only by explicit bindings
+	fallbacks    []*Container    // containers that resolve missing types
+	profiles     []string        // profiles of applied options
+	active       map[string]bool // active profiles
+}
+
+// Apply applies options to the container that created with inject.DeferCompile(). Use it to assemble the container
+// in several steps.
+//
+//   container := inject.New(inject.DeferCompile())
+//   container.Apply(inject.Provide(NewServer))
+//   if debug {
+//     container.Apply(inject.Provide(NewDebugHandler, inject.As(new(http.Handler))))
+//   }
+//   container.Compile()
+//
+// Apply causes panic with di.ErrContainerCompiled if the container is compiled.
+func (c *Container) Apply(options ...Option) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.compiled {
+		panic(di.ErrContainerCompiled)
+	}
+	for _, opt := range options {
+		opt.apply(c)
+	}
+}
+
+// Provide provides constructor to the container that created with inject.DeferCompile(). It is a shortcut for
+// c.Apply(inject.Provide(provider, options...)).
+func (c *Container) Provide(provider interface{}, options ...ProvideOption) {
+	c.Apply(Provide(provider, options...))
+}
+
+// Compile wires container definitions. The container created by New() is already compiled, use inject.DeferCompile()
+// to compile it explicitly. Compile causes panic on the same errors as New(). Repeated calls do nothing.
+func (c *Container) Compile() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.compiled {
+		return
+	}
+	c.compile()
+	c.compiled = true
 }
 
 // Extract populates given target pointer with type instance provided in the container.
@@ -41,25 +172,599 @@ func (c *Container) Extract(target interface{}, options ...ExtractOption) (err e
 	return c.container.Extract(target, params)
 }
 
+// ExtractNamed extracts target of definition with name like Extract() with Name() option. The name is a parameter, so
+// it could not be dropped with a forgotten option. It overrides Name() option.
+//
+//   var primary *sql.DB
+//   err := container.ExtractNamed("primary", &primary)
+func (c *Container) ExtractNamed(name string, target interface{}, options ...ExtractOption) error {
+	return c.Extract(target, append(options[:len(options):len(options)], Name(name))...)
+}
+
+// ExtractOr extracts target like Extract() but fills target with fallback if the target type does not exist in a
+// container. The fallback is not provided into the container. Errors of instance building are returned as is.
+//
+//   var tracer Tracer
+//   if err := container.ExtractOr(&tracer, noopTracer{}); err != nil {
+//     // building of tracer failed
+//   }
+func (c *Container) ExtractOr(target interface{}, fallback interface{}, options ...ExtractOption) error {
+	var params = di.ExtractParams{}
+	for _, opt := range options {
+		opt.apply(&params)
+	}
+	return c.container.ExtractOr(target, fallback, params)
+}
+
+// ExtractIfPresent extracts target like Extract() and reports whether the target type exists in a container. If the
+// type is not provided, ExtractIfPresent returns false and nil error and the target is not changed. Errors of
+// instance building are returned with true.
+//
+//   var flags *FeatureFlagsClient
+//   if ok, err := container.ExtractIfPresent(&flags); ok && err == nil {
+//     // use feature flags
+//   }
+func (c *Container) ExtractIfPresent(target interface{}, options ...ExtractOption) (bool, error) {
+	err := c.Extract(target, options...)
+	if notProvided(err) {
+		return false, nil
+	}
+	return true, err
+}
+
+// Has checks that target type could be extracted with the same options. Has does not create instances.
+//
+//   if container.Has(new(*Metrics)) {
+//     // extract metrics
+//   }
+func (c *Container) Has(target interface{}, options ...ExtractOption) bool {
+	var params = di.ExtractParams{}
+	for _, opt := range options {
+		opt.apply(&params)
+	}
+	return c.container.Has(target, params)
+}
+
+// Explain writes how the target type resolves: the used definition and its dependencies as indented tree with
+// lifetime and built markers. If the resolution would fail, Explain writes the tree down to the failed type and
+// returns the same error as Extract(). Explain does not create instances.
+//
+//   var server *http.Server
+//   container.Explain(&server, os.Stderr)
+func (c *Container) Explain(target interface{}, w io.Writer, options ...ExtractOption) error {
+	var params = di.ExtractParams{}
+	for _, opt := range options {
+		opt.apply(&params)
+	}
+	return c.container.Explain(target, w, params)
+}
+
+// Build calls constructor with parameters resolved from the container and from extras and returns its result without
+// registering it. Parameters of types that do not resolve from the container take the first unused extra assignable
+// to their type. Extras of inject.PreferExtras() option are used even if their types resolve.
+//
+//   // func NewOrderProcessor(db *sql.DB, orderID string) *OrderProcessor
+//   processor, err := container.Build(NewOrderProcessor, orderID)
+func (c *Container) Build(constructor interface{}, extras ...interface{}) (interface{}, error) {
+	var params di.BuildParams
+	var arguments []interface{}
+	for _, extra := range extras {
+		if opt, ok := extra.(BuildOption); ok {
+			opt.apply(&params)
+			continue
+		}
+		arguments = append(arguments, extra)
+	}
+	return c.container.Build(constructor, arguments, params)
+}
+
+// BuildAll creates all singletons in construction order, dependencies go first. It is the first of initialization
+// phases: BuildAll(), Wire() and Start(). Setters are not called until Wire(), so constructors must not use references
+// of inject.Setter(). Without phases, instances are created and wired on the first resolution.
+//
+//   if err := container.BuildAll(); err != nil {
+//     container.Cleanup()
+//     return err
+//   }
+//   if err := container.Wire(); err != nil {
+//     ...
+//   }
+//   if err := container.Start(ctx); err != nil {
+//     ...
+//   }
+func (c *Container) BuildAll() error {
+	return c.container.BuildAll()
+}
+
+// BuildContext is BuildAll() that stops on ctx cancellation. Constructor in progress returns before the build stops,
+// then instances created so far are cleaned up. The error wraps ctx.Err() with the number of built definitions. Use it
+// to stop slow startup on shutdown signal:
+//
+//   ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+//   defer stop()
+//   if err := container.BuildContext(ctx); err != nil {
+//     return err // build canceled after 3 of 8 definitions: context canceled
+//   }
+//
+// Canceled container must not be used.
+func (c *Container) BuildContext(ctx context.Context) error {
+	return c.container.BuildContext(ctx)
+}
+
+// ErrWarmUp is an error of WarmUp() with errors of failed roots.
+type ErrWarmUp = di.ErrWarmUp
+
+// WarmUp creates instances of targets and their dependencies, so the first extraction of them does not wait for
+// constructors. Other types stay lazy. Targets are pointers like Extract() targets. Errors of all failed targets are
+// returned together with their dependency paths.
+//
+//   // readiness probe
+//   if err := container.WarmUp(new(*http.Server), new(*sql.DB)); err != nil {
+//     w.WriteHeader(http.StatusServiceUnavailable)
+//   }
+func (c *Container) WarmUp(targets ...interface{}) error {
+	return c.container.WarmUp(targets...)
+}
+
+// WarmUpParallel is WarmUp() that resolves each target in its own goroutine.
+func (c *Container) WarmUpParallel(targets ...interface{}) error {
+	return c.container.WarmUpParallel(targets...)
+}
+
+// Fingerprint returns a hash of the resolved graph: definitions with lifetimes and constructors, dependencies,
+// implementations chosen for interfaces and order of members of groups that definitions depend on. Assemblies of the
+// same options in different order have equal fingerprints, unless members of such groups without inject.Order()
+// change their order.
+//
+//   require.Equal(t, first.Fingerprint(), second.Fingerprint())
+func (c *Container) Fingerprint() string {
+	return c.container.Fingerprint()
+}
+
+// Prepare computes reflection metadata of all definitions, for example parsed tags of parameter structs, so the first
+// resolution only waits for constructors. Unlike WarmUp() it does not create instances.
+func (c *Container) Prepare() error {
+	return c.container.Prepare()
+}
+
+// Wire calls setters of singletons created by BuildAll(), see inject.Setter(). All singletons exist, so setters could
+// refer to any of them. Wire fails if the container is not built.
+func (c *Container) Wire() error {
+	return c.container.Wire()
+}
+
+// Start calls Start(ctx) of singletons that implement inject.Starter in construction order, so dependencies are
+// started first. All singletons are created and wired. Start fails if the container is not wired and stops on the
+// first error.
+func (c *Container) Start(ctx context.Context) error {
+	return c.container.Start(ctx)
+}
+
 // Invoke invokes custom function. Dependencies of function will be resolved via container.
 func (c *Container) Invoke(fn interface{}) error {
 	return c.container.Invoke(fn)
 }
 
+// WithOverrides creates derived container with applied overrides, calls fn with it and cleanups the derived container
+// after fn returns. Use inject.Replace() options to substitute definitions.
+//
+//   container.WithOverrides(func(c *inject.Container) {
+//     var service *Service
+//     c.Extract(&service) // service uses fake clock
+//   }, inject.Replace(NewFakeClock))
+//
+// The derived container shares singletons of the container that do not depend on replaced definitions. Other
+// singletons are created again inside the derived container and cleanups run only for them. The container is not
+// modified, so WithOverrides can be used by parallel tests. Settings of the container like active profiles, module
+// lifetime and inject.Eager() apply to the derived container too.
+func (c *Container) WithOverrides(fn func(c *Container), overrides ...Option) {
+	c.mu.Lock()
+	derived := &Container{
+		providers:   append([]provide(nil), c.providers...),
+		binds:       append([]bind(nil), c.binds...),
+		container:   di.New(),
+		eager:       c.eager,
+		entryPoints: c.entryPoints,
+		maxDepth:    c.maxDepth,
+		unexported:  c.unexported,
+		compileLog:  c.compileLog,
+		graphLog:    c.graphLog,
+		lifetime:    c.lifetime,
+		module:      c.module,
+		deprecation: c.deprecation,
+		retryLog:    c.retryLog,
+		dev:         c.dev,
+		strict:      c.strict,
+		recoverMode: c.recoverMode,
+		convert:     c.convert,
+		explicit:    c.explicit,
+		fallbacks:   c.fallbacks,
+		active:      map[string]bool{},
+	}
+	for name := range c.active {
+		derived.active[name] = true
+	}
+	c.mu.Unlock()
+	for _, opt := range overrides {
+		opt.apply(derived)
+	}
+	derived.Compile()
+	derived.container.Inherit(c.container)
+	defer derived.container.Cleanup()
+	if len(c.roots) != 0 {
+		// overrides of subset are applied to the subset
+		subset, err := derived.container.Subset(c.roots...)
+		if err != nil {
+			panic(err)
+		}
+		defer subset.Cleanup()
+		derived.container = subset
+	}
+	if derived.eager {
+		derived.run()
+	}
+	fn(derived)
+}
+
+// Subset creates a container that contains only the roots and their transitive dependencies. Roots are pointers to
+// types like Extract() targets. Use it to build a part of a large application, for example, a command of CLI.
+//
+//   subset, err := container.Subset(new(*MigrateCommand))
+//   if err != nil {
+//     // root type not exists
+//   }
+//   var command *MigrateCommand
+//   subset.Extract(&command)
+//
+// Definitions outside of the dependencies are absent in the subset. The subset shares singletons of the container,
+// their cleanup belongs to the container.
+func (c *Container) Subset(roots ...interface{}) (*Container, error) {
+	subset, err := c.container.Subset(roots...)
+	if err != nil {
+		return nil, err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return &Container{
+		providers: append([]provide(nil), c.providers...),
+		binds:     append([]bind(nil), c.binds...),
+		container: subset,
+		compiled:  true,
+		roots:     append([]interface{}(nil), roots...),
+	}, nil
+}
+
+// DefinitionInfo is a snapshot of a registered definition.
+type DefinitionInfo = di.DefinitionInfo
+
+// Key identifies a definition by type and name.
+type Key = di.Key
+
+// Lifetime is a lifetime of definition instances.
+type Lifetime = di.Lifetime
+
+const (
+	// SingletonLifetime instance is created once.
+	SingletonLifetime = di.Singleton
+	// PrototypeLifetime instance is created on each resolution.
+	PrototypeLifetime = di.Prototype
+)
+
+// LifetimeSource is a source of the effective lifetime of definition, see DefinitionInfo.
+type LifetimeSource = di.LifetimeSource
+
+// Location is a source code position of the constructor.
+type Location = di.Location
+
+// RecoverMode is a policy of panics in constructors and invoked functions.
+type RecoverMode = di.RecoverMode
+
+const (
+	// Propagate does not recover panics, they crash with the original stack.
+	Propagate = di.Propagate
+	// WrapError recovers panics and returns them as ErrPanic.
+	WrapError = di.WrapError
+)
+
+// Starter is implemented by instances that start their work after the container is wired, see Start().
+type Starter = di.Starter
+
+// ErrPanic is a recovered panic with its value and stack, see WithRecover().
+type ErrPanic = di.ErrPanic
+
+// ErrNotFound is an error of type that does not exist in container. Use errors.As() to find it in Extract() error,
+// the target could be a value or a pointer:
+//
+//   var notFound inject.ErrNotFound // or *inject.ErrNotFound
+//   if errors.As(err, &notFound) {
+//     // fall back to notFound.Type() resolution
+//   }
+//
+// The requested type, name and candidate definitions are returned by Type(), Name() and Candidates() methods.
+type ErrNotFound = di.ErrParameterProviderNotFound
+
+// Definitions returns snapshots of container definitions in registration order.
+func (c *Container) Definitions() []DefinitionInfo {
+	return c.container.Definitions()
+}
+
+// ImplementationsOf returns snapshots of definitions provided as the interface, in the order of the interface slice
+// elements. Use it for plugin listings or to check which implementations a module registered.
+//
+//   infos, err := container.ImplementationsOf(new(Exporter))
+func (c *Container) ImplementationsOf(iface interface{}) ([]DefinitionInfo, error) {
+	return c.container.ImplementationsOf(iface)
+}
+
+// CompileReport is a summary of the compiled container, see Report().
+type CompileReport = di.CompileReport
+
+// Report returns summary of the compiled container: counts of definitions, interfaces and groups, the longest
+// resolution chain, nodes with the widest fan-in and fan-out, issues tolerated by compile and compile duration. The
+// report is JSON serializable and sorted, so reports of releases could be compared.
+//
+//   report := container.Report()
+//   json.NewEncoder(os.Stdout).Encode(report)
+func (c *Container) Report() CompileReport {
+	return c.container.Report()
+}
+
+// Stats is a snapshot of container statistics, see Stats().
+type Stats = di.Stats
+
+// Stats returns statistics of the container: numbers of definitions by lifetime, instances built by constructors,
+// resolutions with failures and durations of constructor calls. Collection does not allocate on resolution, so
+// long-running services could export the snapshot as gauges. Growing number of built instances per request points to
+// unexpected prototypes.
+//
+//   stats := container.Stats()
+//   builtGauge.Set(float64(stats.Built))
+func (c *Container) Stats() Stats {
+	return c.container.Stats()
+}
+
+// Keys returns keys of container definitions in registration order. Interfaces of definitions are marked as aliases.
+func (c *Container) Keys() []Key {
+	return c.container.Keys()
+}
+
+// String represents container definitions as table: type, name, lifetime, number of dependencies and built marker.
+//
+//   fmt.Println(container)
+func (c *Container) String() string {
+	return c.container.String()
+}
+
+// DebugDump writes dependency tree of the container into writer. Long type names are truncated, use inject.Verbose()
+// to print them in full. DebugDump does not create instances.
+//
+//   container.DebugDump(os.Stderr, inject.Verbose())
+func (c *Container) DebugDump(w io.Writer, options ...DumpOption) error {
+	var params di.DumpParams
+	for _, opt := range options {
+		opt.apply(&params)
+	}
+	return c.container.DebugDump(w, params)
+}
+
 // Cleanup cleanup container.
 func (c *Container) Cleanup() {
 	c.container.Cleanup()
 }
 
+// ReleaseInstances drops references to created singletons and cleanups after Cleanup(), so the heap could be reclaimed
+// while the container is kept for diagnostics. Definitions stay registered for introspection, provided values are not
+// released. Next resolution creates instances again, use inject.RejectResolution() to fail it with di.ErrReleased.
+//
+//   container.Cleanup()
+//   err := container.ReleaseInstances(inject.RejectResolution())
+func (c *Container) ReleaseInstances(options ...ReleaseOption) error {
+	var params di.ReleaseParams
+	for _, opt := range options {
+		opt.apply(&params)
+	}
+	return c.container.ReleaseInstances(params)
+}
+
 func (c *Container) compile() {
-	for _, po := range c.providers {
-		c.container.Provide(po.provider, po.params)
+	if c.unexported {
+		c.container.AllowUnexported()
+	}
+	c.container.SetDefaultLifetime(c.lifetime)
+	c.container.SetDevMode(c.dev)
+	providers, binds := c.activeProviders()
+	var replacements []provide
+	for _, po := range providers {
+		if po.params.IsReplacement {
+			replacements = append(replacements, po)
+			continue
+		}
+		c.provideDefinition(po)
+	}
+	for _, b := range binds {
+		c.container.Bind(b.iface, b.implementation, di.BindParams{Name: b.name})
+	}
+	// replacements are provided after bindings, so they could replace interfaces bound by inject.Bind()
+	for _, po := range replacements {
+		c.provideDefinition(po)
+	}
+	c.container.Provide(newResolver, di.ProvideParams{IsSingleton: true})
+	c.container.Prune(c.entryPoints...)
+	if c.maxDepth != 0 {
+		c.container.SetMaxDepth(c.maxDepth)
+	}
+	if c.compileLog != nil {
+		c.container.SetCompileLog(c.compileLog)
+	}
+	if c.graphLog != nil {
+		c.container.SetGraphLog(c.graphLog)
+	}
+	c.container.SetDeprecationLog(c.deprecation)
+	c.container.SetRetryLog(c.retryLog)
+	if c.strict {
+		c.container.SetStrictDeprecation()
+	}
+	c.container.SetRecoverMode(c.recoverMode)
+	if c.convert {
+		c.container.SetAutoConvert()
+	}
+	if c.explicit {
+		c.container.SetExplicitBindings()
+	}
+	for _, fallback := range c.fallbacks {
+		c.container.SetFallbacks(fallback.container)
 	}
 	c.container.Compile()
 	return
 }
 
+// provideDefinition adds provider into di container. Panics of bulk call members are prefixed with the member.
+func (c *Container) provideDefinition(po provide) {
+	if po.member != "" {
+		defer func() {
+			if r := recover(); r != nil {
+				panic(fmt.Sprintf("%s: %v", po.member, r))
+			}
+		}()
+	}
+	switch provider := po.provider.(type) {
+	case typeTemplate:
+		c.container.ProvideType(provider.template, po.params)
+	default:
+		if po.value {
+			c.container.ProvideValue(po.provider, po.params)
+			return
+		}
+		if typ := reflect.TypeOf(provider); typ != nil && (typ.Kind() == reflect.Struct || typ.Kind() == reflect.Ptr && typ.Elem().Kind() == reflect.Struct) {
+			c.container.ProvideStruct(provider, po.params)
+			return
+		}
+		c.container.Provide(po.provider, po.params)
+	}
+}
+
 type provide struct {
 	provider interface{}
 	params   di.ProvideParams
+	value    bool     // provider is a value
+	profiles []string // provider is added if all profiles are active
+	member   string   // provider is a member of bulk call like `inject.Providers[3] (func NewFoo)`
+}
+
+type bind struct {
+	iface          interface{}
+	implementation interface{}
+	name           string   // name of the interface key
+	profiles       []string // bind is added if all profiles are active
+}
+
+// activeProviders returns providers and binds of active profiles. Providers of the same type from different profiles
+// cause panic that lists both profiles.
+func (c *Container) activeProviders() ([]provide, []bind) {
+	var providers []provide
+	added := map[string]provide{}
+	for _, po := range c.providers {
+		if !c.isActive(po.profiles) {
+			continue
+		}
+		providers = append(providers, po)
+		typ := providedType(po)
+		if typ == nil || po.params.IsReplacement || po.params.IsAppend || po.params.IsDedup {
+			continue
+		}
+		k := typ.String()
+		if po.params.Name != "" {
+			k = fmt.Sprintf("%s[%s]", k, po.params.Name)
+		}
+		if len(po.params.Tags) != 0 {
+			k = fmt.Sprintf("%s{%s}", k, po.params.Tags)
+		}
+		if previous, exists := added[k]; exists && profilesString(previous.profiles) != profilesString(po.profiles) {
+			panic(fmt.Sprintf("The `%s` type is provided in %s and in %s", k, profilesString(previous.profiles),
+				profilesString(po.profiles)))
+		}
+		added[k] = po
+	}
+	var binds []bind
+	for _, b := range c.binds {
+		if c.isActive(b.profiles) {
+			binds = append(binds, b)
+		}
+	}
+	return providers, binds
+}
+
+// isActive checks that all profiles are active.
+func (c *Container) isActive(profiles []string) bool {
+	for _, name := range profiles {
+		if !c.active[name] {
+			return false
+		}
+	}
+	return true
+}
+
+// providedType returns type of provider definition or nil if the provider is incorrect.
+func providedType(po provide) reflect.Type {
+	if template, ok := po.provider.(typeTemplate); ok {
+		return reflect.TypeOf(template.template)
+	}
+	typ := reflect.TypeOf(po.provider)
+	if typ == nil || po.value || typ.Kind() != reflect.Func {
+		return typ
+	}
+	if typ.NumOut() == 0 {
+		return nil
+	}
+	return typ.Out(0)
+}
+
+// profilesString represents profiles for errors.
+func profilesString(profiles []string) string {
+	if len(profiles) == 0 {
+		return "no profile"
+	}
+	if len(profiles) == 1 {
+		return fmt.Sprintf("profile `%s`", profiles[0])
+	}
+	return fmt.Sprintf("profiles `%s`", strings.Join(profiles, "`, `"))
+}
+
+// Resolver is a read-only view of the container. The container provides it, so components that locate services at
+// runtime depend on inject.Resolver instead of the container: they extract types, but could not change or clean up
+// the container.
+//
+//   func NewJobRunner(resolver inject.Resolver) *JobRunner {
+//     return &JobRunner{resolver: resolver}
+//   }
+type Resolver interface {
+	Extract(target interface{}, options ...ExtractOption) error
+	Has(target interface{}, options ...ExtractOption) bool
+}
+
+// newResolver creates resolver of the underlying container. The container resolver is not shared with derived
+// containers, so the resolver of derived container extracts its own types.
+func newResolver(container di.Resolver) Resolver {
+	return resolver{container: container}
+}
+
+// resolver adapts the underlying container resolver to inject options.
+type resolver struct {
+	container di.Resolver
+}
+
+func (r resolver) Extract(target interface{}, options ...ExtractOption) error {
+	var params = di.ExtractParams{}
+	for _, opt := range options {
+		opt.apply(&params)
+	}
+	return r.container.Extract(target, params)
+}
+
+func (r resolver) Has(target interface{}, options ...ExtractOption) bool {
+	var params = di.ExtractParams{}
+	for _, opt := range options {
+		opt.apply(&params)
+	}
+	return r.container.Has(target, params)
 }
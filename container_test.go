@@ -1,14 +1,26 @@
 package inject_test
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/defval/inject/v2"
+	"github.com/defval/inject/v2/di"
 )
 
 func TestContainer(t *testing.T) {
@@ -32,6 +44,1558 @@ func TestContainer(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestContainerOptionOrder(t *testing.T) {
+	options := []inject.Option{
+		inject.Provide(NewMux, inject.As(new(http.Handler)), inject.Order(2)),
+		inject.Provide(NewFileServer, inject.As(new(http.Handler)), inject.Order(1), inject.Primary()),
+		inject.Provide(NewRedirect, inject.As(new(http.Handler))),
+	}
+	reversed := []inject.Option{options[2], options[1], options[0]}
+
+	for _, opts := range [][]inject.Option{options, reversed} {
+		c := inject.New(opts...)
+
+		var handler http.Handler
+		require.NoError(t, c.Extract(&handler))
+		require.IsType(t, FileServer{}, handler)
+
+		var handlers []http.Handler
+		require.NoError(t, c.Extract(&handlers))
+		require.Len(t, handlers, 3)
+		require.IsType(t, Redirect{}, handlers[0])
+		require.IsType(t, FileServer{}, handlers[1])
+		require.IsType(t, &http.ServeMux{}, handlers[2])
+	}
+}
+
+func TestContainerBind(t *testing.T) {
+	c := inject.New(
+		inject.Bind(new(http.Handler), new(http.ServeMux)),
+		inject.Provide(NewMux),
+	)
+
+	var mux *http.ServeMux
+	require.NoError(t, c.Extract(&mux))
+	var handler http.Handler
+	require.NoError(t, c.Extract(&handler))
+	require.Equal(t, fmt.Sprintf("%p", mux), fmt.Sprintf("%p", handler))
+}
+
+func TestContainerDeferCompile(t *testing.T) {
+	t.Run("container compiles explicitly", func(t *testing.T) {
+		c := inject.New(
+			inject.DeferCompile(),
+			inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }, inject.As(new(http.Handler))),
+		)
+		c.Provide(func(handler http.Handler) *http.Server { return &http.Server{Handler: handler} })
+		c.Apply(inject.Values(map[string]interface{}{"addr": ":8080"}))
+		c.Compile()
+
+		var server *http.Server
+		require.NoError(t, c.Extract(&server))
+		var addr string
+		require.NoError(t, c.Extract(&addr, inject.Name("addr")))
+		require.Equal(t, ":8080", addr)
+	})
+
+	t.Run("extract before compile cause error", func(t *testing.T) {
+		c := inject.New(
+			inject.DeferCompile(),
+			inject.Provide(func() *http.Server { return &http.Server{} }),
+		)
+		var server *http.Server
+		require.EqualError(t, c.Extract(&server), "container not compiled")
+		require.EqualError(t, c.Invoke(func(*http.Server) {}), "container not compiled")
+	})
+
+	t.Run("apply after compile cause panic", func(t *testing.T) {
+		c := inject.New(inject.DeferCompile())
+		c.Compile()
+		require.PanicsWithValue(t, di.ErrContainerCompiled, func() {
+			c.Apply(inject.Provide(func() *http.Server { return &http.Server{} }))
+		})
+		require.PanicsWithValue(t, di.ErrContainerCompiled, func() {
+			c.Provide(func() *http.Server { return &http.Server{} })
+		})
+	})
+
+	t.Run("provide to container created by New cause panic", func(t *testing.T) {
+		c := inject.New()
+		require.PanicsWithValue(t, di.ErrContainerCompiled, func() {
+			c.Provide(func() *http.Server { return &http.Server{} })
+		})
+	})
+
+	t.Run("repeated compile does nothing", func(t *testing.T) {
+		var calls int
+		c := inject.New(
+			inject.Provide(func() *http.Server { calls++; return &http.Server{} }),
+		)
+		var server *http.Server
+		require.NoError(t, c.Extract(&server))
+		c.Compile()
+		require.NoError(t, c.Extract(&server))
+		require.Equal(t, 1, calls)
+	})
+}
+
+func TestContainerValues(t *testing.T) {
+	type ServerParameters struct {
+		di.Parameter
+		Addr    string        `di:"listen-addr"`
+		Timeout time.Duration `di:"timeout"`
+	}
+	_, file, line, _ := runtime.Caller(0)
+	c := inject.New(
+		inject.Values(map[string]interface{}{
+			"listen-addr": ":8080",
+			"timeout":     5 * time.Second,
+			"retries":     int64(3),
+		}),
+		inject.Provide(func(params ServerParameters) *http.Server {
+			return &http.Server{Addr: params.Addr, ReadTimeout: params.Timeout}
+		}),
+	)
+
+	var server *http.Server
+	require.NoError(t, c.Extract(&server))
+	require.Equal(t, ":8080", server.Addr)
+	require.Equal(t, 5*time.Second, server.ReadTimeout)
+
+	var retries time.Duration
+	require.EqualError(t, c.Extract(&retries, inject.Name("retries")),
+		"time.Duration[retries]: not exists in container, definition with name `retries` provided as int64")
+
+	for _, info := range c.Definitions() {
+		if info.Name != "" {
+			require.Equal(t, fmt.Sprintf("%s:%d", file, line+2), info.Location.String(), info.Name)
+		}
+	}
+}
+
+func TestContainerAppend(t *testing.T) {
+	type Migration string
+	c := inject.New(
+		inject.Append([]Migration{"create users", "create orders"}),
+		inject.Append([]Migration{"create payments"}),
+	)
+
+	var migrations []Migration
+	require.NoError(t, c.Extract(&migrations))
+	require.Equal(t, []Migration{"create users", "create orders", "create payments"}, migrations)
+
+	_, file, line, _ := runtime.Caller(0)
+	require.PanicsWithValue(t, fmt.Sprintf("%s:%d: map[string]int key `a` already provided at %s:%d", file, line+4, file, line+3), func() {
+		inject.New(
+			inject.Append(map[string]int{"a": 1}),
+			inject.Append(map[string]int{"a": 2}),
+		)
+	})
+}
+
+func TestContainerGroup(t *testing.T) {
+	type Middleware interface{ http.Handler }
+	type RouterParameters struct {
+		di.Parameter
+		Middlewares []Middleware `group:"middleware"`
+	}
+	c := inject.New(
+		inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }, inject.As(new(Middleware)), inject.IntoGroup("middleware")),
+		inject.Provide(func(params RouterParameters) []http.Handler {
+			handlers := make([]http.Handler, 0, len(params.Middlewares))
+			for _, middleware := range params.Middlewares {
+				handlers = append(handlers, middleware)
+			}
+			return handlers
+		}),
+	)
+
+	var handlers []http.Handler
+	require.NoError(t, c.Extract(&handlers))
+	require.Len(t, handlers, 1)
+
+	var middlewares []Middleware
+	require.NoError(t, c.Extract(&middlewares, inject.Group("middleware")))
+	require.Len(t, middlewares, 1)
+}
+
+func TestContainerExtractFresh(t *testing.T) {
+	c := inject.New(
+		inject.Provide(func() *http.Client { return &http.Client{} }),
+	)
+
+	var cached *http.Client
+	require.NoError(t, c.Extract(&cached))
+	var fresh *http.Client
+	require.NoError(t, c.Extract(&fresh, inject.Fresh()))
+	require.True(t, cached != fresh)
+
+	var client *http.Client
+	require.NoError(t, c.Extract(&client))
+	require.True(t, cached == client)
+}
+
+func TestContainerSubset(t *testing.T) {
+	type Clock struct{ fake bool }
+	type Migrator struct{ clock *Clock }
+	c := inject.New(
+		inject.Provide(func() *Clock { return &Clock{} }),
+		inject.Provide(func(clock *Clock) *Migrator { return &Migrator{clock: clock} }),
+		inject.Provide(func(clock *Clock) *http.Server { return &http.Server{} }),
+	)
+
+	subset, err := c.Subset(new(*Migrator))
+	require.NoError(t, err)
+	var server *http.Server
+	require.EqualError(t, subset.Extract(&server), "*http.Server: not exists in container")
+
+	subset.WithOverrides(func(derived *inject.Container) {
+		var migrator *Migrator
+		require.NoError(t, derived.Extract(&migrator))
+		require.True(t, migrator.clock.fake)
+		require.EqualError(t, derived.Extract(&server), "*http.Server: not exists in container")
+	}, inject.Replace(func() *Clock { return &Clock{fake: true} }))
+}
+
+func TestContainerPrune(t *testing.T) {
+	type Extra struct{}
+	c := inject.New(
+		inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }),
+		inject.Provide(func(mux *http.ServeMux) *http.Server { return &http.Server{Handler: mux} }),
+		inject.Provide(func(*http.Client) *Extra { return &Extra{} }), // *http.Client not exists
+		inject.Prune(new(*http.Server)),
+	)
+
+	var server *http.Server
+	require.NoError(t, c.Extract(&server))
+	var extra *Extra
+	require.Error(t, c.Extract(&extra))
+
+	c.WithOverrides(func(derived *inject.Container) {
+		require.Len(t, derived.Definitions(), 5)
+	}, inject.Replace(func() *http.ServeMux { return &http.ServeMux{} }))
+}
+
+func TestContainerMaxDepth(t *testing.T) {
+	c := inject.New(
+		inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }),
+		inject.Provide(func(mux *http.ServeMux) *http.Server { return &http.Server{Handler: mux} }),
+		inject.MaxDepth(1),
+	)
+
+	var server *http.Server
+	require.EqualError(t, c.Extract(&server), "resolution depth exceeds limit of 1: *http.Server -> *http.ServeMux")
+
+	c.WithOverrides(func(derived *inject.Container) {
+		require.Error(t, derived.Extract(&server))
+	})
+}
+
+func TestContainerAllowUnexported(t *testing.T) {
+	type ServerParameters struct {
+		di.Parameter
+		mux *http.ServeMux `di:""`
+	}
+	c := inject.New(
+		inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }),
+		inject.Provide(func(params ServerParameters) *http.Server { return &http.Server{Handler: params.mux} }),
+		inject.AllowUnexported(),
+	)
+
+	var server *http.Server
+	require.NoError(t, c.Extract(&server))
+	require.NotNil(t, server.Handler)
+}
+
+func TestContainerResolver(t *testing.T) {
+	type JobRunner struct {
+		resolver inject.Resolver
+	}
+	c := inject.New(
+		inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }),
+		inject.Provide(func(resolver inject.Resolver) *JobRunner { return &JobRunner{resolver: resolver} }),
+	)
+	var runner *JobRunner
+	require.NoError(t, c.Extract(&runner))
+	require.True(t, runner.resolver.Has(new(*http.ServeMux)))
+	require.False(t, runner.resolver.Has(new(*http.Server)))
+	require.False(t, runner.resolver.Has(new(*http.ServeMux), inject.Name("mux")))
+	var mux *http.ServeMux
+	require.NoError(t, runner.resolver.Extract(&mux))
+	require.NotNil(t, mux)
+
+	c.WithOverrides(func(derived *inject.Container) {
+		var runner *JobRunner
+		require.NoError(t, derived.Extract(&runner))
+		var server *http.Server
+		require.NoError(t, runner.resolver.Extract(&server))
+	}, inject.Provide(func() *http.Server { return &http.Server{} }))
+}
+
+func TestContainerImplementationsOf(t *testing.T) {
+	c := inject.New(
+		inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }, inject.As(new(http.Handler))),
+	)
+	infos, err := c.ImplementationsOf(new(http.Handler))
+	require.NoError(t, err)
+	require.Len(t, infos, 1)
+	require.Equal(t, reflect.TypeOf(&http.ServeMux{}), infos[0].Type)
+}
+
+func TestContainerErrNotFound(t *testing.T) {
+	c := inject.New(
+		inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }, inject.WithName("mux")),
+	)
+	var mux *http.ServeMux
+	var notFound inject.ErrNotFound
+	require.True(t, errors.As(c.Extract(&mux), &notFound))
+	require.Equal(t, reflect.TypeOf(mux), notFound.Type())
+	require.Len(t, notFound.Candidates(), 1)
+	require.Equal(t, "mux", notFound.Candidates()[0].Name())
+
+	var notFoundPtr *inject.ErrNotFound
+	require.True(t, errors.As(c.Extract(&mux), &notFoundPtr))
+	require.Equal(t, reflect.TypeOf(mux), notFoundPtr.Type())
+	require.Equal(t, "", notFoundPtr.Name())
+	require.Len(t, notFoundPtr.Candidates(), 1)
+	require.False(t, errors.As(errors.New("other"), &notFoundPtr))
+}
+
+func TestContainerExtractOr(t *testing.T) {
+	mux := &http.ServeMux{}
+	c := inject.New(
+		inject.Provide(func() *http.ServeMux { return mux }, inject.WithName("mux")),
+	)
+	var extracted *http.ServeMux
+	require.NoError(t, c.ExtractOr(&extracted, http.DefaultServeMux, inject.Name("mux")))
+	require.Same(t, mux, extracted)
+	require.NoError(t, c.ExtractOr(&extracted, http.DefaultServeMux))
+	require.Same(t, http.DefaultServeMux, extracted)
+	require.False(t, c.Has(new(*http.ServeMux)))
+}
+
+func TestContainerExtractIfPresent(t *testing.T) {
+	c := inject.New(
+		inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }, inject.WithName("mux")),
+		inject.Provide(func() (*http.Cookie, error) { return nil, errors.New("no cookie") }),
+	)
+
+	t.Run("named type", func(t *testing.T) {
+		var mux *http.ServeMux
+		ok, err := c.ExtractIfPresent(&mux, inject.Name("mux"))
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.NotNil(t, mux)
+
+		mux = nil
+		ok, err = c.ExtractIfPresent(&mux)
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Nil(t, mux)
+	})
+
+	t.Run("interface", func(t *testing.T) {
+		var handler http.Handler
+		ok, err := c.ExtractIfPresent(&handler)
+		require.NoError(t, err)
+		require.False(t, ok)
+
+		c := inject.New(inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }, inject.As(new(http.Handler))))
+		ok, err = c.ExtractIfPresent(&handler)
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.IsType(t, &http.ServeMux{}, handler)
+	})
+
+	t.Run("build error", func(t *testing.T) {
+		var cookie *http.Cookie
+		ok, err := c.ExtractIfPresent(&cookie)
+		require.EqualError(t, err, "*http.Cookie: no cookie")
+		require.True(t, ok)
+	})
+}
+
+func TestContainerOfReflectType(t *testing.T) {
+	mux := &http.ServeMux{}
+	c := inject.New(
+		inject.Provide(func() *http.ServeMux { return mux }, inject.WithName("mux"), inject.As(new(http.Handler))),
+	)
+	var handler interface{}
+	require.NoError(t, c.Extract(&handler, inject.OfReflectType(reflect.TypeOf(mux)), inject.Name("mux")))
+	require.Same(t, mux, handler)
+	require.NoError(t, c.Extract(&handler, inject.OfReflectType(reflect.TypeOf(new(http.Handler)).Elem()), inject.Name("mux")))
+	require.Same(t, mux, handler)
+
+	var notFound inject.ErrNotFound
+	require.True(t, errors.As(c.Extract(&handler, inject.OfReflectType(reflect.TypeOf(mux))), &notFound))
+	require.Equal(t, reflect.TypeOf(mux), notFound.Type())
+
+	var server *http.Server
+	require.EqualError(t, c.Extract(&server, inject.OfReflectType(reflect.TypeOf(mux))),
+		"extract target `**http.Server` could not hold `*http.ServeMux`")
+}
+
+func TestResolve(t *testing.T) {
+	mux := &http.ServeMux{}
+	c := inject.New(
+		inject.Provide(func() *http.ServeMux { return mux }),
+		inject.Provide(func() (*http.Cookie, error) { return nil, errors.New("no cookie") }),
+	)
+
+	t.Run("resolve", func(t *testing.T) {
+		resolved, err := inject.Resolve[*http.ServeMux](c)
+		require.NoError(t, err)
+		require.Same(t, mux, resolved)
+	})
+
+	t.Run("not provided type is not an error", func(t *testing.T) {
+		resolved, ok, err := inject.TryResolve[*http.Request](c)
+		require.NoError(t, err)
+		require.False(t, ok)
+		require.Nil(t, resolved)
+
+		fallback := &http.Request{}
+		request, err := inject.ResolveOr(c, fallback)
+		require.NoError(t, err)
+		require.Same(t, fallback, request)
+	})
+
+	t.Run("build error is returned", func(t *testing.T) {
+		_, ok, err := inject.TryResolve[*http.Cookie](c)
+		require.True(t, ok)
+		require.EqualError(t, err, "*http.Cookie: no cookie")
+		_, err = inject.ResolveOr(c, &http.Cookie{})
+		require.EqualError(t, err, "*http.Cookie: no cookie")
+	})
+}
+
+func TestContainerCompileLog(t *testing.T) {
+	var b strings.Builder
+	inject.New(
+		inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }, inject.As(new(http.Handler))),
+		inject.Provide(func(handler http.Handler) *http.Server { return &http.Server{Handler: handler} }),
+		inject.CompileLog(&b),
+	)
+	require.Equal(t, ""+
+		"compiled 3 definitions\n"+
+		"construction order:\n"+
+		"  1. inject.Resolver [singleton]\n"+
+		"  2. *http.ServeMux [singleton]\n"+
+		"  3. *http.Server [singleton]\n"+
+		"interface bindings:\n"+
+		"  http.Handler -> *http.ServeMux: the only implementation\n",
+		b.String())
+}
+
+func TestContainerLogGraph(t *testing.T) {
+	var b strings.Builder
+	inject.New(
+		inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }, inject.As(new(http.Handler))),
+		inject.Provide(func(handler http.Handler) *http.Server { return &http.Server{Handler: handler} }),
+		inject.LogGraph(&b),
+	)
+	lines := strings.Split(strings.TrimSpace(b.String()), "\n")
+	require.Len(t, lines, 3)
+	require.True(t, strings.HasPrefix(lines[0], `key="*http.ServeMux" lifetime=singleton dependencies="" implements="http.Handler" location=`))
+	require.True(t, strings.HasPrefix(lines[1], `key="*http.Server" lifetime=singleton dependencies="http.Handler" implements="" location=`))
+	require.True(t, strings.HasPrefix(lines[2], `key="inject.Resolver" lifetime=singleton`))
+}
+
+func TestContainerLifetime(t *testing.T) {
+	lifetimes := func(c *inject.Container) map[string]string {
+		lifetimes := map[string]string{}
+		for _, info := range c.Definitions() {
+			if strings.HasPrefix(info.Key.String(), "*http.") {
+				lifetimes[info.Key.String()] = fmt.Sprintf("%s from %s", info.Lifetime, info.LifetimeSource)
+			}
+		}
+		return lifetimes
+	}
+
+	t.Run("provide option overrides module and module overrides container", func(t *testing.T) {
+		c := inject.New(
+			inject.DefaultLifetime(inject.PrototypeLifetime),
+			inject.Provide(func() *http.Client { return &http.Client{} }),
+			inject.ModuleLifetime(inject.SingletonLifetime,
+				inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }),
+				inject.Provide(func() *http.Server { return &http.Server{} }, inject.Prototype()),
+			),
+			inject.Provide(func() *http.Cookie { return &http.Cookie{} }, inject.Singleton()),
+		)
+		require.Equal(t, map[string]string{
+			"*http.Client":   "prototype from container",
+			"*http.ServeMux": "singleton from module",
+			"*http.Server":   "prototype from provide",
+			"*http.Cookie":   "singleton from provide",
+		}, lifetimes(c))
+	})
+
+	t.Run("builder module with lifetime", func(t *testing.T) {
+		c, err := inject.NewBuilder().
+			Apply(inject.DefaultLifetime(inject.PrototypeLifetime)).
+			ModuleWithLifetime(inject.SingletonLifetime, func(b *inject.Builder) {
+				b.Provide(func() *http.ServeMux { return &http.ServeMux{} })
+			}).
+			Build()
+		require.NoError(t, err)
+		require.Equal(t, "singleton from module", lifetimes(c)["*http.ServeMux"])
+	})
+}
+
+func TestContainerSupply(t *testing.T) {
+	t.Run("supplied value is named and bound to interface", func(t *testing.T) {
+		var cleanups []string
+		buffer := &bytes.Buffer{}
+		c := inject.New(
+			inject.Supply(buffer,
+				inject.WithName("primary"),
+				inject.As(new(io.Writer)),
+				inject.WithCleanup(func() { cleanups = append(cleanups, "buffer") }),
+			),
+		)
+		var writer io.Writer
+		require.NoError(t, c.Extract(&writer, inject.Name("primary")))
+		require.Same(t, buffer, writer)
+		var extracted *bytes.Buffer
+		require.NoError(t, c.Extract(&extracted, inject.Name("primary")))
+		require.Same(t, buffer, extracted)
+		c.Cleanup()
+		require.Equal(t, []string{"buffer"}, cleanups)
+	})
+
+	t.Run("value that does not implement interface cause panic", func(t *testing.T) {
+		_, file, line, _ := runtime.Caller(0)
+		require.PanicsWithValue(t, fmt.Sprintf("%s:%d: supplied *http.Client does not implement io.Writer", file, line+2), func() {
+			inject.Supply(&http.Client{}, inject.As(new(io.Writer)))
+		})
+	})
+
+	t.Run("nil pointer bound to interface cause panic", func(t *testing.T) {
+		_, file, line, _ := runtime.Caller(0)
+		require.PanicsWithValue(t, fmt.Sprintf("%s:%d: supplied *bytes.Buffer bound to io.Writer is nil pointer", file, line+2), func() {
+			inject.Supply((*bytes.Buffer)(nil), inject.As(new(io.Writer)))
+		})
+	})
+
+	t.Run("nil channel is supplied with AllowNil option", func(t *testing.T) {
+		var events chan string
+		require.Panics(t, func() {
+			inject.New(inject.Supply(events))
+		})
+		c := inject.New(inject.Supply(events, inject.AllowNil()))
+		var extracted chan string
+		require.NoError(t, c.Extract(&extracted))
+		require.Nil(t, extracted)
+	})
+}
+
+func TestContainerStats(t *testing.T) {
+	c := inject.New(
+		inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }),
+		inject.Provide(func(mux *http.ServeMux) *http.Server { return &http.Server{Handler: mux} }, inject.Prototype()),
+	)
+	var server *http.Server
+	require.NoError(t, c.Extract(&server))
+	require.NoError(t, c.Extract(&server))
+	stats := c.Stats()
+	require.Equal(t, 1, stats.Prototypes)
+	require.Equal(t, uint64(3), stats.Built)
+	require.Equal(t, uint64(2), stats.Resolutions)
+	require.Equal(t, uint64(0), stats.Failures)
+}
+
+func TestContainerReleaseInstances(t *testing.T) {
+	c := inject.New(
+		inject.Provide(func() *http.Server { return &http.Server{} }),
+	)
+	var first *http.Server
+	require.NoError(t, c.Extract(&first))
+	c.Cleanup()
+	require.NoError(t, c.ReleaseInstances())
+	var second *http.Server
+	require.NoError(t, c.Extract(&second))
+	require.True(t, first != second)
+
+	c.Cleanup()
+	require.NoError(t, c.ReleaseInstances(inject.RejectResolution()))
+	require.True(t, errors.Is(c.Extract(&second), di.ErrReleased))
+}
+
+func TestContainerContextFirst(t *testing.T) {
+	type ctxKey struct{}
+	var received []interface{}
+	c := inject.New(
+		inject.Provide(func(ctx context.Context) *http.ServeMux {
+			received = append(received, ctx.Value(ctxKey{}))
+			return &http.ServeMux{}
+		}),
+		inject.Provide(func(ctx context.Context, mux *http.ServeMux) *http.Server {
+			received = append(received, ctx.Value(ctxKey{}))
+			return &http.Server{Handler: mux}
+		}, inject.Prototype()),
+	)
+	require.NoError(t, c.BuildContext(context.WithValue(context.Background(), ctxKey{}, "build")))
+	var server *http.Server
+	require.NoError(t, c.Extract(&server))
+	require.Equal(t, []interface{}{"build", nil}, received)
+}
+
+func TestContainerDevMode(t *testing.T) {
+	var c *inject.Container
+	var err error
+	c = inject.New(
+		inject.DeferCompile(),
+		inject.DevMode(),
+		inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }),
+		inject.CompileLog(writerFunc(func(p []byte) (int, error) {
+			var mux *http.ServeMux
+			err = c.Extract(&mux)
+			return len(p), nil
+		})),
+	)
+	c.Compile()
+	require.Equal(t, di.ErrCompiling, err)
+	var mux *http.ServeMux
+	require.NoError(t, c.Extract(&mux))
+}
+
+func TestContainerProvideKinds(t *testing.T) {
+	type Handler struct {
+		Mux *http.ServeMux `di:""`
+	}
+	var value int
+	for _, test := range []struct {
+		kind     reflect.Kind
+		provider interface{}
+		valid    bool
+	}{
+		{kind: reflect.Invalid, provider: nil},
+		{kind: reflect.Bool, provider: true},
+		{kind: reflect.Int, provider: 1},
+		{kind: reflect.Int8, provider: int8(1)},
+		{kind: reflect.Int16, provider: int16(1)},
+		{kind: reflect.Int32, provider: int32(1)},
+		{kind: reflect.Int64, provider: int64(1)},
+		{kind: reflect.Uint, provider: uint(1)},
+		{kind: reflect.Uint8, provider: uint8(1)},
+		{kind: reflect.Uint16, provider: uint16(1)},
+		{kind: reflect.Uint32, provider: uint32(1)},
+		{kind: reflect.Uint64, provider: uint64(1)},
+		{kind: reflect.Uintptr, provider: uintptr(1)},
+		{kind: reflect.Float32, provider: float32(1)},
+		{kind: reflect.Float64, provider: float64(1)},
+		{kind: reflect.Complex64, provider: complex64(1)},
+		{kind: reflect.Complex128, provider: complex128(1)},
+		{kind: reflect.Array, provider: [1]int{}},
+		{kind: reflect.Chan, provider: make(chan int)},
+		{kind: reflect.Func, provider: func() *http.ServeMux { return &http.ServeMux{} }, valid: true},
+		// interface value is unpacked to its dynamic type, nil interface is nil
+		{kind: reflect.Interface, provider: reflect.ValueOf(new(io.Writer)).Elem().Interface()},
+		{kind: reflect.Map, provider: map[string]int{}},
+		{kind: reflect.Ptr, provider: &value},
+		{kind: reflect.Ptr, provider: &Handler{}, valid: true},
+		{kind: reflect.Slice, provider: []int{}},
+		{kind: reflect.String, provider: "addr"},
+		{kind: reflect.Struct, provider: Handler{}, valid: true},
+		{kind: reflect.UnsafePointer, provider: reflect.ValueOf(&value).UnsafePointer()},
+	} {
+		test := test
+		t.Run(fmt.Sprintf("%s %T", test.kind, test.provider), func(t *testing.T) {
+			if test.valid {
+				require.NotPanics(t, func() {
+					inject.Provide(test.provider)
+				})
+				return
+			}
+			_, file, line, _ := runtime.Caller(0)
+			expected := fmt.Sprintf("%s:%d: cannot provide %T; wrap it with inject.Supply to register a value, or pass a constructor function", file, line+6, test.provider)
+			if test.provider == nil {
+				expected = fmt.Sprintf("%s:%d: cannot provide nil; pass a constructor function", file, line+6)
+			}
+			require.PanicsWithValue(t, expected, func() {
+				inject.Provide(test.provider)
+			})
+		})
+	}
+
+	t.Run("struct value is filled with dependencies", func(t *testing.T) {
+		mux := &http.ServeMux{}
+		c := inject.New(
+			inject.Supply(mux),
+			inject.Provide(Handler{}),
+		)
+		var handler Handler
+		require.NoError(t, c.Extract(&handler))
+		require.Same(t, mux, handler.Mux)
+	})
+}
+
+func TestContainerProviders(t *testing.T) {
+	t.Run("constructors are provided with shared options", func(t *testing.T) {
+		c := inject.New(
+			inject.ProvidersWith([]inject.ProvideOption{inject.Prototype()}, NewMux, NewFileServer),
+			inject.Providers(ProvideAddr("0.0.0.0", "8080"), inject.OfType(&http.Client{})),
+		)
+		var mux1, mux2 *http.ServeMux
+		require.NoError(t, c.Extract(&mux1))
+		require.NoError(t, c.Extract(&mux2))
+		require.True(t, mux1 != mux2)
+		var addr Addr
+		require.NoError(t, c.Extract(&addr))
+		require.Equal(t, Addr("0.0.0.0:8080"), addr)
+		var client *http.Client
+		require.NoError(t, c.Extract(&client))
+	})
+
+	t.Run("invalid member is named by index", func(t *testing.T) {
+		_, file, line, _ := runtime.Caller(0)
+		require.PanicsWithValue(t, fmt.Sprintf("%s:%d: inject.Providers[1] (string): cannot provide string; wrap it with inject.Supply to register a value, or pass a constructor function", file, line+2), func() {
+			inject.Providers(NewMux, "addr")
+		})
+	})
+
+	t.Run("invalid constructor is named by index and function", func(t *testing.T) {
+		var recovered interface{}
+		func() {
+			defer func() { recovered = recover() }()
+			inject.New(inject.ProvidersWith(nil, NewMux, StartServer))
+		}()
+		require.True(t, strings.HasPrefix(fmt.Sprint(recovered), "inject.ProvidersWith[1] (func StartServer): "), recovered)
+		require.Contains(t, fmt.Sprint(recovered), "constructor has no result")
+	})
+}
+
+func TestContainerExtractNamed(t *testing.T) {
+	primary := &http.ServeMux{}
+	c := inject.New(
+		inject.Supply(primary, inject.WithName("primary")),
+		inject.Supply(&http.ServeMux{}, inject.WithName("secondary")),
+		inject.Provide(func() (*http.Server, error) { return nil, errors.New("no server") }, inject.WithName("server")),
+	)
+
+	for _, name := range []string{"primary", "secondary", "unknown", ""} {
+		var extracted, named *http.ServeMux
+		err := c.Extract(&extracted, inject.Name(name))
+		namedErr := c.ExtractNamed(name, &named)
+		resolved, resolveErr := inject.ResolveNamed[*http.ServeMux](c, name)
+		require.Equal(t, err, namedErr, name)
+		require.Equal(t, err, resolveErr, name)
+		require.Same(t, extracted, named, name)
+		require.Same(t, extracted, resolved, name)
+		if err != nil {
+			require.Equal(t, errors.As(err, new(inject.ErrNotFound)), errors.As(namedErr, new(inject.ErrNotFound)), name)
+		}
+	}
+
+	var mux *http.ServeMux
+	require.NoError(t, c.ExtractNamed("primary", &mux, inject.Name("secondary")))
+	require.Same(t, primary, mux)
+	require.EqualError(t, c.ExtractNamed("server", new(*http.Server)), "*http.Server[server]: no server")
+	_, err := inject.ResolveNamed[*http.Server](c, "server")
+	require.EqualError(t, err, "*http.Server[server]: no server")
+}
+
+func TestContainerBindNamed(t *testing.T) {
+	c := inject.New(
+		inject.Provide(NewMux),
+		inject.Provide(NewFileServer),
+		inject.Bind(new(http.Handler), new(http.ServeMux), inject.Name("primary")),
+		inject.Bind(new(http.Handler), FileServer{}, inject.Name("files")),
+	)
+
+	var mux *http.ServeMux
+	require.NoError(t, c.Extract(&mux))
+	var primary http.Handler
+	require.NoError(t, c.Extract(&primary, inject.Name("primary")))
+	require.Same(t, mux, primary)
+	var files http.Handler
+	require.NoError(t, c.Extract(&files, inject.Name("files")))
+	require.IsType(t, FileServer{}, files)
+
+	type Handlers struct {
+		di.Parameter
+		Primary http.Handler `di:"primary"`
+		Files   http.Handler `di:"files"`
+	}
+	require.NoError(t, c.Invoke(func(handlers Handlers) {
+		require.Same(t, mux, handlers.Primary)
+		require.IsType(t, FileServer{}, handlers.Files)
+	}))
+
+	var handler http.Handler
+	require.Error(t, c.Extract(&handler))
+	require.PanicsWithValue(t, "inject.Bind() supports only inject.Name() option", func() {
+		inject.Bind(new(http.Handler), new(http.ServeMux), inject.Tag("region", "eu"))
+	})
+}
+
+func TestContainerProvideFrom(t *testing.T) {
+	handlers := inject.Options(
+		inject.Provide(NewMux, inject.As(new(http.Handler))),
+		inject.Provide(NewFileServer, inject.As(new(http.Handler))),
+	)
+	newRoutes := func(handlers []http.Handler) map[string]http.Handler {
+		routes := map[string]http.Handler{}
+		for _, handler := range handlers {
+			routes[fmt.Sprintf("%T", handler)] = handler
+		}
+		return routes
+	}
+
+	t.Run("late constructor is resolved from connected definitions", func(t *testing.T) {
+		c := inject.New(
+			handlers,
+			inject.ProvideFrom(newRoutes),
+			inject.ProvideFrom(func(routes map[string]http.Handler) *http.Server {
+				return &http.Server{Handler: routes["*http.ServeMux"]}
+			}),
+		)
+		require.NoError(t, c.BuildAll())
+		var server *http.Server
+		require.NoError(t, c.Extract(&server))
+		require.IsType(t, &http.ServeMux{}, server.Handler)
+	})
+
+	t.Run("ordinary constructor depending on late one cause panic", func(t *testing.T) {
+		newServer := func(routes map[string]http.Handler) *http.Server { return &http.Server{} }
+		require.PanicsWithValue(t, fmt.Sprintf("%s: *http.Server depends on map[string]http.Handler provided late at %s, "+
+			"only late definitions could depend on it", location(newServer), location(newRoutes)), func() {
+			inject.New(handlers, inject.ProvideFrom(newRoutes), inject.Provide(newServer))
+		})
+	})
+
+	t.Run("not a function cause panic", func(t *testing.T) {
+		_, file, line, _ := runtime.Caller(0)
+		require.PanicsWithValue(t, fmt.Sprintf("%s:%d: ProvideFrom() requires a constructor function, got *http.ServeMux", file, line+2), func() {
+			inject.ProvideFrom(&http.ServeMux{})
+		})
+	})
+}
+
+func TestContainerRetryLog(t *testing.T) {
+	var calls int
+	var b strings.Builder
+	c := inject.New(
+		inject.Provide(func() (*http.Server, error) {
+			calls++
+			if calls < 2 {
+				return nil, errors.New("sidecar not ready")
+			}
+			return &http.Server{}, nil
+		}, inject.Retry(2, time.Millisecond)),
+		inject.RetryLog(&b),
+	)
+	var server *http.Server
+	require.NoError(t, c.Extract(&server))
+	require.Equal(t, "*http.Server: attempt 1 of 2 failed: sidecar not ready\n", b.String())
+}
+
+func TestContainerStructShadowsConstructor(t *testing.T) {
+	c := inject.New(
+		inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }),
+		inject.Replace(&http.ServeMux{}),
+	)
+	findings := c.Report().Findings
+	require.Len(t, findings, 1)
+	require.Equal(t, di.FindingShadowed, findings[0].Kind)
+	require.Equal(t, "*http.ServeMux", findings[0].Key)
+	require.Contains(t, findings[0].Message, "struct provider shadows constructor at ")
+}
+
+func TestContainerDeprecated(t *testing.T) {
+	providers := inject.Bundle(
+		inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }, inject.Deprecated("use NewRouter")),
+		inject.Provide(func(mux *http.ServeMux) *http.Server { return &http.Server{Handler: mux} }),
+	)
+	var b strings.Builder
+	c := inject.New(providers, inject.DeprecationLog(&b))
+	var server *http.Server
+	require.NoError(t, c.Extract(&server))
+	require.Contains(t, b.String(), "*http.ServeMux is deprecated: use NewRouter, required by *http.Server\n")
+
+	c = inject.New(providers, inject.StrictDeprecation())
+	require.EqualError(t, c.Extract(&server), "*http.ServeMux: deprecated: use NewRouter")
+}
+
+func TestContainerDescription(t *testing.T) {
+	type Doer interface{}
+	type PaymentsClient struct{}
+	type SearchClient struct{}
+	c := inject.New(
+		inject.Provide(func() *PaymentsClient { return &PaymentsClient{} }, inject.As(new(Doer)),
+			inject.Description("payments API client")),
+		inject.Provide(func() *SearchClient { return &SearchClient{} }, inject.As(new(Doer))),
+	)
+	var doer Doer
+	require.EqualError(t, c.Extract(&doer),
+		"inject_test.Doer: have several implementations: *inject_test.PaymentsClient (payments API client), *inject_test.SearchClient")
+}
+
+func TestContainerWithRecover(t *testing.T) {
+	c := inject.New(
+		inject.Provide(func() *http.Server { panic("boom") }),
+		inject.WithRecover(inject.WrapError),
+	)
+	var server *http.Server
+	err := c.Extract(&server)
+	require.EqualError(t, err, "*http.Server: panic: boom")
+	var recovered inject.ErrPanic
+	require.True(t, errors.As(err, &recovered))
+	require.Equal(t, "boom", recovered.Value())
+}
+
+func TestContainerProfiles(t *testing.T) {
+	type Queue interface{}
+	type MemoryQueue struct{}
+	type SQSQueue struct{ client *http.Client }
+	profiles := inject.Bundle(
+		inject.Profile("dev", inject.Provide(func() *MemoryQueue { return &MemoryQueue{} }, inject.As(new(Queue)))),
+		inject.Profile("prod",
+			inject.Provide(func(client *http.Client) *SQSQueue { return &SQSQueue{client: client} }),
+			inject.Bind(new(Queue), new(SQSQueue)),
+		),
+		inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }),
+	)
+
+	t.Run("providers of inactive profiles are dropped", func(t *testing.T) {
+		c := inject.New(profiles, inject.ActiveProfiles("dev"))
+		var queue Queue
+		require.NoError(t, c.Extract(&queue))
+		require.IsType(t, &MemoryQueue{}, queue)
+		require.True(t, c.Has(new(*http.ServeMux)))
+		require.False(t, c.Has(new(*SQSQueue)))
+	})
+
+	t.Run("dependencies of active profile are required", func(t *testing.T) {
+		require.PanicsWithValue(t, "*inject_test.SQSQueue: dependency *http.Client not exists in container", func() {
+			inject.New(profiles, inject.ActiveProfiles("prod"))
+		})
+		c := inject.New(profiles, inject.ActiveProfiles("prod"), inject.Provide(func() *http.Client { return &http.Client{} }))
+		var queue Queue
+		require.NoError(t, c.Extract(&queue))
+		require.IsType(t, &SQSQueue{}, queue)
+	})
+
+	t.Run("nested profiles must be active", func(t *testing.T) {
+		nested := inject.Profile("prod", inject.Profile("eu", inject.Provide(func() *http.ServeMux { return &http.ServeMux{} })))
+		require.False(t, inject.New(nested, inject.ActiveProfiles("prod")).Has(new(*http.ServeMux)))
+		require.True(t, inject.New(nested, inject.ActiveProfiles("prod", "eu")).Has(new(*http.ServeMux)))
+	})
+
+	t.Run("values and appended values of inactive profiles are dropped", func(t *testing.T) {
+		options := inject.Bundle(
+			inject.Append([]string{"users"}),
+			inject.Profile("dev",
+				inject.Values(map[string]interface{}{"addr": ":8080"}),
+				inject.Append([]string{"fixtures"}),
+			),
+		)
+		c := inject.New(options)
+		require.False(t, c.Has(new(string), inject.Name("addr")))
+		var migrations []string
+		require.NoError(t, c.Extract(&migrations))
+		require.Equal(t, []string{"users"}, migrations)
+
+		c = inject.New(options, inject.ActiveProfiles("dev"))
+		require.True(t, c.Has(new(string), inject.Name("addr")))
+		require.NoError(t, c.Extract(&migrations))
+		require.Equal(t, []string{"users", "fixtures"}, migrations)
+	})
+
+	t.Run("same type in active profiles causes panic with profiles", func(t *testing.T) {
+		require.PanicsWithValue(t, "The `*http.ServeMux` type is provided in no profile and in profile `dev`", func() {
+			inject.New(
+				inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }),
+				inject.Profile("dev", inject.Provide(func() *http.ServeMux { return &http.ServeMux{} })),
+				inject.ActiveProfiles("dev"),
+			)
+		})
+		require.PanicsWithValue(t, "The `*http.Client` type is provided in profile `dev` and in profile `prod`", func() {
+			inject.New(
+				inject.Profile("dev", inject.Provide(func() *http.Client { return &http.Client{} })),
+				inject.Profile("prod", inject.Provide(func() *http.Client { return &http.Client{} })),
+				inject.ActiveProfiles("dev", "prod"),
+			)
+		})
+	})
+}
+
+func TestContainerDedup(t *testing.T) {
+	newMux := func() *http.ServeMux { return &http.ServeMux{} }
+	accounts := inject.Bundle(inject.Provide(newMux, inject.Dedup()))
+	auth := inject.Bundle(inject.Provide(newMux, inject.Dedup()))
+	c := inject.New(accounts, auth)
+	require.True(t, c.Has(new(*http.ServeMux)))
+
+	require.Panics(t, func() {
+		inject.New(accounts, inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }, inject.Dedup()))
+	})
+}
+
+func TestContainerSeveralNames(t *testing.T) {
+	c := inject.New(inject.Provide(func() *http.Client { return &http.Client{} },
+		inject.WithName("internal"), inject.WithName("external")))
+	var internal, external *http.Client
+	require.NoError(t, c.Extract(&internal, inject.Name("internal")))
+	require.NoError(t, c.Extract(&external, inject.Name("external")))
+	require.Same(t, internal, external)
+}
+
+func TestContainerExplicitBindingsOnly(t *testing.T) {
+	newBuffer := func() *bytes.Buffer { return &bytes.Buffer{} }
+	c := inject.New(inject.Provide(newBuffer, inject.As(new(io.ReadWriter))))
+	require.True(t, c.Has(new(io.Reader)))
+
+	c = inject.New(inject.Provide(newBuffer, inject.As(new(io.ReadWriter))), inject.ExplicitBindingsOnly())
+	require.True(t, c.Has(new(io.ReadWriter)))
+	require.False(t, c.Has(new(io.Reader)))
+}
+
+func TestContainerSetter(t *testing.T) {
+	c := inject.New(
+		inject.Provide(NewPeer("a"), inject.WithName("a"), inject.Setter("SetNext")),
+		inject.Provide(NewNextPeer, inject.Setter("SetPeer")),
+	)
+	var next *NextPeer
+	require.NoError(t, c.Extract(&next))
+	require.Equal(t, "a", next.peer.name)
+	require.Same(t, next, next.peer.next)
+}
+
+func TestContainerEager(t *testing.T) {
+	var created bool
+	c := inject.New(
+		inject.Provide(func() *http.ServeMux {
+			created = true
+			return &http.ServeMux{}
+		}),
+		inject.Eager(),
+	)
+	require.True(t, created)
+	require.NoError(t, c.Start(context.Background()))
+
+	var cleaned bool
+	require.Panics(t, func() {
+		inject.New(
+			inject.Provide(func() (*http.ServeMux, func()) { return &http.ServeMux{}, func() { cleaned = true } }),
+			inject.Provide(func(*http.ServeMux) (*http.Client, error) { return nil, errors.New("no network") }),
+			inject.Eager(),
+		)
+	})
+	require.True(t, cleaned)
+}
+
+func TestNewWithDiagnostics(t *testing.T) {
+	container, diagnostics, err := inject.NewWithDiagnostics(inject.Provide(http.NewServeMux))
+	require.NoError(t, err)
+	require.NotNil(t, container)
+	require.Equal(t, &inject.Diagnostics{Findings: []di.Finding{}}, diagnostics)
+
+	newClient := func(*http.ServeMux) *http.Client { return &http.Client{} }
+	container, diagnostics, err = inject.NewWithDiagnostics(inject.Provide(newClient))
+	require.EqualError(t, err, "*http.Client: dependency *http.ServeMux not exists in container")
+	require.Nil(t, container)
+	require.Equal(t, di.StageConnect, diagnostics.Stage)
+	require.Equal(t, di.FindingUnresolved, diagnostics.Findings[0].Kind)
+	require.Equal(t, "*http.Client", diagnostics.Findings[0].Key)
+	require.NotNil(t, diagnostics.Findings[0].Location)
+	var failed inject.ErrCompileFailed
+	require.True(t, errors.As(err, &failed))
+	require.Same(t, diagnostics, failed.Diagnostics())
+
+	_, diagnostics, err = inject.NewWithDiagnostics(
+		inject.Provide(func() (*http.Client, error) { return nil, errors.New("no network") }),
+		inject.Eager(),
+	)
+	require.EqualError(t, err, "*http.Client: no network")
+	require.Equal(t, di.StageBuild, diagnostics.Stage)
+	require.Equal(t, "*http.Client", diagnostics.Findings[0].Key)
+}
+
+func TestContainerWarmUp(t *testing.T) {
+	var created bool
+	c := inject.New(
+		inject.Provide(http.NewServeMux),
+		inject.Provide(func() *http.Client {
+			created = true
+			return &http.Client{}
+		}),
+	)
+	require.NoError(t, c.WarmUp(new(*http.ServeMux)))
+	require.False(t, created)
+	err := c.WarmUpParallel(new(*http.Client), new(*http.Server))
+	var failed inject.ErrWarmUp
+	require.True(t, errors.As(err, &failed))
+	require.Equal(t, []string{"*http.Server"}, failed.Roots())
+	require.True(t, created)
+}
+
+func TestBuilder(t *testing.T) {
+	debug := false
+	container, err := inject.NewBuilder().
+		Supply(&http.ServeMux{}, inject.As(new(http.Handler))).
+		Provide(func(handler http.Handler) *http.Server { return &http.Server{Handler: handler} }).
+		Module(func(b *inject.Builder) {
+			b.Provide(func() *http.Client { return &http.Client{} })
+		}).
+		If(debug, func(b *inject.Builder) {
+			b.Provide(func() *http.Client { return &http.Client{} })
+		}).
+		Apply(inject.Eager()).
+		Build()
+	require.NoError(t, err)
+	var server *http.Server
+	require.NoError(t, container.Extract(&server))
+	require.IsType(t, &http.ServeMux{}, server.Handler)
+
+	var ch chan int
+	_, file, line, _ := runtime.Caller(0)
+	_, err = inject.NewBuilder().Supply(ch).Build()
+	require.EqualError(t, err, fmt.Sprintf("%s:%d: chan int: the value is nil channel, operations on it block forever", file, line+1))
+	var failed inject.ErrCompileFailed
+	require.True(t, errors.As(err, &failed))
+	require.Equal(t, di.StageProvide, failed.Diagnostics().Stage)
+	require.Equal(t, fmt.Sprintf("%s:%d", file, line+1), failed.Diagnostics().Findings[0].Location.String())
+}
+
+func TestOptions(t *testing.T) {
+	t.Run("nested groups keep registration order", func(t *testing.T) {
+		newMux := func() *http.ServeMux { return &http.ServeMux{} }
+		module := inject.Options(
+			inject.Provide(newMux, inject.WithName("a"), inject.As(new(http.Handler))),
+			inject.Options(
+				inject.Provide(newMux, inject.WithName("b"), inject.As(new(http.Handler))),
+				inject.When(false, inject.Provide(newMux, inject.WithName("c"), inject.As(new(http.Handler)))),
+				inject.When(true, inject.Provide(newMux, inject.WithName("d"), inject.As(new(http.Handler)))),
+			),
+			inject.Options(),
+			inject.When(true),
+		)
+		container := inject.New(module, inject.Provide(newMux, inject.WithName("e"), inject.As(new(http.Handler))))
+		var keys []string
+		for _, key := range container.Keys() {
+			if key.Type() == reflect.TypeOf(&http.ServeMux{}) && !key.IsAlias() {
+				keys = append(keys, key.Name())
+			}
+		}
+		require.Equal(t, []string{"a", "b", "d", "e"}, keys)
+	})
+
+	t.Run("empty groups add nothing", func(t *testing.T) {
+		container := inject.New(inject.Options(), inject.When(false), inject.Options(inject.Options()))
+		require.False(t, container.Has(new(*http.ServeMux)))
+	})
+
+	t.Run("nested options keep call locations", func(t *testing.T) {
+		var ch chan int
+		_, file, line, _ := runtime.Caller(0)
+		option := inject.Options(inject.When(true, inject.Options(inject.Supply(ch))))
+		require.PanicsWithValue(t, fmt.Sprintf("%s:%d: chan int: the value is nil channel, operations on it block forever", file, line+1), func() {
+			inject.New(option)
+		})
+	})
+}
+
+func TestRegister(t *testing.T) {
+	var created []string
+	newClient := func(name string) func() *http.Client {
+		return func() *http.Client {
+			created = append(created, name)
+			return &http.Client{}
+		}
+	}
+	container := inject.New(
+		inject.Register("internal", newClient("internal")),
+		inject.Register("external", newClient("external")),
+	)
+	var factories map[string]func() (*http.Client, error)
+	require.NoError(t, container.Extract(&factories))
+	require.Empty(t, created)
+	client, err := factories["external"]()
+	require.NoError(t, err)
+	require.Equal(t, []string{"external"}, created)
+	var clients map[string]*http.Client
+	require.NoError(t, container.Extract(&clients))
+	require.Same(t, client, clients["external"])
+	var named *http.Client
+	require.NoError(t, container.Extract(&named, inject.Name("external")))
+	require.Same(t, client, named)
+}
+
+func TestWithFallbacks(t *testing.T) {
+	platform := inject.New(inject.Provide(http.NewServeMux))
+	host := inject.New(inject.Provide(func() *http.Client { return &http.Client{} }))
+	plugin := inject.New(
+		inject.Provide(func(mux *http.ServeMux, client *http.Client) *http.Server { return &http.Server{Handler: mux} }),
+		inject.WithFallbacks(host, platform),
+	)
+	var server *http.Server
+	require.NoError(t, plugin.Extract(&server))
+	var mux *http.ServeMux
+	require.NoError(t, platform.Extract(&mux))
+	require.Same(t, mux, server.Handler)
+	var transport http.RoundTripper
+	require.EqualError(t, plugin.Extract(&transport), "http.RoundTripper: not exists in container and its 2 fallback containers")
+}
+
+func TestContainerAllowCopy(t *testing.T) {
+	type Counter struct {
+		mu    sync.Mutex
+		count int
+	}
+	newCounter := func() Counter { return Counter{} }
+	require.Panics(t, func() {
+		inject.New(inject.Provide(newCounter))
+	})
+	c := inject.New(inject.Provide(newCounter, inject.AllowCopy()))
+	require.True(t, c.Has(new(Counter)))
+}
+
+func TestContainerProvideIf(t *testing.T) {
+	type Config struct{ Debug bool }
+	newMux := func() *http.ServeMux { return &http.ServeMux{} }
+	c := inject.New(
+		inject.Provide(&Config{Debug: true}),
+		inject.ProvideIf(func() bool { return false }, func() *http.Client { return &http.Client{} }),
+		inject.ProvideIf(func(cfg *Config) bool { return cfg.Debug }, newMux),
+	)
+	require.True(t, c.Has(new(*http.ServeMux)))
+	require.False(t, c.Has(new(*http.Client)))
+	findings := c.Report().Findings
+	require.Len(t, findings, 1)
+	require.Equal(t, "*http.Client", findings[0].Key)
+	require.Contains(t, findings[0].Message, "skipped by condition at ")
+}
+
+func TestContainerFactory(t *testing.T) {
+	type Pool struct {
+		newClient func() (*http.Client, error)
+	}
+	c := inject.New(
+		inject.Provide(func() *http.Client { return &http.Client{} }, inject.Prototype()),
+		inject.Provide(func(newClient func() (*http.Client, error)) *Pool { return &Pool{newClient: newClient} }),
+	)
+	var pool *Pool
+	require.NoError(t, c.Extract(&pool))
+	client1, err := pool.newClient()
+	require.NoError(t, err)
+	client2, err := pool.newClient()
+	require.NoError(t, err)
+	require.False(t, client1 == client2)
+}
+
+func TestContainerBuild(t *testing.T) {
+	c := inject.New(
+		inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }),
+	)
+	newServer := func(mux *http.ServeMux, addr string) *http.Server { return &http.Server{Handler: mux, Addr: addr} }
+	built, err := c.Build(newServer, ":8080")
+	require.NoError(t, err)
+	server := built.(*http.Server)
+	require.Equal(t, ":8080", server.Addr)
+	require.NotNil(t, server.Handler)
+
+	mux := &http.ServeMux{}
+	built, err = c.Build(newServer, ":8080", mux, inject.PreferExtras())
+	require.NoError(t, err)
+	require.True(t, built.(*http.Server).Handler == mux)
+}
+
+func TestContainerAutoConvert(t *testing.T) {
+	type Timeout time.Duration
+	c := inject.New(
+		inject.Provide(func() Timeout { return Timeout(time.Second) }),
+		inject.Provide(func(timeout time.Duration) *http.Client { return &http.Client{Timeout: timeout} }),
+		inject.AutoConvert(),
+	)
+	var client *http.Client
+	require.NoError(t, c.Extract(&client))
+	require.Equal(t, time.Second, client.Timeout)
+}
+
+func TestContainerReport(t *testing.T) {
+	c := inject.New(
+		inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }, inject.As(new(http.Handler))),
+		inject.Provide(func(handler http.Handler) *http.Server { return &http.Server{Handler: handler} }),
+	)
+	report := c.Report()
+	require.Equal(t, 3, report.Definitions) // with inject.Resolver
+	require.Equal(t, 3, report.MaxDepth)
+	report.Duration = 0
+	data, err := json.Marshal(report)
+	require.NoError(t, err)
+	var decoded inject.CompileReport
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	require.Equal(t, report, decoded)
+}
+
+func TestContainerProvideStruct(t *testing.T) {
+	type Handler struct {
+		Mux *http.ServeMux `di:""`
+	}
+	handler := &Handler{}
+	c := inject.New(
+		inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }),
+		inject.Provide(handler),
+		inject.Provide(inject.OfType(&http.Server{Addr: ":8080"})),
+	)
+
+	var extracted *Handler
+	require.NoError(t, c.Extract(&extracted))
+	require.True(t, extracted == handler)
+	require.NotNil(t, handler.Mux)
+
+	var server1, server2 *http.Server
+	require.NoError(t, c.Extract(&server1))
+	require.NoError(t, c.Extract(&server2))
+	require.False(t, server1 == server2)
+	require.Equal(t, ":8080", server2.Addr)
+}
+
+func TestContainerExplain(t *testing.T) {
+	c := inject.New(
+		inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }, inject.As(new(http.Handler))),
+		inject.Provide(func(handler http.Handler) *http.Server { return &http.Server{Handler: handler} }, inject.WithName("server")),
+	)
+
+	var b strings.Builder
+	var server *http.Server
+	require.NoError(t, c.Explain(&server, &b, inject.Name("server")))
+	require.Equal(t, ""+
+		"*http.Server[server] [singleton, not built]\n"+
+		"  http.Handler [interface] resolved as *http.ServeMux: the only implementation\n"+
+		"    *http.ServeMux [singleton, not built]\n",
+		b.String())
+}
+
+func TestDebugHandler(t *testing.T) {
+	c := inject.New(
+		inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }, inject.As(new(http.Handler))),
+		inject.Provide(func(handler http.Handler) *http.Server { return &http.Server{Handler: handler} }),
+	)
+	var mux *http.ServeMux
+	require.NoError(t, c.Extract(&mux))
+	handler := inject.DebugHandler(c)
+
+	t.Run("definitions", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/inject/", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Equal(t, "application/json; charset=utf-8", rec.Header().Get("Content-Type"))
+
+		var definitions []struct {
+			Key          string   `json:"key"`
+			Lifetime     string   `json:"lifetime"`
+			Implements   []string `json:"implements"`
+			Dependencies []string `json:"dependencies"`
+			Created      bool     `json:"created"`
+			Duration     string   `json:"duration"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &definitions))
+		require.Equal(t, "*http.ServeMux", definitions[0].Key)
+		require.Equal(t, "singleton", definitions[0].Lifetime)
+		require.Equal(t, []string{"http.Handler"}, definitions[0].Implements)
+		require.True(t, definitions[0].Created)
+		require.NotEmpty(t, definitions[0].Duration)
+		require.Equal(t, "*http.Server", definitions[1].Key)
+		require.Equal(t, []string{"http.Handler"}, definitions[1].Dependencies)
+		require.False(t, definitions[1].Created)
+	})
+
+	t.Run("graph", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/inject/graph", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+
+		var graph struct {
+			Nodes []string `json:"nodes"`
+			Edges []struct {
+				From string `json:"from"`
+				To   string `json:"to"`
+			} `json:"edges"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &graph))
+		require.Contains(t, graph.Nodes, "*http.Server")
+		var edges []string
+		for _, edge := range graph.Edges {
+			edges = append(edges, edge.From+" -> "+edge.To)
+		}
+		require.Equal(t, []string{"http.Handler -> *http.Server", "di.Resolver -> inject.Resolver"}, edges)
+	})
+
+	t.Run("graph in dot format", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/inject/graph.dot", nil))
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), "digraph")
+		require.Contains(t, rec.Header().Get("Content-Disposition"), "graph.dot")
+	})
+
+	t.Run("handler does not create instances", func(t *testing.T) {
+		for _, path := range []string{"/debug/inject/", "/debug/inject/graph", "/debug/inject/graph.dot"} {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+		}
+		for _, def := range c.Definitions() {
+			require.Equal(t, def.Type == reflect.TypeOf(mux), def.Created, def.Type.String())
+		}
+	})
+
+	t.Run("handler is read-only", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/debug/inject/", nil))
+		require.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+}
+
+func TestContainerWithOverrides(t *testing.T) {
+	var cleanups []string
+	c := inject.New(
+		inject.Provide(ProvideAddr("0.0.0.0", "8080")),
+		inject.Provide(func() (*http.ServeMux, func()) {
+			return &http.ServeMux{}, func() { cleanups = append(cleanups, "mux") }
+		}, inject.As(new(http.Handler))),
+		inject.Provide(func(addr Addr, handler http.Handler) (*http.Server, func()) {
+			return NewHTTPServer(addr, handler), func() { cleanups = append(cleanups, "server") }
+		}),
+	)
+
+	var mux *http.ServeMux
+	require.NoError(t, c.Extract(&mux))
+
+	var server *http.Server
+	c.WithOverrides(func(c *inject.Container) {
+		require.NoError(t, c.Extract(&server))
+		require.Equal(t, "127.0.0.1:80", server.Addr)
+
+		var scopeMux *http.ServeMux
+		require.NoError(t, c.Extract(&scopeMux))
+		require.Equal(t, fmt.Sprintf("%p", mux), fmt.Sprintf("%p", scopeMux), "unaffected singleton must be shared")
+	}, inject.Replace(ProvideAddr("127.0.0.1", "80")))
+	require.Equal(t, []string{"server"}, cleanups, "only scope instances must be cleaned")
+
+	var parentServer *http.Server
+	require.NoError(t, c.Extract(&parentServer))
+	require.Equal(t, "0.0.0.0:8080", parentServer.Addr)
+	require.NotEqual(t, fmt.Sprintf("%p", server), fmt.Sprintf("%p", parentServer))
+}
+
+func TestContainerWithOverridesSettings(t *testing.T) {
+	t.Run("derived container keeps active profiles", func(t *testing.T) {
+		c := inject.New(
+			inject.Provide(func() *http.ServeMux { return &http.ServeMux{} }),
+			inject.ActiveProfiles("dev"),
+		)
+		c.WithOverrides(func(derived *inject.Container) {
+			require.True(t, derived.Has(new(*http.Client)))
+			require.False(t, derived.Has(new(*http.Server)))
+		},
+			inject.Profile("dev", inject.Provide(func() *http.Client { return &http.Client{} })),
+			inject.Profile("prod", inject.Provide(func() *http.Server { return &http.Server{} })),
+		)
+	})
+
+	t.Run("derived container of eager container is built", func(t *testing.T) {
+		var built []string
+		c := inject.New(
+			inject.Provide(func() *http.ServeMux {
+				built = append(built, "mux")
+				return &http.ServeMux{}
+			}),
+			inject.Eager(),
+		)
+		require.Equal(t, []string{"mux"}, built)
+		c.WithOverrides(func(derived *inject.Container) {
+			require.Equal(t, []string{"mux", "fake mux"}, built)
+		}, inject.Replace(func() *http.ServeMux {
+			built = append(built, "fake mux")
+			return &http.ServeMux{}
+		}))
+	})
+}
+
+func TestContainerWithOverridesInterface(t *testing.T) {
+	newScheduler := func(clock TimeSource) *Scheduler { return &Scheduler{clock: clock} }
+	newJobs := func(scheduler *Scheduler) *Jobs { return &Jobs{scheduler: scheduler} }
+	provideScheduler := inject.Bundle(
+		inject.Provide(newScheduler),
+		inject.Provide(newJobs),
+	)
+	for _, test := range []struct {
+		name     string
+		provider inject.Option
+		override inject.Option
+	}{
+		{
+			name:     "interface result replaces binding",
+			provider: inject.Provide(NewSystemTime, inject.As(new(TimeSource))),
+			override: inject.Replace(func() TimeSource { return &FakeTime{} }),
+		},
+		{
+			name:     "fake of another type replaces binding of As() interface",
+			provider: inject.Provide(NewSystemTime, inject.As(new(TimeSource))),
+			override: inject.Replace(NewFakeTime, inject.As(new(TimeSource))),
+		},
+		{
+			name: "fake of another type replaces Bind() binding",
+			provider: inject.Bundle(
+				inject.Provide(NewSystemTime),
+				inject.Bind(new(TimeSource), new(SystemTime)),
+			),
+			override: inject.Replace(NewFakeTime, inject.As(new(TimeSource))),
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			c := inject.New(test.provider, provideScheduler)
+			c.WithOverrides(func(derived *inject.Container) {
+				var jobs *Jobs
+				require.NoError(t, derived.Extract(&jobs))
+				require.IsType(t, &FakeTime{}, jobs.scheduler.clock)
+				var sources []TimeSource
+				require.NoError(t, derived.Extract(&sources))
+				require.Len(t, sources, 1)
+				require.IsType(t, &FakeTime{}, sources[0])
+			}, test.override)
+
+			var jobs *Jobs
+			require.NoError(t, c.Extract(&jobs))
+			require.IsType(t, &SystemTime{}, jobs.scheduler.clock)
+		})
+	}
+}
+
+// TimeSource
+type TimeSource interface {
+	Now() time.Time
+}
+
+// SystemTime
+type SystemTime struct{}
+
+// NewSystemTime
+func NewSystemTime() *SystemTime { return &SystemTime{} }
+
+// Now
+func (*SystemTime) Now() time.Time { return time.Now() }
+
+// FakeTime
+type FakeTime struct{}
+
+// NewFakeTime
+func NewFakeTime() *FakeTime { return &FakeTime{} }
+
+// Now
+func (*FakeTime) Now() time.Time { return time.Time{} }
+
+// Scheduler
+type Scheduler struct {
+	clock TimeSource
+}
+
+// Jobs
+type Jobs struct {
+	scheduler *Scheduler
+}
+
 // Addr
 type Addr string
 
@@ -55,7 +1619,81 @@ func NewMux() *http.ServeMux {
 	return &http.ServeMux{}
 }
 
+// StartServer
+func StartServer(mux *http.ServeMux) {}
+
+// FileServer
+type FileServer struct {
+	http.Handler
+}
+
+// NewFileServer
+func NewFileServer() FileServer {
+	return FileServer{Handler: http.FileServer(http.Dir("."))}
+}
+
+// Redirect
+type Redirect struct {
+	http.Handler
+}
+
+// NewRedirect
+func NewRedirect() Redirect {
+	return Redirect{Handler: http.RedirectHandler("/", http.StatusFound)}
+}
+
 // PrintAddr
 func PrintAddr(addr Addr) {
 	fmt.Println(addr)
 }
+
+// Peer refers to the next peer with setter
+type Peer struct {
+	name string
+	next *NextPeer
+}
+
+// NewPeer
+func NewPeer(name string) func() *Peer {
+	return func() *Peer {
+		return &Peer{name: name}
+	}
+}
+
+// SetNext
+func (p *Peer) SetNext(next *NextPeer) {
+	p.next = next
+}
+
+// NextPeer refers to the peer with setter
+type NextPeer struct {
+	peer *Peer
+}
+
+// NewNextPeer
+func NewNextPeer() *NextPeer {
+	return &NextPeer{}
+}
+
+// SetPeer
+func (p *NextPeer) SetPeer(params PeerParameters) {
+	p.peer = params.Peer
+}
+
+// PeerParameters
+type PeerParameters struct {
+	di.Parameter
+	Peer *Peer `di:"a"`
+}
+
+// writerFunc is an io.Writer function.
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
+
+// location returns source location of function.
+func location(fn interface{}) string {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	file, line := f.FileLine(f.Entry())
+	return fmt.Sprintf("%s:%d", file, line)
+}
@@ -0,0 +1,124 @@
+package inject
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DebugHandler returns read-only handler of container state. Mount it next to pprof in non-production builds:
+//
+//   mux.Handle("/debug/inject/", inject.DebugHandler(container))
+//
+// The handler serves definitions with their dependencies, built markers and construction timings as JSON. Requests
+// with path that ends with /graph return the dependency graph as JSON, /graph.dot returns it in DOT format.
+// The handler never creates instances, so it is safe to use it while the container resolves types.
+func DebugHandler(c *Container) http.Handler {
+	return debugHandler{container: c}
+}
+
+// debugHandler serves container state.
+type debugHandler struct {
+	container *Container
+}
+
+// debugDefinition is a JSON representation of a definition.
+type debugDefinition struct {
+	Key          string   `json:"key"`
+	Type         string   `json:"type"`
+	Name         string   `json:"name,omitempty"`
+	Lifetime     string   `json:"lifetime"`
+	Implements   []string `json:"implements,omitempty"`
+	Dependencies []string `json:"dependencies,omitempty"`
+	Location     string   `json:"location,omitempty"`
+	Function     string   `json:"function,omitempty"`
+	Created      bool     `json:"created"`
+	CreatedAt    string   `json:"createdAt,omitempty"`
+	Duration     string   `json:"duration,omitempty"`
+	Deprecated   string   `json:"deprecated,omitempty"`
+	Description  string   `json:"description,omitempty"`
+}
+
+// debugGraph is a JSON representation of the dependency graph. Edges are directed from dependency to dependent.
+type debugGraph struct {
+	Nodes []string    `json:"nodes"`
+	Edges []debugEdge `json:"edges"`
+}
+
+// debugEdge is a JSON representation of a graph edge.
+type debugEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+func (h debugHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/graph.dot"):
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="graph.dot"`)
+		_, _ = h.container.container.Graph().WriteTo(w)
+	case strings.HasSuffix(r.URL.Path, "/graph"):
+		h.json(w, h.graph())
+	default:
+		h.json(w, h.definitions())
+	}
+}
+
+// definitions returns definitions in registration order.
+func (h debugHandler) definitions() []debugDefinition {
+	infos := h.container.Definitions()
+	definitions := make([]debugDefinition, 0, len(infos))
+	for _, info := range infos {
+		def := debugDefinition{
+			Key:         info.Key.String(),
+			Type:        info.Type.String(),
+			Name:        info.Name,
+			Lifetime:    info.Lifetime.String(),
+			Function:    info.Location.Function,
+			Created:     info.Created,
+			Deprecated:  info.Deprecated,
+			Description: info.Description,
+		}
+		for _, iface := range info.Implements {
+			def.Implements = append(def.Implements, iface.String())
+		}
+		for _, dependency := range info.Dependencies {
+			def.Dependencies = append(def.Dependencies, dependency.String())
+		}
+		if info.Location.File != "" {
+			def.Location = info.Location.String()
+		}
+		if !info.CreatedAt.IsZero() {
+			def.CreatedAt = info.CreatedAt.Format(time.RFC3339Nano)
+			def.Duration = info.Duration.String()
+		}
+		definitions = append(definitions, def)
+	}
+	return definitions
+}
+
+// graph returns the dependency graph of definitions.
+func (h debugHandler) graph() debugGraph {
+	graph := debugGraph{Nodes: []string{}, Edges: []debugEdge{}}
+	for _, info := range h.container.Definitions() {
+		graph.Nodes = append(graph.Nodes, info.Key.String())
+		for _, dependency := range info.Dependencies {
+			graph.Edges = append(graph.Edges, debugEdge{From: dependency.String(), To: info.Key.String()})
+		}
+	}
+	return graph
+}
+
+// json writes value as JSON response.
+func (h debugHandler) json(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(v)
+}
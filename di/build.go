@@ -0,0 +1,114 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/defval/inject/v2/di/internal/reflection"
+)
+
+// BuildParams is a `Build()` method options. IsPreferExtras fills parameters from extras even if their types resolve
+// from the container.
+type BuildParams struct {
+	IsPreferExtras bool
+}
+
+func (p BuildParams) apply(params *BuildParams) {
+	*params = p
+}
+
+// BuildOption
+type BuildOption interface {
+	apply(params *BuildParams)
+}
+
+// Build calls constructor and returns its result without registering it in the container. Parameters are resolved
+// from the container, parameters of types that do not resolve are filled from extras: each parameter takes the first
+// unused extra assignable to its type, so extras of the same type are taken in order. Build fails if an extra is not
+// used. Cleanup returned by the constructor runs on container cleanup.
+//
+//   // func NewOrderProcessor(db *sql.DB, orderID string) *OrderProcessor
+//   processor, err := c.Build(NewOrderProcessor, []interface{}{"42"})
+func (c *Container) Build(constructor interface{}, extras []interface{}, options ...BuildOption) (interface{}, error) {
+	params := BuildParams{}
+	for _, opt := range options {
+		opt.apply(&params)
+	}
+	if !c.isCompiled() {
+		return nil, fmt.Errorf("container not compiled")
+	}
+	if constructor == nil {
+		return nil, fmt.Errorf("the build function must be a function like `func([dep1, dep2, ...]) (<result>, [cleanup, error])`, got `%s`", "nil")
+	}
+	if !reflection.IsFunc(constructor) {
+		return nil, fmt.Errorf("the build function must be a function like `func([dep1, dep2, ...]) (<result>, [cleanup, error])`, got `%s`",
+			reflect.TypeOf(constructor))
+	}
+	fn := reflection.InspectFunction(constructor)
+	if !isCtorSignature(fn) {
+		return nil, fmt.Errorf("the build function must be a function like `func([dep1, dep2, ...]) (<result>, [cleanup, error])`, got `%s`",
+			fn.Type)
+	}
+	for i, extra := range extras {
+		if extra == nil {
+			return nil, fmt.Errorf("extra argument %d is nil", i)
+		}
+	}
+	sig := signatures.Get(fn)
+	used := make([]bool, len(extras))
+	args := make([]reflect.Value, 0, len(sig.params))
+	for _, param := range sig.params {
+		var resolvable bool
+		c.read(func() {
+			_, resolvable = param.ResolveProvider(c)
+		})
+		if !resolvable || params.IsPreferExtras {
+			if value, ok := takeExtra(param.res, extras, used); ok {
+				args = append(args, value)
+				continue
+			}
+		}
+		value, err := param.ResolveValue(c)
+		if err != nil {
+			return nil, fmt.Errorf("could not resolve build parameters: %w", err)
+		}
+		args = append(args, value)
+	}
+	for i, extra := range extras {
+		if !used[i] {
+			return nil, fmt.Errorf("extra argument %d of type `%s` does not match build parameters", i, reflect.TypeOf(extra))
+		}
+	}
+	if sig.context {
+		args = append([]reflect.Value{reflect.ValueOf(c.buildContext())}, args...)
+	}
+	value, err := c.call(&providerConstructor{ctor: fn, ctorType: sig.ctorType, result: sig.result}, args)
+	if err != nil {
+		return nil, err
+	}
+	return value.Interface(), nil
+}
+
+// takeExtra returns the first unused extra assignable to type and marks it used.
+func takeExtra(typ reflect.Type, extras []interface{}, used []bool) (reflect.Value, bool) {
+	for i, extra := range extras {
+		if !used[i] && reflect.TypeOf(extra).AssignableTo(typ) {
+			used[i] = true
+			return reflect.ValueOf(extra), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// isCtorSignature checks that function results are like constructor results.
+func isCtorSignature(fn *reflection.Func) bool {
+	switch fn.NumOut() {
+	case 1:
+		return true
+	case 2:
+		return reflection.IsError(fn.Out(1)) || reflection.IsCleanup(fn.Out(1))
+	case 3:
+		return reflection.IsCleanup(fn.Out(1)) && reflection.IsError(fn.Out(2))
+	}
+	return false
+}
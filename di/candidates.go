@@ -0,0 +1,158 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// candidateFilter keeps definitions that match one criterion of parameter.
+type candidateFilter struct {
+	criterion string                       // criterion like `name=sessions` or `tag region=eu`
+	keep      func(def *definition) bool   // definition matches criterion
+	available func(def *definition) string // value of definition for criterion, empty if it has no value
+}
+
+// candidateFilters returns filters of parameter in order of application: type or interface, name and tags sorted by
+// key. Unnamed parameter is matched by unnamed definitions only, so its type filter checks name too.
+func (c *Container) candidateFilters(p parameter) []candidateFilter {
+	filters := []candidateFilter{{
+		criterion: p.res.String(),
+		keep: func(def *definition) bool {
+			if p.name == "" && def.key.name != "" {
+				return false
+			}
+			if p.res.Kind() == reflect.Interface {
+				return c.boundAs(def, p.res) || p.name != "" && c.boundWithName(def, p.name, p.res)
+			}
+			return def.key.res == p.res && (p.impl || !def.exclusive)
+		},
+	}}
+	if p.name != "" {
+		filters = append(filters, candidateFilter{
+			criterion: fmt.Sprintf("name=%s", p.name),
+			keep: func(def *definition) bool {
+				return def.key.name == p.name || c.boundWithName(def, p.name, p.res)
+			},
+			available: func(def *definition) string {
+				if def.key.name == "" {
+					return ""
+				}
+				return fmt.Sprintf("name=%s", def.key.name)
+			},
+		})
+	}
+	keys := make([]string, 0, len(p.tags))
+	for k := range p.tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		k, v := k, p.tags[k]
+		filters = append(filters, candidateFilter{
+			criterion: fmt.Sprintf("tag %s=%s", k, v),
+			keep: func(def *definition) bool {
+				value, ok := def.tags[k]
+				return ok && value == v
+			},
+			available: func(def *definition) string {
+				value, ok := def.tags[k]
+				if !ok {
+					return ""
+				}
+				return fmt.Sprintf("%s=%s", k, value)
+			},
+		})
+	}
+	return filters
+}
+
+// selection is a result of candidate filtering. If no definition passed, eliminated is a filter that removed the last
+// candidates and remaining are candidates before it.
+type selection struct {
+	candidates definitionList
+	matched    []string // criteria passed by remaining candidates
+	eliminated *candidateFilter
+	remaining  definitionList
+}
+
+// selectCandidates applies filters of parameter to definitions one by one.
+func (c *Container) selectCandidates(p parameter) selection {
+	var s selection
+	candidates := c.definitions
+	for i, filter := range c.candidateFilters(p) {
+		var kept definitionList
+		for _, def := range candidates {
+			if filter.keep(def) {
+				kept = append(kept, def)
+			}
+		}
+		if len(kept) == 0 {
+			if i != 0 {
+				filter := filter
+				s.eliminated = &filter
+				s.remaining = candidates
+			}
+			return s
+		}
+		if i != 0 {
+			s.matched = append(s.matched, filter.criterion)
+		}
+		candidates = kept
+	}
+	s.candidates = candidates
+	return s
+}
+
+// hint explains which filter eliminated the last candidates, for example:
+//
+//   2 implementations of Cache matched name=sessions but none have tag region=eu; available: region=us
+//
+// Selection without candidates of the parameter type has no hint.
+func (s selection) hint(p parameter) string {
+	if s.eliminated == nil {
+		return ""
+	}
+	noun := "definition"
+	if p.res.Kind() == reflect.Interface {
+		noun = "implementation"
+	}
+	if len(s.remaining) != 1 {
+		noun += "s"
+	}
+	hint := fmt.Sprintf("%d %s of %s", len(s.remaining), noun, p.res)
+	if len(s.matched) != 0 {
+		hint += " matched " + strings.Join(s.matched, ", ")
+	}
+	seen := map[string]bool{}
+	var available []string
+	for _, def := range s.remaining {
+		if value := s.eliminated.available(def); value != "" && !seen[value] {
+			seen[value] = true
+			available = append(available, value)
+		}
+	}
+	sort.Strings(available)
+	if len(available) == 0 {
+		available = append(available, "none")
+	}
+	return fmt.Sprintf("%s but none have %s; available: %s", hint, s.eliminated.criterion, strings.Join(available, ", "))
+}
+
+// selectedProvider selects definitions of parameter type or implementations of parameter interface by name and tags.
+// If several definitions match, the returned provider follows interface ambiguity rules.
+func (c *Container) selectedProvider(p parameter) (internalProvider, bool) {
+	candidates := c.selectCandidates(p).candidates
+	switch len(candidates) {
+	case 0:
+		return nil, false
+	case 1:
+		return candidates[0].provider, true
+	}
+	candidates.Sort()
+	return &providerInterface{
+		res:   key{name: p.name, res: p.res, typ: ptInterface, tags: p.tags.String()},
+		impls: candidates,
+	}, true
+}
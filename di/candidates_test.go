@@ -0,0 +1,81 @@
+package di
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectCandidates(t *testing.T) {
+	writer := reflect.TypeOf(new(io.Writer)).Elem()
+	builder := reflect.TypeOf(&strings.Builder{})
+	c := New()
+	provide := func(name string, tags Tags) {
+		c.Provide(func() *strings.Builder { return &strings.Builder{} }, ProvideParams{
+			Name:       name,
+			Tags:       tags,
+			Interfaces: []interface{}{new(io.Writer)},
+		})
+	}
+	provide("sessions", Tags{"region": "us", "tier": "premium"})
+	provide("sessions", Tags{"region": "us", "tier": "basic"})
+	provide("users", Tags{"region": "eu"})
+	provide("", Tags{"region": "eu"})
+
+	for _, tt := range []struct {
+		name       string
+		param      parameter
+		candidates int
+		hint       string
+	}{
+		{
+			name:       "unnamed interface matches unnamed implementations",
+			param:      parameter{res: writer},
+			candidates: 1,
+		},
+		{
+			name:       "name and tags are applied to implementations",
+			param:      parameter{res: writer, name: "sessions", tags: Tags{"region": "us", "tier": "basic"}},
+			candidates: 1,
+		},
+		{
+			name:       "name and tags are applied to definitions",
+			param:      parameter{res: builder, name: "sessions", tags: Tags{"region": "us"}},
+			candidates: 2,
+		},
+		{
+			name:  "tag eliminates named implementations",
+			param: parameter{res: writer, name: "sessions", tags: Tags{"region": "eu"}},
+			hint:  "2 implementations of io.Writer matched name=sessions but none have tag region=eu; available: region=us",
+		},
+		{
+			name:  "tags are applied in order of keys",
+			param: parameter{res: writer, name: "sessions", tags: Tags{"tier": "free", "region": "us"}},
+			hint: "2 implementations of io.Writer matched name=sessions, tag region=us but none have tag tier=free; " +
+				"available: tier=basic, tier=premium",
+		},
+		{
+			name:  "name eliminates implementations",
+			param: parameter{res: writer, name: "orders", tags: Tags{"region": "eu"}},
+			hint:  "4 implementations of io.Writer but none have name=orders; available: name=sessions, name=users",
+		},
+		{
+			name:  "tag without values of candidates",
+			param: parameter{res: builder, tags: Tags{"zone": "a"}},
+			hint:  "1 definition of *strings.Builder but none have tag zone=a; available: none",
+		},
+		{
+			name:  "type without candidates has no hint",
+			param: parameter{res: reflect.TypeOf(new(io.Reader)).Elem(), tags: Tags{"region": "eu"}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			selected := c.selectCandidates(tt.param)
+			require.Len(t, selected.candidates, tt.candidates)
+			require.Equal(t, tt.hint, selected.hint(tt.param))
+		})
+	}
+}
@@ -2,7 +2,15 @@ package di
 
 import (
 	"fmt"
+	"io"
+	"os"
 	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/emicklei/dot"
 
 	"github.com/defval/inject/v2/di/internal/graphkv"
 	"github.com/defval/inject/v2/di/internal/reflection"
@@ -14,6 +22,13 @@ type Interactor interface {
 	Invoke(fn interface{}, options ...InvokeOption) error
 }
 
+// Resolver is a read-only view of the container. The container provides it, so constructors that locate services
+// depend on Resolver instead of the container itself.
+type Resolver interface {
+	Extract(target interface{}, options ...ExtractOption) error
+	Has(target interface{}, options ...ExtractOption) bool
+}
+
 // Builder is helper interface.
 type Builder interface {
 	Provide(provider interface{}, options ...ProvideOption)
@@ -22,37 +37,364 @@ type Builder interface {
 // New create new container.
 func New() *Container {
 	return &Container{
-		graph: graphkv.New(),
+		graph:       graphkv.New(),
+		maxDepth:    defaultMaxDepth,
+		deprecation: os.Stderr,
+		retryLog:    os.Stderr,
 	}
 }
 
-// Container is a dependency injection container.
+// Container is a dependency injection container. It is safe for concurrent use.
+//
+// The storage lock guards the graph and definitions. Provide(), Bind(), Compile() and Inherit() hold it for writing,
+// resolving holds it for reading only while looking up providers. The storage lock is never held while a provider
+// creates an instance, so it is never acquired inside per-definition singleton locks and constructors could extract
+// types from the container. Inherit() locks the container before the parent one.
+//
+// Singleton is created once no matter which key resolves it: the definition type, bound interfaces, additional names,
+// tags or conversions. All of them resolve the definition node, so replacements and derived containers keep it too.
+// Only Fresh extraction creates another instance.
 type Container struct {
-	compiled bool
-	graph    *graphkv.Graph
-	cleanups []func()
+	storage     sync.RWMutex // guards compiled, graph, definitions and index
+	compiled    bool
+	graph       *graphkv.Graph
+	definitions definitionList
+	byKey       map[key]*definition
+	index       nodeIndex     // built at compile
+	maxDepth    int           // limit of resolution chain length
+	unexported  bool          // parameter structs set unexported fields
+	compileLog  io.Writer     // compile summary output
+	graphLog    io.Writer     // resolved graph output
+	duration    time.Duration // duration of compile
+	deprecation io.Writer     // deprecation warnings output
+	retryLog    io.Writer     // failed retry attempts output
+	strict      bool          // resolution of deprecated definitions fails
+	recoverMode RecoverMode   // panic policy of constructors and invoked functions
+	convert     bool          // missing types resolve by conversion of definitions
+	explicit    bool          // interfaces resolve only by explicit bindings
+	entryPoints []interface{} // prune roots
+	skipped     []skipped     // definitions skipped by condition
+	phase       int32         // initialization phase, see BuildAll()
+	step        step          // current assembly step, see Recover()
+	fallbacks   []*Container  // containers that resolve missing types
+	lifetime    Lifetime      // default lifetime of definitions
+	order       []*definition // construction order computed by Prepare()
+	stats       statsCounters // resolution statistics, see Stats()
+	released    int32         // creation of instances is rejected, see ReleaseInstances()
+	ctx         atomic.Value  // context of context-first constructors, see BuildContext()
+	dev         *devMode      // development mode checks, nil if disabled
+	mu          sync.Mutex    // guards cleanups, deprecation warnings and retry log
+	cleanups    []func()
+	cleaned     int // number of cleanups called by Cleanup()
 }
 
-// Provide adds constructor into container with parameters.
+// Provide adds constructor into container with parameters. Constructor could take context.Context as the first
+// parameter, the context is not a dependency: constructors called by BuildContext() receive its context, others
+// receive context.Background(). Context in other positions causes panic.
+//
+//   c.Provide(func(ctx context.Context, config *Config) (*sql.DB, error) { ... })
 func (c *Container) Provide(constructor interface{}, options ...ProvideOption) {
+	c.storage.Lock()
+	defer c.storage.Unlock()
+	c.mustNotCompiled()
+	params := ProvideParams{}
+	for _, opt := range options {
+		opt.apply(&params)
+	}
+	c.step = step{stage: StageProvide}
+	if reflection.IsFunc(constructor) {
+		c.step.location = reflection.InspectFunction(constructor).Location
+	}
+	ctor := newProviderConstructor(params.Name, params.Tags, constructor)
+	if !c.satisfied(ctor.Key(), ctor.ctor.Location, params.Condition) {
+		return
+	}
+	c.provide(ctor, ctor.ctor.Location, params)
+}
+
+// ProvideValue adds already created value into container with parameters. The value type is a definition type.
+//
+//   c.ProvideValue(":8080", di.ProvideParams{Name: "addr"})
+func (c *Container) ProvideValue(value interface{}, options ...ProvideOption) {
+	c.storage.Lock()
+	defer c.storage.Unlock()
+	c.mustNotCompiled()
+	params := ProvideParams{}
+	for _, opt := range options {
+		opt.apply(&params)
+	}
+	provider := newProviderValue(params.Name, value)
+	provider.tags = params.Tags
+	location := reflection.Location(params.Location)
+	c.step = step{stage: StageProvide, location: location}
+	if provider.value.Kind() == reflect.Chan && provider.value.IsNil() && !params.IsAllowNil {
+		panicf("%s: %s: the value is nil channel, operations on it block forever", location, provider.Key())
+	}
+	if provider.value.Kind() == reflect.Ptr && provider.value.IsNil() && len(params.Interfaces) != 0 {
+		panicf("%s: %s: nil pointer could not be bound to interfaces", location, provider.Key())
+	}
+	if params.Cleanup != nil && params.IsAppend {
+		panicf("%s: %s: appended value could not have cleanup", location, provider.Key())
+	}
+	provider.cleanup = params.Cleanup
+	if !c.satisfied(provider.Key(), location, params.Condition) {
+		return
+	}
+	if params.IsAppend {
+		c.appendValue(provider, location, params)
+		return
+	}
+	c.provide(provider, location, params)
+}
+
+// ProvideStruct adds pointer to struct into container. Tagged fields of the struct are set to dependencies on the first
+// resolution and the instance is shared like a provided value. Fields are tagged like fields of parameter structs.
+// A struct value is a template: its copy with fields set to dependencies is created like a constructor result.
+//
+//   c.ProvideStruct(&Handler{})
+func (c *Container) ProvideStruct(instance interface{}, options ...ProvideOption) {
+	c.storage.Lock()
+	defer c.storage.Unlock()
+	c.mustNotCompiled()
 	params := ProvideParams{}
 	for _, opt := range options {
 		opt.apply(&params)
 	}
-	provider := internalProvider(newProviderConstructor(params.Name, constructor))
+	location := reflection.Location(params.Location)
+	c.step = step{stage: StageProvide, location: location}
+	typ := reflect.TypeOf(instance)
+	shared := typ == nil || typ.Kind() != reflect.Struct
+	if shared && params.IsPrototype {
+		panicf("%s: provided instance of `%s` could not be prototype, use ProvideType()", location, typ)
+	}
+	c.provideStruct(newProviderStruct(params.Name, instance, shared, c.unexported), location, params)
+}
+
+// ProvideType adds struct type into container. Each resolution allocates a copy of the template and sets its tagged
+// fields to dependencies, so definitions of types are prototypes. The template is a struct or a pointer to struct,
+// the definition type is the template type.
+//
+//   c.ProvideType(&Handler{})
+func (c *Container) ProvideType(template interface{}, options ...ProvideOption) {
+	c.storage.Lock()
+	defer c.storage.Unlock()
+	c.mustNotCompiled()
+	params := ProvideParams{}
+	for _, opt := range options {
+		opt.apply(&params)
+	}
+	params.IsPrototype = true
+	location := reflection.Location(params.Location)
+	c.step = step{stage: StageProvide, location: location}
+	c.provideStruct(newProviderStruct(params.Name, template, false, c.unexported), location, params)
+}
+
+// provideStruct adds struct provider into container.
+func (c *Container) provideStruct(provider *providerStruct, location reflection.Location, params ProvideParams) {
+	if provider.fields.err != nil {
+		panicf("%s: %s", location, provider.fields.err)
+	}
+	provider.tags = params.Tags
+	if !c.satisfied(provider.Key(), location, params.Condition) {
+		return
+	}
+	c.provide(provider, location, params)
+}
+
+// skipped is a definition that is not registered because its condition returned false.
+type skipped struct {
+	key       key
+	condition reflection.Location
+}
+
+// unwrapped returns provider without singleton and retry wrappers.
+func unwrapped(provider internalProvider) internalProvider {
+	switch p := provider.(type) {
+	case *singletonWrapper:
+		return unwrapped(p.internalProvider)
+	case *providerRetry:
+		return unwrapped(p.internalProvider)
+	}
+	return provider
+}
+
+// satisfied calls condition of definition with provided values and records the definition if it is skipped. Nil
+// condition is satisfied.
+func (c *Container) satisfied(k key, location reflection.Location, condition interface{}) bool {
+	if condition == nil {
+		return true
+	}
+	if !reflection.IsFunc(condition) {
+		panicf("%s: %s: the condition must be a function like `func([value1, value2, ...]) bool`, got `%s`", location,
+			k, reflect.TypeOf(condition))
+	}
+	fn := reflection.InspectFunction(condition)
+	if fn.NumOut() != 1 || fn.Out(0).Kind() != reflect.Bool {
+		panicf("%s: %s: the condition must be a function like `func([value1, value2, ...]) bool`, got `%s`", location,
+			k, fn.Type)
+	}
+	args := make([]reflect.Value, fn.NumIn())
+	for i := range args {
+		var def *definition
+		if def = c.definition(key{res: fn.In(i), typ: ptConstructor}); def != nil {
+			switch provider := unwrapped(def.provider).(type) {
+			case *providerValue:
+				args[i] = provider.value
+			case *providerStruct:
+				if provider.instance {
+					args[i] = provider.value
+				}
+			}
+		}
+		if !args[i].IsValid() {
+			panicf("%s: %s: condition parameter %s must be a value provided before", location, k, fn.In(i))
+		}
+	}
+	if fn.Call(args)[0].Bool() {
+		return true
+	}
+	c.skipped = append(c.skipped, skipped{key: k, condition: fn.Location})
+	return false
+}
+
+// appendValue appends value to the existing appendable value definition or registers a new one. Parameters of the
+// first registration are used for the definition.
+func (c *Container) appendValue(provider *providerValue, location reflection.Location, params ProvideParams) {
+	if kind := provider.value.Kind(); kind != reflect.Slice && kind != reflect.Map {
+		panicf("%s: appended value must be a slice or a map, got `%s`", location, provider.value.Type())
+	}
+	def := c.definition(provider.Key())
+	if def == nil {
+		// the definition owns a copy of the value, so appends do not change values of callers
+		value := provider.value
+		provider.appendable = true
+		provider.sources = map[interface{}]reflection.Location{}
+		if value.Kind() == reflect.Slice {
+			provider.value = reflect.MakeSlice(value.Type(), 0, value.Len())
+		} else {
+			provider.value = reflect.MakeMapWithSize(value.Type(), value.Len())
+		}
+		provider.append(value, location)
+		c.provide(provider, location, params)
+		return
+	}
+	existing, ok := def.provider.(*providerValue)
+	if !ok || !existing.appendable {
+		panicf("The `%s` type already exists in container", c.describe(def.key))
+	}
+	existing.append(provider.value, location)
+}
+
+// provide registers provider as definition.
+func (c *Container) provide(provider internalProvider, location reflection.Location, params ProvideParams) {
 	key := provider.Key()
-	if c.graph.Exists(key) {
-		panicf("The `%s` type already exists in container", provider.Key())
+	registerTypeNames(key.res)
+	c.step = step{stage: StageProvide, key: key, location: location}
+	lifetime, lifetimeSource := c.effectiveLifetime(provider, location, params)
+	params.IsPrototype = lifetime == Prototype
+	if isAnonymousStruct(key.res) && key.name == "" {
+		panicf("%s: %s result requires a name, use WithName() provide option", location, key)
+	}
+	if selfDependent(provider) {
+		panicf("%s: provider for %s depends on itself, provide the wrapped definition with WithName() and depend on it by name",
+			location, key)
+	}
+	var setters []setter
+	for _, name := range params.Setters {
+		setters = append(setters, newSetter(key, location, name))
+	}
+	if lock, ok := reflection.ContainedLock(key.res); ok && !params.IsAllowCopy {
+		panicf("%s: provide *%s instead of %s: %s contains %s", location, key.res, key.res, key.res, lock)
+	}
+	exists := c.graph.Exists(key)
+	if exists && params.IsDedup && !params.IsReplacement {
+		existing := c.definition(key)
+		if existing != nil && existing.isDuplicate(provider, params) {
+			return
+		}
+		if existing != nil {
+			panicf("The `%s` type is provided by different providers at %s and at %s", c.describe(key),
+				existing.location, location)
+		}
 	}
-	if !params.IsPrototype {
-		provider = asSingleton(provider)
+	if exists && !params.IsReplacement || c.graph.Exists(aliasKey(key)) {
+		panicf("The `%s` type already exists in container", c.describe(key))
+	}
+	var rebound []*providerInterface
+	if !exists && params.IsReplacement {
+		rebound = c.replacedInterfaces(key, params.Interfaces)
+		if len(rebound) == 0 {
+			panicf("The `%s` type not exists in container, nothing to replace", provider.Key())
+		}
+	}
+	if params.IsExclusive && len(params.Interfaces) == 0 {
+		panicf("%s: %s exclusive definition requires interfaces, use As() provide option", location, key)
+	}
+	value, isValue := provider.(*providerValue)
+	if !isValue && params.Cleanup != nil {
+		panicf("%s: %s: cleanup option is supported by values only, return cleanup from the constructor", location, key)
+	}
+	if isValue && params.IsLate {
+		panicf("%s: %s: provided value could not be late, it does not depend on definitions", location, key)
+	}
+	if params.RetryAttempts != 0 {
+		provider = c.withRetry(provider, params.RetryAttempts, params.RetryBackoff, params.IsExponentialBackoff)
+	}
+	// value with cleanup is created on the first resolution like a constructor result, so cleanups keep the order
+	if (!isValue || value.cleanup != nil) && !params.IsPrototype {
+		singleton := asSingleton(provider)
+		singleton.retryOnError = params.IsRetryOnError
+		provider = singleton
+	}
+	def := c.definition(key)
+	if def != nil {
+		// replacement takes the place of replaced definition
+		def.shadows = shadowedLocation(def, provider)
+		def.provider = provider
+		def.replaced = true
+		def.prototype = params.IsPrototype
+		def.source = lifetimeSource
+		def.exclusive = params.IsExclusive
+		def.late = params.IsLate
+		def.location = location
+		def.deprecated = params.Deprecated
+		def.label = params.Description
+		def.setters = setters
+		c.graph.Replace(key, provider)
+	} else {
+		def = &definition{
+			key:        key,
+			seq:        len(c.definitions) + 1,
+			order:      params.Order,
+			primary:    params.IsPrimary,
+			prototype:  params.IsPrototype,
+			source:     lifetimeSource,
+			exclusive:  params.IsExclusive,
+			late:       params.IsLate,
+			tags:       params.Tags,
+			location:   location,
+			deprecated: params.Deprecated,
+			label:      params.Description,
+			setters:    setters,
+			provider:   provider,
+		}
+		c.addDefinition(def)
+		// add provider to graph
+		c.graph.Add(key, provider)
+	}
+	// replacement of interface takes the place of its implementations
+	for _, iface := range rebound {
+		def.replaced = true
+		c.rebind(iface, def)
+	}
+	// process additional names
+	for _, name := range params.Names {
+		c.processProviderName(def, name)
 	}
-	// add provider to graph
-	c.graph.Add(key, provider)
 	// parse embed parameters
 	for _, param := range provider.ParameterList() {
 		if param.embed {
-			embed := newProviderEmbed(param)
+			embed := newProviderEmbed(param, c.unexported)
 			c.graph.Add(embed.Key(), embed)
 		}
 	}
@@ -63,53 +405,418 @@ func (c *Container) Provide(constructor interface{}, options ...ProvideOption) {
 	}
 	// process interfaces
 	for _, iface := range params.Interfaces {
-		c.processProviderInterface(provider, iface)
+		c.processProviderInterface(def, iface)
+	}
+	// process registries
+	if params.IsRegistry {
+		if key.name == "" {
+			panicf("%s: %s registry definition requires a name", location, key)
+		}
+		c.processProviderRegistry(def, key.res)
+		for _, iface := range def.implements {
+			c.processProviderRegistry(def, iface)
+		}
+	}
+	// process named groups
+	for _, group := range params.Groups {
+		c.processProviderGroup(def, group, key.res)
+		for _, iface := range def.implements {
+			c.processProviderGroup(def, group, iface)
+		}
+		def.join(group)
+	}
+}
+
+// selfDependent checks that provider requires its own result. It is usually a copy-paste mistake or an attempt to wrap
+// the existing definition of the type.
+func selfDependent(provider internalProvider) bool {
+	k := provider.Key()
+	for _, param := range provider.ParameterList() {
+		if !param.optional && param.group == "" && param.name == k.name && param.res == k.res && param.tags.String() == k.tags {
+			return true
+		}
+	}
+	return false
+}
+
+// Bind binds interface to existing definition of implementation type. It works like As() provide option for
+// definitions that could not be changed, for example, provided by library. Implementation is a value of definition type.
+//
+//   c.Provide(NewPostgresRepository) // func NewPostgresRepository() *PostgresRepository
+//   c.Bind(new(UserRepository), new(PostgresRepository))
+//
+// BindParams.Name names the interface key, the definition keeps its own name. Named bindings resolve by name
+// of extraction or of parameter field like named definitions.
+//
+//   c.Bind(new(Repository), new(PostgresRepository), di.BindParams{Name: "primary"})
+//   c.Bind(new(Repository), new(S3Repository), di.BindParams{Name: "audit"})
+//
+// Bind causes panic if definition does not exist or does not implement the interface.
+func (c *Container) Bind(iface interface{}, implementation interface{}, options ...BindOption) {
+	c.storage.Lock()
+	defer c.storage.Unlock()
+	c.mustNotCompiled()
+	params := BindParams{}
+	for _, opt := range options {
+		opt.apply(&params)
+	}
+	k := key{res: reflect.TypeOf(implementation), typ: ptConstructor}
+	c.step = step{stage: StageBind, key: k}
+	def := c.definition(k)
+	if def == nil {
+		panicf("Bind to %s: type not exists in container", k)
+	}
+	if params.Name == "" || params.Name == def.key.name {
+		c.processProviderInterface(def, iface)
+		return
+	}
+	c.processProviderBinding(def, iface, params.Name)
+}
+
+// Prune sets entry points of the container. Compile removes definitions that are not reachable from entry points.
+// Entry points are pointers to types like Extract() targets.
+//
+//   c.Prune(new(*http.Server))
+//   c.Compile() // unreachable definitions are removed before dependency checks
+func (c *Container) Prune(entryPoints ...interface{}) {
+	c.storage.Lock()
+	defer c.storage.Unlock()
+	c.mustNotCompiled()
+	c.entryPoints = append(c.entryPoints, entryPoints...)
+}
+
+// defaultMaxDepth is a default limit of resolution chain length.
+const defaultMaxDepth = 1000
+
+// SetMaxDepth sets the limit of resolution chain length. Resolution of longer chain returns ErrResolutionTooDeep
+// instead of growing the stack, which happens with runaway generated definitions. The default limit is 1000, raise it
+// for legitimate deep graphs.
+//
+//   c.SetMaxDepth(5000)
+func (c *Container) SetMaxDepth(depth int) {
+	if depth < 1 {
+		panicf("The max depth must be positive, got %d", depth)
+	}
+	c.storage.Lock()
+	defer c.storage.Unlock()
+	c.mustNotCompiled()
+	c.maxDepth = depth
+}
+
+// SetCompileLog sets writer of compile summary: number of definitions, construction order and chosen interface
+// implementations with the reason of the choice. The summary answers why the container is wired that way without
+// debugging. It is stable for the same definitions.
+//
+//   c.SetCompileLog(os.Stderr)
+func (c *Container) SetCompileLog(w io.Writer) {
+	c.storage.Lock()
+	defer c.storage.Unlock()
+	c.mustNotCompiled()
+	c.compileLog = w
+}
+
+// SetGraphLog sets writer of the resolved graph. After compile one line per definition is written: key, lifetime,
+// dependencies, interfaces that resolve to the definition and location. Lines are sorted by key, so logs of builds
+// could be compared to find what was wired differently.
+//
+//   c.SetGraphLog(os.Stderr)
+func (c *Container) SetGraphLog(w io.Writer) {
+	c.storage.Lock()
+	defer c.storage.Unlock()
+	c.mustNotCompiled()
+	c.graphLog = w
+}
+
+// SetDeprecationLog sets writer of deprecation warnings. A warning is written on the first resolution of deprecated
+// definition and contains the dependent definition, so it is clear who still uses it. Warnings are written to stderr
+// by default, nil writer disables them.
+func (c *Container) SetDeprecationLog(w io.Writer) {
+	c.storage.Lock()
+	defer c.storage.Unlock()
+	c.mustNotCompiled()
+	c.deprecation = w
+}
+
+// SetRetryLog sets writer of failed attempts of providers with retry. A line contains the definition key, the attempt
+// number and the error. Attempts are written to stderr by default, nil writer disables them.
+func (c *Container) SetRetryLog(w io.Writer) {
+	c.storage.Lock()
+	defer c.storage.Unlock()
+	c.mustNotCompiled()
+	c.retryLog = w
+}
+
+// SetStrictDeprecation makes resolution of deprecated definitions fail with ErrDeprecated instead of warning.
+func (c *Container) SetStrictDeprecation() {
+	c.storage.Lock()
+	defer c.storage.Unlock()
+	c.mustNotCompiled()
+	c.strict = true
+}
+
+// SetRecoverMode sets policy of panics in constructors and invoked functions. By default panics propagate with the
+// original stack. WrapError recovers them and returns ErrPanic from Extract() and Invoke(), the failed singleton is
+// constructed again on the next resolution.
+func (c *Container) SetRecoverMode(mode RecoverMode) {
+	c.storage.Lock()
+	defer c.storage.Unlock()
+	c.mustNotCompiled()
+	c.recoverMode = mode
+}
+
+// SetAutoConvert makes types that have no definitions resolve by conversion of a definition of a convertible type
+// with the same name and tags. Types are convertible if they have the same kind, for example, a defined type and its
+// underlying type. Interfaces are resolved only by bound definitions. If several definitions are convertible, the
+// resolution fails. Conversions are written to the compile log and explained by Explain().
+//
+//   c.ProvideValue(Timeout(5 * time.Second))
+//   c.SetAutoConvert()
+//   // time.Duration resolves as Timeout instance
+func (c *Container) SetAutoConvert() {
+	c.storage.Lock()
+	defer c.storage.Unlock()
+	c.mustNotCompiled()
+	c.convert = true
+}
+
+// SetExplicitBindings makes interfaces resolve only by definitions bound to exactly the requested interface with As()
+// or Bind(). Interfaces are not resolved through provided interfaces that embed them, for example, io.Reader does not
+// resolve as definition provided as io.ReadWriter. Not found error names definitions that implement the interface
+// but are not bound.
+func (c *Container) SetExplicitBindings() {
+	c.storage.Lock()
+	defer c.storage.Unlock()
+	c.mustNotCompiled()
+	c.explicit = true
+}
+
+// AllowUnexported allows tagged unexported fields of parameter structs. The fields are set with unsafe access that
+// bypasses Go visibility rules, use it only for types that could not be changed. Without it, tagged unexported field
+// causes compile panic or resolution error. AllowUnexported must be called before Provide().
+func (c *Container) AllowUnexported() {
+	c.storage.Lock()
+	defer c.storage.Unlock()
+	c.mustNotCompiled()
+	if len(c.definitions) != 0 {
+		panicf("AllowUnexported() must be called before Provide()")
 	}
+	c.unexported = true
 }
 
 // Compile compiles the container. It iterates over all nodes
-// in graph and register their parameters.
+// in graph and register their parameters. Repeated calls do nothing. Definitions could not be added after compile.
 func (c *Container) Compile() {
-	graphProvider := func() *Graph { return &Graph{graph: c.graph.DOTGraph()} }
+	c.storage.Lock()
+	defer c.storage.Unlock()
+	if c.compiled {
+		return
+	}
+	c.dev.compile(true)
+	defer c.dev.compile(false)
+	start := time.Now()
+	graphProvider := func() *Graph { return c.Graph() }
 	interactorProvider := func() Interactor { return c }
-	c.Provide(graphProvider)
-	c.Provide(interactorProvider)
+	resolverProvider := func() Resolver { return c }
+	constructors := []interface{}{graphProvider, interactorProvider, resolverProvider}
+	for _, constructor := range constructors {
+		ctor := newProviderConstructor("", nil, constructor)
+		c.provide(ctor, ctor.ctor.Location, ProvideParams{IsSingleton: true})
+	}
+	// container specific definitions could not be inherited
+	for _, def := range c.definitions[len(c.definitions)-len(constructors):] {
+		def.isolated = true
+	}
+	c.step = step{stage: StageConnect}
+	if len(c.entryPoints) != 0 {
+		c.prune()
+	}
 	for _, node := range c.graph.Nodes() {
-		c.registerProviderParameters(node.Value.(internalProvider))
+		provider := node.Value.(internalProvider)
+		c.step = step{stage: StageConnect, key: provider.Key()}
+		c.registerProviderParameters(provider)
 	}
-	if err := c.graph.CheckCycles(); err != nil {
-		panic(err.Error())
+	c.checkSetters()
+	c.step = step{stage: StageCycle}
+	if cycle, component := c.graph.ShortestCycle(); cycle != nil {
+		panic(c.cycleError(cycle, component))
 	}
+	c.buildIndex()
+	c.checkLate()
+	c.checkShadows()
 	c.compiled = true
+	c.duration = time.Since(start)
+	if c.compileLog != nil {
+		c.writeCompileLog(c.compileLog)
+	}
+	if c.graphLog != nil {
+		c.writeGraphLog(c.graphLog)
+	}
 }
 
 // Extract builds instance of target type and fills target pointer.
 func (c *Container) Extract(target interface{}, options ...ExtractOption) error {
+	param, err := c.extractParameter(target, options)
+	if err != nil {
+		return err
+	}
+	c.dev.enter(param.String())
+	value, err := param.ResolveValue(c)
+	c.stats.resolved(err)
+	if err != nil {
+		return err
+	}
+	targetValue := reflect.ValueOf(target).Elem()
+	targetValue.Set(value)
+	return nil
+}
+
+// ExtractOr builds instance of target type and fills target pointer. If the target type does not exist in the
+// container, ExtractOr fills target with fallback, nil fallback is a zero value. The fallback is not registered in
+// the container. Errors of building instance are returned as is.
+//
+//   var tracer Tracer
+//   err := c.ExtractOr(&tracer, noopTracer{})
+func (c *Container) ExtractOr(target interface{}, fallback interface{}, options ...ExtractOption) error {
+	param, err := c.extractParameter(target, options)
+	if err != nil {
+		return err
+	}
+	var exists bool
+	c.read(func() {
+		_, exists = param.ResolveProvider(c)
+	})
+	if exists {
+		return c.Extract(target, options...)
+	}
+	targetValue := reflect.ValueOf(target).Elem()
+	if fallback == nil {
+		targetValue.Set(reflect.Zero(targetValue.Type()))
+		return nil
+	}
+	if !reflect.TypeOf(fallback).AssignableTo(targetValue.Type()) {
+		return fmt.Errorf("fallback of type `%s` is not assignable to `%s`", reflect.TypeOf(fallback), targetValue.Type())
+	}
+	targetValue.Set(reflect.ValueOf(fallback))
+	return nil
+}
+
+// Has checks that target type could be extracted with the same options: the container has its definition and
+// interface definition has the only or the primary implementation. Has does not create instances.
+//
+//   if c.Has(new(*Metrics)) {
+//     // extract metrics
+//   }
+func (c *Container) Has(target interface{}, options ...ExtractOption) bool {
+	param, err := c.extractParameter(target, options)
+	if err != nil {
+		return false
+	}
+	var provider internalProvider
+	var exists bool
+	c.read(func() {
+		provider, exists = param.ResolveProvider(c)
+	})
+	if iface, ok := provider.(*providerInterface); ok {
+		_, err := iface.Implementation()
+		return err == nil
+	}
+	return exists
+}
+
+// extractParameter creates parameter of extraction target.
+func (c *Container) extractParameter(target interface{}, options []ExtractOption) (parameter, error) {
 	params := ExtractParams{}
 	for _, opt := range options {
 		opt.apply(&params)
 	}
-	if !c.compiled {
-		return fmt.Errorf("container not compiled")
+	if err := c.dev.checkCompiling(); err != nil {
+		return parameter{}, err
 	}
-	if target == nil {
-		return fmt.Errorf("extract target must be a pointer, got `nil`")
+	if !c.isCompiled() {
+		return parameter{}, fmt.Errorf("container not compiled")
 	}
-	if !reflection.IsPtr(target) {
-		return fmt.Errorf("extract target must be a pointer, got `%s`", reflect.TypeOf(target))
+	if err := c.checkTarget(target, params); err != nil {
+		return parameter{}, err
 	}
 	typ := reflect.TypeOf(target)
-	param := parameter{
+	if params.Type != nil {
+		if !params.Type.AssignableTo(typ.Elem()) {
+			return parameter{}, fmt.Errorf("extract target `%s` could not hold `%s`", typ, params.Type)
+		}
+		typ = reflect.PtrTo(params.Type)
+	}
+	registerTypeNames(typ.Elem())
+	if params.Group != "" && !isGroupType(typ.Elem()) {
+		return parameter{}, fmt.Errorf("extract target of group must be a pointer to slice or map with string keys, got `%s`", typ)
+	}
+	return parameter{
 		name:  params.Name,
 		res:   typ.Elem(),
+		tags:  params.Tags,
 		embed: isEmbedParameter(typ),
+		fresh: params.IsFresh,
+		group: params.Group,
+	}, nil
+}
+
+// checkTarget checks that extraction target is a pointer that could be filled. Each kind of invalid target has its
+// own error with the expected usage. Double pointer is a mistake if the container does not provide pointer of its
+// type but provides the type itself. Target of runtime type is checked by extractParameter().
+func (c *Container) checkTarget(target interface{}, params ExtractParams) error {
+	if target == nil {
+		return fmt.Errorf("extract target must be a non-nil pointer, got `nil`, pass address of variable like &target")
 	}
-	value, err := param.ResolveValue(c)
-	if err != nil {
-		return err
+	typ := reflect.TypeOf(target)
+	switch typ.Kind() {
+	case reflect.Ptr:
+		if reflect.ValueOf(target).IsNil() {
+			return fmt.Errorf("extract target must be a non-nil pointer, got nil `%s`, pass address of variable like "+
+				"&target", typ)
+		}
+	case reflect.Struct:
+		if typ == reflect.TypeOf(reflect.Value{}) {
+			return fmt.Errorf("extract target must be a non-nil pointer, got `reflect.Value`, use its Interface() method")
+		}
+		return fmt.Errorf("extract target must be a non-nil pointer, got struct `%s`, pass address of variable like "+
+			"&target", typ)
+	case reflect.Func:
+		return fmt.Errorf("extract target must be a non-nil pointer, got func `%s`, use Invoke() to call function "+
+			"with dependencies", typ)
+	case reflect.Map, reflect.Slice, reflect.Chan:
+		return fmt.Errorf("extract target must be a non-nil pointer, got %s `%s`, pass address of variable like "+
+			"&target, the %s is replaced by extracted one", typ.Kind(), typ, typ.Kind())
+	case reflect.UnsafePointer:
+		return fmt.Errorf("extract target must be a non-nil pointer, got `unsafe.Pointer`, pass typed pointer like " +
+			"&target")
+	default:
+		return fmt.Errorf("extract target must be a non-nil pointer, got %s `%s`, pass address of variable like "+
+			"&target", typ.Kind(), typ)
+	}
+	if params.Type != nil {
+		return nil
+	}
+	switch typ.Elem().Kind() {
+	case reflect.Interface:
+		if typ.Elem() == reflect.TypeOf((*interface{})(nil)).Elem() {
+			return fmt.Errorf("extract target is `%s`, specify a concrete or interface type", typ)
+		}
+		return nil
+	case reflect.Ptr:
+		if params.Group != "" {
+			return nil
+		}
+	default:
+		// other kinds are explained by not found error, see kindHint()
+		return nil
+	}
+	var exists, elemExists bool
+	c.read(func() {
+		_, exists = parameter{name: params.Name, res: typ.Elem(), tags: params.Tags}.ResolveProvider(c)
+		_, elemExists = parameter{name: params.Name, res: typ.Elem().Elem(), tags: params.Tags}.ResolveProvider(c)
+	})
+	if !exists && elemExists {
+		return fmt.Errorf("extract target is `%s`, did you mean `%s`?", typ, typ.Elem())
 	}
-	targetValue := reflect.ValueOf(target).Elem()
-	targetValue.Set(value)
 	return nil
 }
 
@@ -119,37 +826,400 @@ func (c *Container) Invoke(fn interface{}, options ...InvokeOption) error {
 	for _, opt := range options {
 		opt.apply(&params)
 	}
-	if !c.compiled {
+	if err := c.dev.checkCompiling(); err != nil {
+		return err
+	}
+	if !c.isCompiled() {
 		return fmt.Errorf("container not compiled")
 	}
 	invoker, err := newInvoker(fn)
 	if err != nil {
 		return err
 	}
+	c.dev.enter(invoker.fn.Type.String())
 	return invoker.Invoke(c)
 }
 
+// Inherit shares singletons of parent container with compiled container. The singleton shared if the container has
+// definition with the same key that does not depend on replaced definitions. Cleanup of shared instances belongs to
+// the parent container.
+//
+//   derived := di.New()
+//   // provide parent definitions and replacements
+//   derived.Compile()
+//   derived.Inherit(parent)
+func (c *Container) Inherit(parent *Container) {
+	c.storage.Lock()
+	defer c.storage.Unlock()
+	parent.storage.RLock()
+	defer parent.storage.RUnlock()
+	if !c.compiled {
+		panicf("container not compiled")
+	}
+	affected := map[key]bool{}
+	var queue []key
+	for _, def := range c.definitions {
+		if def.replaced || def.isolated {
+			affected[def.key] = true
+			queue = append(queue, def.key)
+		}
+	}
+	for len(queue) > 0 {
+		k := queue[0]
+		queue = queue[1:]
+		for _, dependent := range c.graph.OutgoingEdges(k) {
+			if !affected[dependent.(key)] {
+				affected[dependent.(key)] = true
+				queue = append(queue, dependent.(key))
+			}
+		}
+	}
+	for _, def := range c.definitions {
+		if affected[def.key] {
+			continue
+		}
+		source := parent.definition(def.key)
+		if source == nil {
+			continue
+		}
+		if _, singleton := source.provider.(*singletonWrapper); !singleton {
+			continue
+		}
+		def.provider = &providerInherited{internalProvider: source.provider, owner: parent}
+		c.graph.Replace(def.key, def.provider)
+	}
+	c.buildIndex()
+}
+
+// Subset creates compiled container that contains only the roots and their transitive dependencies. Roots are
+// pointers to types like Extract() targets. The subset shares singletons of the container, cleanup of shared
+// instances belongs to the container.
+//
+//   subset, err := c.Subset(new(*Command))
+func (c *Container) Subset(roots ...interface{}) (*Container, error) {
+	if !c.isCompiled() {
+		return nil, fmt.Errorf("container not compiled")
+	}
+	c.storage.RLock()
+	defer c.storage.RUnlock()
+	closure, err := c.closure("subset root", roots)
+	if err != nil {
+		return nil, err
+	}
+	subset := New()
+	subset.maxDepth = c.maxDepth
+	subset.unexported = c.unexported
+	subset.deprecation = c.deprecation
+	subset.retryLog = c.retryLog
+	subset.strict = c.strict
+	subset.recoverMode = c.recoverMode
+	subset.convert = c.convert
+	subset.explicit = c.explicit
+	subset.fallbacks = c.fallbacks
+	for _, def := range c.definitions {
+		if _, retained := closure[def.key]; !retained || def.isolated {
+			continue
+		}
+		copied := *def
+		if singleton, ok := def.provider.(*singletonWrapper); ok {
+			copied.provider = &providerInherited{internalProvider: singleton, owner: c}
+		}
+		closure[def.key] = copied.provider
+		copied.seq = len(subset.definitions) + 1
+		subset.addDefinition(&copied)
+	}
+	for _, node := range c.graph.Nodes() {
+		if provider, retained := closure[node.Key.(key)]; retained && c.definition(node.Key.(key)) == nil {
+			subset.graph.Add(node.Key, provider)
+		}
+	}
+	for _, def := range subset.definitions {
+		subset.graph.Add(def.key, def.provider)
+	}
+	subset.Compile()
+	return subset, nil
+}
+
+// closure returns providers of roots and their transitive dependencies. Roots are pointers to types like Extract()
+// targets.
+func (c *Container) closure(kind string, roots []interface{}) (map[key]internalProvider, error) {
+	closure := map[key]internalProvider{}
+	var visit func(provider internalProvider)
+	visit = func(provider internalProvider) {
+		if _, visited := closure[provider.Key()]; visited {
+			return
+		}
+		closure[provider.Key()] = provider
+		for _, param := range provider.ParameterList() {
+			if dependency, exists := param.ResolveProvider(c); exists {
+				visit(dependency)
+			}
+		}
+	}
+	for _, root := range roots {
+		if root == nil || !reflection.IsPtr(root) {
+			return nil, fmt.Errorf("%s must be a pointer, got `%s`", kind, reflect.TypeOf(root))
+		}
+		param := parameter{res: reflect.TypeOf(root).Elem()}
+		provider, exists := param.ResolveProvider(c)
+		if !exists {
+			return nil, c.notFound(param)
+		}
+		visit(provider)
+	}
+	return closure, nil
+}
+
+// prune removes definitions that are not reachable from entry points. Container specific definitions are kept.
+func (c *Container) prune() {
+	closure, err := c.closure("entry point", c.entryPoints)
+	if err != nil {
+		panicf("Prune: %s", err)
+	}
+	definitions := c.definitions
+	c.definitions, c.byKey = nil, nil
+	for _, def := range definitions {
+		if _, reachable := closure[def.key]; reachable || def.isolated {
+			c.addDefinition(def)
+		}
+	}
+	for _, node := range c.graph.Nodes() {
+		if _, reachable := closure[node.Key.(key)]; !reachable && c.definition(node.Key.(key)) == nil {
+			c.graph.Remove(node.Key)
+		}
+	}
+}
+
+// Definitions returns snapshots of registered definitions in registration order.
+func (c *Container) Definitions() []DefinitionInfo {
+	c.storage.RLock()
+	defer c.storage.RUnlock()
+	infos := make([]DefinitionInfo, 0, len(c.definitions))
+	for _, def := range c.definitions {
+		infos = append(infos, def.Info())
+	}
+	return infos
+}
+
+// ImplementationsOf returns snapshots of definitions bound to the interface in the order of the interface slice
+// elements. ImplementationsOf does not create instances.
+//
+//   infos, err := c.ImplementationsOf(new(Exporter)) // prometheus, statsd, otlp
+func (c *Container) ImplementationsOf(iface interface{}) ([]DefinitionInfo, error) {
+	inspected, err := reflection.InspectInterfacePtr(iface)
+	if err != nil {
+		return nil, fmt.Errorf("ImplementationsOf() argument must be a pointer to interface, %s", err)
+	}
+	c.storage.RLock()
+	defer c.storage.RUnlock()
+	k := newProviderGroup(key{res: inspected.Type}).Key()
+	infos := []DefinitionInfo{}
+	if !c.graph.Exists(k) {
+		return infos, nil
+	}
+	for _, def := range c.graph.Get(k).Value.(*providerGroup).members {
+		infos = append(infos, def.Info())
+	}
+	return infos, nil
+}
+
+// Graph returns snapshot of the dependency graph. Unlike extraction of *Graph it does not create the graph
+// definition instance. Deprecated definitions are dashed, descriptions are shown under types.
+func (c *Container) Graph() *Graph {
+	c.storage.RLock()
+	defer c.storage.RUnlock()
+	return &Graph{graph: c.graph.DOTGraph(func(k graphkv.Key, node *dot.Node) {
+		def := c.definition(k.(key))
+		if def == nil {
+			return
+		}
+		label := k.(key).String()
+		if def.deprecated != "" {
+			label += " (deprecated)"
+			node.Attr("style", "filled,dashed")
+		}
+		if def.label != "" {
+			label += "\n" + def.label
+			node.Attr("tooltip", def.label)
+		}
+		node.Label(label)
+	})}
+}
+
+// Keys returns keys of registered definitions in registration order. Interfaces that definitions are bound to follow
+// the first bound definition and are marked as aliases.
+func (c *Container) Keys() []Key {
+	c.storage.RLock()
+	defer c.storage.RUnlock()
+	var keys []Key
+	aliases := map[Key]bool{}
+	for _, def := range c.definitions {
+		keys = append(keys, def.key.export())
+		for _, iface := range def.implements {
+			alias := Key{typ: iface, name: def.key.name, alias: true}
+			if aliases[alias] {
+				continue
+			}
+			aliases[alias] = true
+			keys = append(keys, alias)
+		}
+		for _, b := range def.bindings {
+			alias := Key{typ: b.iface, name: b.name, alias: true}
+			if aliases[alias] {
+				continue
+			}
+			aliases[alias] = true
+			keys = append(keys, alias)
+		}
+	}
+	return keys
+}
+
 // Cleanup runs destructors in order that was been created.
 func (c *Container) Cleanup() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	for _, cleanup := range c.cleanups {
 		cleanup()
 	}
+	c.cleaned = len(c.cleanups)
+}
+
+// describe represents key as string using definition information if it exists.
+func (c *Container) describe(k key) string {
+	if def := c.definition(k); def != nil {
+		return def.String()
+	}
+	return k.String()
+}
+
+// isCompiled checks that container is compiled.
+func (c *Container) isCompiled() bool {
+	c.storage.RLock()
+	defer c.storage.RUnlock()
+	return c.compiled
+}
+
+// read calls fn under the storage read lock.
+func (c *Container) read(fn func()) {
+	c.storage.RLock()
+	defer c.storage.RUnlock()
+	fn()
+}
+
+// mustNotCompiled causes panic with ErrContainerCompiled if container is compiled.
+func (c *Container) mustNotCompiled() {
+	if c.compiled {
+		panic(ErrContainerCompiled)
+	}
+}
+
+// cycleError creates dependency cycle error. Graph edges are directed from dependency to dependent, so the cycle is
+// reversed to start from dependents. The cycle starts from the first provided definition to be independent of the
+// graph traversal.
+func (c *Container) cycleError(cycle []graphkv.Key, component []graphkv.Key) ErrDependencyCycle {
+	err := ErrDependencyCycle{}
+	for i := len(cycle) - 1; i >= 0; i-- {
+		err.cycle = append(err.cycle, c.cycleNode(cycle[i].(key)))
+	}
+	start, seq := 0, 0
+	for i, node := range err.cycle {
+		if def := c.definition(node.k); def != nil && (seq == 0 || def.seq < seq) {
+			start, seq = i, def.seq
+		}
+	}
+	err.cycle = append(err.cycle[start:], err.cycle[:start]...)
+	for _, k := range component {
+		err.component = append(err.component, c.cycleNode(k.(key)))
+	}
+	return err
+}
+
+// cycleNode creates dependency cycle node with source location of the definition.
+func (c *Container) cycleNode(k key) cycleNode {
+	node := cycleNode{k: k, desc: c.describe(k)}
+	if def := c.definition(k); def != nil && def.location.File != "" {
+		node.location = def.location.String()
+	}
+	return node
+}
+
+// provideFailed creates provide error of definition.
+func (c *Container) provideFailed(k key, err error) ErrParameterProvideFailed {
+	var desc string
+	c.read(func() {
+		desc = c.describe(k)
+	})
+	return ErrParameterProvideFailed{k: k, desc: desc, err: err}
+}
+
+// addCleanup registers destructor of created instance.
+func (c *Container) addCleanup(cleanup func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cleanups = append(c.cleanups, cleanup)
 }
 
 // processProviderInterface represents instances as interfaces and groups.
-func (c *Container) processProviderInterface(provider internalProvider, as interface{}) {
+func (c *Container) processProviderInterface(def *definition, as interface{}) {
 	// create interface from provider
-	iface := newProviderInterface(provider, as)
+	iface := newProviderInterface(def, as)
 	key := iface.Key()
+	registerTypeNames(key.res)
 	if c.graph.Exists(key) {
-		stub := newProviderStub(key, "have several implementations")
-		c.graph.Replace(key, stub)
+		// if exists use existing interface
+		iface = c.graph.Get(key).Value.(*providerInterface)
+		if iface.replacement != nil && iface.replacement != def {
+			// replaced interface is not bound to other definitions
+			return
+		}
 	} else {
 		// add interface node
 		c.graph.Add(key, iface)
 	}
-	// create group
-	group := newProviderGroup(key)
+	iface.Add(def)
+	def.bind(key.res)
+	c.processProviderGroup(def, "", key.res)
+}
+
+// processProviderBinding binds definition to interface under name of the interface key. The definition is not added
+// into interface group, the group collects implementations bound under their own names.
+func (c *Container) processProviderBinding(def *definition, as interface{}, name string) {
+	iface := newProviderInterface(def, as)
+	iface.res.name = name
+	key := iface.Key()
+	registerTypeNames(key.res)
+	if c.graph.Exists(key) {
+		iface = c.graph.Get(key).Value.(*providerInterface)
+	} else {
+		c.graph.Add(key, iface)
+	}
+	iface.Add(def)
+	def.bindNamed(key.res, name)
+}
+
+// processProviderName adds alias of definition with additional name.
+func (c *Container) processProviderName(def *definition, name string) {
+	if name == def.key.name {
+		return
+	}
+	for _, existing := range def.names {
+		if existing == name {
+			return
+		}
+	}
+	alias := newProviderAlias(def, name)
+	if c.graph.Exists(alias.Key()) || c.graph.Exists(constructorKey(alias.Key())) {
+		panicf("The `%s` type already exists in container", constructorKey(alias.Key()))
+	}
+	c.graph.Add(alias.Key(), alias)
+	def.names = append(def.names, name)
+}
+
+// processProviderGroup adds definition into group of element type. Interface groups are unnamed, named groups are
+// collections of explicit members.
+func (c *Container) processProviderGroup(def *definition, name string, elem reflect.Type) {
+	group := newProviderGroup(key{name: name, res: elem})
 	groupKey := group.Key()
 	// check exists
 	if c.graph.Exists(groupKey) {
@@ -161,20 +1231,216 @@ func (c *Container) processProviderInterface(provider internalProvider, as inter
 		c.graph.Add(groupKey, group)
 	}
 	// add provider reference into group
-	providerKey := provider.Key()
-	group.Add(providerKey)
+	group.Add(def)
+}
+
+// embeddedInterface creates interface provider from provided interfaces that embed the requested one. For example,
+// io.Reader resolves as definition provided as io.ReadWriter. All definitions of such interfaces are implementations
+// of created interface, so the ambiguity rules are the same as for interfaces provided explicitly.
+func (c *Container) embeddedInterface(name string, typ reflect.Type) (*providerInterface, bool) {
+	if typ.Kind() != reflect.Interface {
+		return nil, false
+	}
+	var iface *providerInterface
+	for _, node := range c.graph.Nodes() {
+		candidate, ok := node.Value.(*providerInterface)
+		if !ok || candidate.res.name != name || !candidate.res.res.Implements(typ) {
+			continue
+		}
+		if iface == nil {
+			iface = &providerInterface{res: key{name: name, res: typ, typ: ptInterface}}
+		}
+		for _, def := range candidate.impls {
+			iface.Add(def)
+		}
+	}
+	return iface, iface != nil
+}
+
+// conversionProvider selects definitions of types that are convertible to parameter type, see SetAutoConvert().
+// Directional channel converts from bidirectional channel of the same element type without SetAutoConvert().
+func (c *Container) conversionProvider(p parameter) (internalProvider, bool) {
+	directional := p.res.Kind() == reflect.Chan && p.res.ChanDir() != reflect.BothDir
+	if !c.convert && !directional || p.res.Kind() == reflect.Interface {
+		return nil, false
+	}
+	var candidates definitionList
+	for _, def := range c.definitions {
+		if !def.isolated && !def.exclusive && def.key.name == p.name && def.tags.Contains(p.tags) &&
+			(c.convert || isBidirectional(def.key.res, p.res)) && isConvertible(def.key.res, p.res) {
+			candidates = append(candidates, def)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, false
+	}
+	candidates.Sort()
+	return &providerConversion{
+		res:        key{name: p.name, res: p.res, typ: ptConversion, tags: p.tags.String()},
+		candidates: candidates,
+	}, true
+}
+
+// factoryProvider synthesizes factory of parameter function type if the factory result type resolves.
+func (c *Container) factoryProvider(p parameter) (internalProvider, bool) {
+	factory, ok := newProviderFactory(c, p)
+	if !ok {
+		return nil, false
+	}
+	if _, exists := factory.target.ResolveProvider(c); !exists {
+		return nil, false
+	}
+	return factory, true
+}
+
+// notFound creates error of parameter that does not resolve.
+func (c *Container) notFound(p parameter) ErrParameterProviderNotFound {
+	err := ErrParameterProviderNotFound{param: p, hint: c.notFoundHint(p), fallbacks: len(c.fallbacks)}
+	for _, def := range c.definitions {
+		if def.isolated || def.key.name == p.name && def.key.res == p.res && def.tags.String() == p.tags.String() {
+			continue
+		}
+		if def.key.res == p.res || p.res.Kind() == reflect.Interface && def.key.res.Implements(p.res) {
+			err.candidates = append(err.candidates, def.key.export())
+		}
+	}
+	return err
+}
+
+// notFoundHint explains why parameter does not resolve: names the tag that eliminated the last candidates, lists types
+// of definitions with the parameter name or names of the interface implementations.
+func (c *Container) notFoundHint(p parameter) string {
+	var types, names, exclusive, unbound []string
+	var unnamed bool
+	selected := c.selectCandidates(p)
+	seen := map[string]bool{}
+	for _, def := range c.definitions {
+		if def.exclusive && def.key.res == p.res && def.key.name == p.name {
+			for _, iface := range def.implements {
+				exclusive = append(exclusive, iface.String())
+			}
+		}
+		if def.key.res != p.res && def.key.name == p.name && p.name != "" {
+			types = append(types, def.key.res.String())
+		}
+		if !def.isolated && def.key.name == p.name && p.res.Kind() == reflect.Interface && def.key.res.Implements(p.res) &&
+			!c.boundAs(def, p.res) && !def.boundNamed(p.res) {
+			unbound = append(unbound, def.key.res.String())
+		}
+		for _, b := range def.bindings {
+			if b.iface == p.res && b.name != p.name && !seen[b.name] {
+				seen[b.name] = true
+				names = append(names, fmt.Sprintf("`%s`", b.name))
+			}
+		}
+		if def.key.name != p.name && !seen[def.key.name] && (def.key.res == p.res || c.boundAs(def, p.res)) {
+			seen[def.key.name] = true
+			if def.key.name == "" {
+				unnamed = true
+				continue
+			}
+			names = append(names, fmt.Sprintf("`%s`", def.key.name))
+		}
+	}
+	switch {
+	case len(exclusive) != 0:
+		return fmt.Sprintf("definition provided exclusively as %s", strings.Join(exclusive, ", "))
+	case len(p.tags) != 0 && selected.eliminated != nil:
+		return selected.hint(p)
+	case len(types) != 0:
+		return fmt.Sprintf("definition with name `%s` provided as %s", p.name, strings.Join(types, ", "))
+	case len(names) != 0 && unnamed:
+		return fmt.Sprintf("available names: %s, the type is also provided without name", strings.Join(names, ", "))
+	case len(names) != 0:
+		return fmt.Sprintf("available names: %s", strings.Join(names, ", "))
+	case unnamed:
+		return "the type is provided without name"
+	case len(unbound) != 0:
+		return fmt.Sprintf("%s implements this interface but is not bound, add As(new(%s)) provide option",
+			strings.Join(unbound, ", "), p.res)
+	}
+	return kindHint(p.res)
+}
+
+// kindHint explains how to provide type of kind that the container could not create by itself.
+func kindHint(typ reflect.Type) string {
+	switch typ.Kind() {
+	case reflect.Map:
+		return "map is not provided, provide the map or extract a named group into it"
+	case reflect.Slice:
+		return "slice is not provided, provide the slice or extract a named group into it"
+	case reflect.Func:
+		if _, factory := newProviderFactory(nil, parameter{res: typ}); factory {
+			return ""
+		}
+		return "func is not provided, provide the function as value or use a factory like " +
+			"`func([context.Context]) (<type>, error)`"
+	case reflect.Chan:
+		return "channel is not provided, provide the channel as value"
+	case reflect.Struct:
+		if isEmbedParameter(typ) {
+			return ""
+		}
+		for i := 0; i < typ.NumField(); i++ {
+			if typ.Field(i).PkgPath == "" {
+				return ""
+			}
+		}
+		return "struct without exported fields is not provided, provide the struct or embed di.Parameter and " +
+			"export fields to fill them from the container"
+	}
+	return ""
+}
+
+// isExclusive checks that definition is reachable only through interfaces.
+func (c *Container) isExclusive(k key) bool {
+	def := c.definition(k)
+	return def != nil && def.exclusive
+}
+
+// boundAs checks that definition is bound to the interface.
+func (c *Container) boundAs(def *definition, typ reflect.Type) bool {
+	return c.boundWithName(def, def.key.name, typ)
+}
+
+// boundWithName checks that definition is bound to the interface under name of the interface key.
+func (c *Container) boundWithName(def *definition, name string, typ reflect.Type) bool {
+	if typ.Kind() != reflect.Interface || !def.key.res.Implements(typ) {
+		return false
+	}
+	k := key{name: name, res: typ, typ: ptInterface}
+	if !c.graph.Exists(k) {
+		return false
+	}
+	iface, ok := c.graph.Get(k).Value.(*providerInterface)
+	return ok && iface.impls.Get(def.key) != nil
 }
 
 // registerProviderParameters registers provider parameters in a dependency graph.
 func (c *Container) registerProviderParameters(p internalProvider) {
+	if embed, ok := p.(*providerEmbed); ok && embed.err != nil {
+		panicf("%s", embed.err)
+	}
 	for _, param := range p.ParameterList() {
 		provider, exists := param.ResolveProvider(c)
+		if group, ok := provider.(*providerMapGroup); ok && group.err != nil {
+			panicf("%s: %s", group.Key(), group.err)
+		}
+		if exists && !c.graph.Exists(provider.Key()) {
+			// interface resolved via embedding interface becomes part of graph
+			c.graph.Add(provider.Key(), provider)
+			c.registerProviderParameters(provider)
+		}
+		if exists && param.optional {
+			c.graph.OptionalEdge(provider.Key(), p.Key())
+			continue
+		}
 		if exists {
 			c.graph.Edge(provider.Key(), p.Key())
 			continue
 		}
 		if !exists && !param.optional {
-			panicf("%s: dependency %s not exists in container", p.Key(), param)
+			panicf("%s: dependency %s not exists in container", c.describe(p.Key()), param)
 		}
 	}
 }
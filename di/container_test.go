@@ -1,17 +1,29 @@
 package di_test
 
 import (
+	"bufio"
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
+	"os"
 	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/defval/inject/v2/di"
 	"github.com/defval/inject/v2/di/internal/ditest"
+	"github.com/defval/inject/v2/di/internal/ditest/config"
+	otherconfig "github.com/defval/inject/v2/di/internal/ditest/other/config"
 )
 
 func TestContainerCompileErrors(t *testing.T) {
@@ -19,7 +31,110 @@ func TestContainerCompileErrors(t *testing.T) {
 		c := NewTestContainer(t)
 		c.MustProvide(ditest.NewCycleFooBar)
 		c.MustProvide(ditest.NewBar)
-		c.MustCompileError("the graph cannot be cyclic")
+		c.MustCompileError("the graph cannot be cyclic: *ditest.Foo -> *ditest.Bar -> *ditest.Foo")
+	})
+
+	t.Run("provider that depends on itself cause panic", func(t *testing.T) {
+		type Cache struct{}
+		c := NewTestContainer(t)
+		defer func() {
+			require.Contains(t, fmt.Sprint(recover()), ": provider for *di_test.Cache depends on itself, provide the wrapped definition with WithName() and depend on it by name")
+		}()
+		c.Provide(func(cache *Cache) *Cache { return cache })
+	})
+
+	t.Run("struct that depends on itself cause panic", func(t *testing.T) {
+		type Node struct {
+			Next *Node `di:""`
+		}
+		c := NewTestContainer(t)
+		require.PanicsWithValue(t, "node.go:3: provider for *di_test.Node depends on itself, provide the wrapped definition with WithName() and depend on it by name", func() {
+			c.ProvideStruct(&Node{}, di.ProvideParams{Location: di.Location{File: "node.go", Line: 3}})
+		})
+	})
+
+	t.Run("provider that wraps named definition of its type", func(t *testing.T) {
+		type Cache struct{ inner *Cache }
+		type Parameters struct {
+			di.Parameter
+			Inner *Cache `di:"inner"`
+		}
+		c := NewTestContainer(t)
+		c.MustProvideWithName("inner", func() *Cache { return &Cache{} })
+		c.MustProvide(func(params Parameters) *Cache { return &Cache{inner: params.Inner} })
+		c.MustCompile()
+		var cache *Cache
+		c.MustExtract(&cache)
+		require.NotNil(t, cache.inner)
+	})
+
+	t.Run("dependency cycle through interface cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewQux)
+		c.MustProvide(ditest.NewCycleFooer, new(ditest.Fooer))
+		c.MustCompileError("the graph cannot be cyclic: *ditest.Qux -> ditest.Fooer -> *ditest.CycleFooer -> *ditest.Qux")
+	})
+
+	t.Run("dependency cycle through bind cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewQux)
+		c.MustProvide(ditest.NewCycleFooer)
+		c.Bind(new(ditest.Fooer), new(ditest.CycleFooer))
+		c.MustCompileError("the graph cannot be cyclic: *ditest.Qux -> ditest.Fooer -> *ditest.CycleFooer -> *ditest.Qux")
+	})
+
+	t.Run("dependency cycle through primary implementation cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewQux)
+		c.MustProvide(ditest.NewBar, new(ditest.Fooer))
+		c.Provide(ditest.NewCycleFooer, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}, IsPrimary: true})
+		c.MustCompileError("the graph cannot be cyclic: *ditest.Qux -> ditest.Fooer -> *ditest.CycleFooer -> *ditest.Qux")
+	})
+
+	t.Run("dependency cycle error contains shortest cycle", func(t *testing.T) {
+		type A struct{}
+		type B struct{}
+		type C struct{}
+		c := NewTestContainer(t)
+		c.MustProvide(func(*B) *A { return &A{} })
+		c.MustProvide(func(*C) *B { return &B{} })
+		c.MustProvide(func(*A, *B) *C { return &C{} })
+		var cycle di.ErrDependencyCycle
+		func() {
+			defer func() {
+				err, _ := recover().(error)
+				require.True(t, errors.As(err, &cycle))
+			}()
+			c.Compile()
+		}()
+		require.EqualError(t, cycle, "the graph cannot be cyclic: *di_test.B -> *di_test.C -> *di_test.B")
+		require.ElementsMatch(t, []string{"*di_test.A", "*di_test.B", "*di_test.C"}, cycle.Component())
+	})
+
+	t.Run("dependency cycle error renders cycle diagram", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewCycleFooBar)
+		c.MustProvide(ditest.NewBar)
+		var cycle di.ErrDependencyCycle
+		func() {
+			defer func() {
+				err, _ := recover().(error)
+				require.True(t, errors.As(err, &cycle))
+			}()
+			c.Compile()
+		}()
+		bar := "*ditest.Bar\n" + location(ditest.NewBar)
+		foo := "*ditest.Foo\n" + location(ditest.NewCycleFooBar)
+		require.Contains(t, cycle.DOT(), fmt.Sprintf(`n1[color="#46494C",fontcolor="white",fontname="COURIER",label=%q`, foo))
+		require.Contains(t, cycle.DOT(), fmt.Sprintf(`n2[color="#46494C",fontcolor="white",fontname="COURIER",label=%q`, bar))
+		require.Contains(t, cycle.DOT(), "n1->n2")
+		require.Contains(t, cycle.DOT(), "n2->n1")
+		require.Equal(t, fmt.Sprintf("graph TD\n"+
+			"    n0[\"*ditest.Foo<br/>%s\"]\n"+
+			"    n1[\"*ditest.Bar<br/>%s\"]\n"+
+			"    n1 --> n0\n"+
+			"    n0 --> n1\n", location(ditest.NewCycleFooBar), location(ditest.NewBar)), cycle.Mermaid())
 	})
 
 	t.Run("not existing dependency cause compile error", func(t *testing.T) {
@@ -59,19 +174,33 @@ func TestContainerProvideErrors(t *testing.T) {
 		c.MustProvideError(&ditest.Foo{}, "The constructor must be a function like `func([dep1, dep2, ...]) (<result>, [cleanup, error])`, got `*ditest.Foo`")
 	})
 
+	const like = "the constructor must be a function like `func([dep1, dep2, ...]) (<result>, [cleanup, error])`"
+
 	t.Run("provide constructor without result cause panic", func(t *testing.T) {
 		c := NewTestContainer(t)
-		c.MustProvideError(ditest.ConstructorWithoutResult, "The constructor must be a function like `func([dep1, dep2, ...]) (<result>, [cleanup, error])`, got `github.com/defval/inject/v2/di/internal/ditest.ConstructorWithoutResult`")
+		c.MustProvideError(ditest.ConstructorWithoutResult, location(ditest.ConstructorWithoutResult)+
+			": invalid constructor `func()`: constructor has no result, "+like)
 	})
 
 	t.Run("provide constructor with many results cause panic", func(t *testing.T) {
 		c := NewTestContainer(t)
-		c.MustProvideError(ditest.ConstructorWithManyResults, "The constructor must be a function like `func([dep1, dep2, ...]) (<result>, [cleanup, error])`, got `github.com/defval/inject/v2/di/internal/ditest.ConstructorWithManyResults`")
+		c.MustProvideError(ditest.ConstructorWithManyResults, location(ditest.ConstructorWithManyResults)+
+			": invalid constructor `func() (*ditest.Foo, *ditest.Bar, error)`: second result must be cleanup func(), "+like)
 	})
 
 	t.Run("provide constructor with incorrect result error argument", func(t *testing.T) {
 		c := NewTestContainer(t)
-		c.MustProvideError(ditest.ConstructorWithIncorrectResultError, "The constructor must be a function like `func([dep1, dep2, ...]) (<result>, [cleanup, error])`, got `github.com/defval/inject/v2/di/internal/ditest.ConstructorWithIncorrectResultError`")
+		c.MustProvideError(ditest.ConstructorWithIncorrectResultError, location(ditest.ConstructorWithIncorrectResultError)+
+			": invalid constructor `func() (*ditest.Foo, *ditest.Bar)`: second result must be error or cleanup func(), "+like)
+	})
+
+	t.Run("invalid constructor signature renders variadic parameters and generic types", func(t *testing.T) {
+		c := NewTestContainer(t)
+		ctor := func(foo *ditest.Foo, names ...string) (ditest.Box[*ditest.Foo], string) {
+			return ditest.Box[*ditest.Foo]{}, ""
+		}
+		c.MustProvideError(ctor, location(ctor)+
+			": invalid constructor `func(*ditest.Foo, ...string) (ditest.Box[*ditest.Foo], string)`: second result must be error or cleanup func(), "+like)
 	})
 
 	t.Run("provide duplicate", func(t *testing.T) {
@@ -80,16 +209,60 @@ func TestContainerProvideErrors(t *testing.T) {
 		c.MustProvideError(ditest.NewFoo, "The `*ditest.Foo` type already exists in container")
 	})
 
+	t.Run("replace not existing type cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		require.PanicsWithValue(t, "The `*ditest.Foo` type not exists in container, nothing to replace", func() {
+			c.Provide(ditest.NewFoo, di.ProvideParams{IsReplacement: true})
+		})
+	})
+
+	t.Run("replacement of other type replaces interface binding", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewBar, new(ditest.Fooer))
+		c.MustProvide(ditest.NewQux)
+		c.Provide(func() *ditest.CycleFooer { return &ditest.CycleFooer{} },
+			di.ProvideParams{IsReplacement: true, Interfaces: []interface{}{new(ditest.Fooer)}})
+		c.MustCompile()
+
+		var qux *ditest.Qux
+		c.MustExtract(&qux)
+		require.IsType(t, &ditest.CycleFooer{}, qux.Fooer())
+		var fooers []ditest.Fooer
+		c.MustExtract(&fooers)
+		require.Len(t, fooers, 1)
+		require.Empty(t, c.Definitions()[1].Implements)
+	})
+
 	t.Run("provide as not implemented interface cause error", func(t *testing.T) {
 		c := NewTestContainer(t)
 		c.MustProvide(ditest.NewFoo)
 		c.MustProvideError(ditest.NewBar, "*ditest.Bar not implement ditest.Barer", new(ditest.Barer))
 	})
 
-	t.Run("provide as not interface cause error", func(t *testing.T) {
+	t.Run("provide as pointer to struct cause error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvideError(ditest.NewBar, location(ditest.NewBar)+": *ditest.Bar: As() argument must be a pointer to interface, got pointer to struct *ditest.Foo", new(ditest.Foo))
+	})
+
+	t.Run("provide as not pointer cause error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvideError(ditest.NewBar, location(ditest.NewBar)+": *ditest.Bar: As() argument must be a pointer to interface, got struct ditest.Foo", ditest.Foo{})
+	})
+
+	t.Run("provide as nil interface cause error with hint", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		var fooer ditest.Fooer
+		c.MustProvideError(ditest.NewBar, location(ditest.NewBar)+": *ditest.Bar: As() argument must be a pointer to interface, got nil, pass a pointer to interface like new(Interface) instead of interface value", fooer)
+	})
+
+	t.Run("provide as nil pointer to interface cause error", func(t *testing.T) {
 		c := NewTestContainer(t)
 		c.MustProvide(ditest.NewFoo)
-		c.MustProvideError(ditest.NewBar, "*ditest.Foo: not a pointer to interface", new(ditest.Foo))
+		c.MustProvideError(ditest.NewBar, location(ditest.NewBar)+": *ditest.Bar: As() argument must be a pointer to interface, got nil *ditest.Fooer, use new(ditest.Fooer)", (*ditest.Fooer)(nil))
 	})
 }
 
@@ -106,21 +279,65 @@ func TestContainerExtractErrors(t *testing.T) {
 		c := NewTestContainer(t)
 		c.MustProvide(ditest.NewFoo)
 		c.MustCompile()
-		c.MustExtractError("string", "extract target must be a pointer, got `string`")
+		c.MustExtractError("string", "extract target must be a non-nil pointer, got string `string`, pass address of variable like &target")
 	})
 
 	t.Run("extract into struct cause error", func(t *testing.T) {
 		c := NewTestContainer(t)
 		c.MustProvide(ditest.NewFoo)
 		c.MustCompile()
-		c.MustExtractError(struct{}{}, "extract target must be a pointer, got `struct {}`")
+		c.MustExtractError(struct{}{}, "extract target must be a non-nil pointer, got struct `struct {}`, pass address of variable like &target")
 	})
 
 	t.Run("extract into nil cause error", func(t *testing.T) {
 		c := NewTestContainer(t)
 		c.MustProvide(ditest.NewFoo)
 		c.MustCompile()
-		c.MustExtractError(nil, "extract target must be a pointer, got `nil`")
+		c.MustExtractError(nil, "extract target must be a non-nil pointer, got `nil`, pass address of variable like &target")
+	})
+
+	t.Run("extract into nil pointer cause error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustCompile()
+		c.MustExtractError((**ditest.Foo)(nil), "extract target must be a non-nil pointer, got nil `**ditest.Foo`, pass address of variable like &target")
+	})
+
+	t.Run("extract into reflect value cause error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustCompile()
+		c.MustExtractError(reflect.Value{}, "extract target must be a non-nil pointer, got `reflect.Value`, use its Interface() method")
+	})
+
+	t.Run("extract into empty interface cause error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustCompile()
+		var extracted interface{}
+		c.MustExtractError(&extracted, "extract target is `*interface {}`, specify a concrete or interface type")
+	})
+
+	t.Run("extract runtime type into empty interface", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustCompile()
+		var extracted interface{}
+		require.NoError(t, c.Extract(&extracted, di.ExtractParams{Type: reflect.TypeOf(&ditest.Foo{})}))
+		require.IsType(t, &ditest.Foo{}, extracted)
+		var bar *ditest.Bar
+		require.EqualError(t, c.Extract(&bar, di.ExtractParams{Type: reflect.TypeOf(&ditest.Foo{})}),
+			"extract target `**ditest.Bar` could not hold `*ditest.Foo`")
+	})
+
+	t.Run("extract into double pointer cause error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustCompile()
+		var extracted *ditest.Foo
+		target := &extracted
+		c.MustExtractError(&target, "extract target is `***ditest.Foo`, did you mean `**ditest.Foo`?")
+		c.MustExtract(target)
 	})
 
 	t.Run("container does not find type because its named", func(t *testing.T) {
@@ -130,7 +347,56 @@ func TestContainerExtractErrors(t *testing.T) {
 		c.MustCompile()
 
 		var extracted *ditest.Foo
-		c.MustExtractError(&extracted, "*ditest.Foo: not exists in container")
+		c.MustExtractError(&extracted, "*ditest.Foo: not exists in container, available names: `foo`")
+	})
+
+	t.Run("not found error lists names of type and interface implementations", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvideWithName("primary", ditest.NewFoo)
+		c.MustProvideWithName("replica", ditest.NewBar, new(ditest.Fooer))
+		c.MustCompile()
+
+		var foo *ditest.Foo
+		c.MustExtractWithNameError("replca", &foo, "*ditest.Foo[replca]: not exists in container, available names: "+
+			"`primary`, the type is also provided without name")
+		var fooer ditest.Fooer
+		c.MustExtractWithNameError("replca", &fooer, "ditest.Fooer[replca]: not exists in container, available names: `replica`")
+		var bar *ditest.Bar
+		c.MustExtractWithNameError("replca", &bar, "*ditest.Bar[replca]: not exists in container, available names: `replica`")
+	})
+
+	t.Run("not found error reports definition without name", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustCompile()
+
+		var foo *ditest.Foo
+		c.MustExtractWithNameError("primary", &foo, "*ditest.Foo[primary]: not exists in container, the type is provided without name")
+	})
+
+	t.Run("not found error contains requested type and candidates", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvideWithName("foo", ditest.NewFoo)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewBar)
+		c.MustCompile()
+
+		var extracted *ditest.Foo
+		err := c.Extract(&extracted, di.ExtractParams{Name: "bar"})
+		var notFound di.ErrParameterProviderNotFound
+		require.True(t, errors.As(err, &notFound))
+		require.Equal(t, reflect.TypeOf(extracted), notFound.Type())
+		require.Equal(t, "bar", notFound.Name())
+		var candidates []string
+		for _, candidate := range notFound.Candidates() {
+			candidates = append(candidates, candidate.String())
+		}
+		require.Equal(t, []string{"*ditest.Foo[foo]", "*ditest.Foo"}, candidates)
+
+		var fooer ditest.Fooer
+		require.True(t, errors.As(c.Extract(&fooer), &notFound))
+		require.Equal(t, "*ditest.Bar", notFound.Candidates()[0].String())
 	})
 
 	t.Run("extract returns error because dependency constructing failed", func(t *testing.T) {
@@ -150,7 +416,7 @@ func TestContainerExtractErrors(t *testing.T) {
 		c.MustCompile()
 
 		var extracted ditest.Fooer
-		c.MustExtractError(&extracted, "ditest.Fooer: have several implementations")
+		c.MustExtractError(&extracted, "ditest.Fooer: have several implementations: *ditest.Bar, *ditest.Baz")
 	})
 }
 
@@ -195,158 +461,3669 @@ func TestContainerProvide(t *testing.T) {
 
 }
 
-func TestContainerExtract(t *testing.T) {
-	t.Run("container extract correct pointer", func(t *testing.T) {
+func TestContainerExtract(t *testing.T) {
+	t.Run("container extract correct pointer", func(t *testing.T) {
+		c := NewTestContainer(t)
+		foo := &ditest.Foo{}
+		c.MustProvide(ditest.CreateFooConstructor(foo))
+		c.MustCompile()
+
+		var extracted *ditest.Foo
+		c.MustExtractPtr(foo, &extracted)
+	})
+
+	t.Run("container extract same pointer on each extraction", func(t *testing.T) {
+		c := NewTestContainer(t)
+		foo := &ditest.Foo{}
+		c.MustProvide(ditest.CreateFooConstructor(foo))
+		c.MustCompile()
+
+		var extracted1 *ditest.Foo
+		c.MustExtractPtr(foo, &extracted1)
+
+		var extracted2 *ditest.Foo
+		c.MustExtractPtr(foo, &extracted2)
+	})
+
+	t.Run("container extract instance if error is nil", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.CreateFooConstructorWithError(nil))
+		c.MustCompile()
+
+		var extracted *ditest.Foo
+		c.MustExtract(&extracted)
+	})
+
+	t.Run("container extract instance if cleanup and error is nil", func(t *testing.T) {
+		c := NewTestContainer(t)
+
+		c.MustProvide(ditest.CreateFooConstructorWithCleanupAndError(nil, nil))
+		c.MustCompile()
+
+		var extracted *ditest.Foo
+		c.MustExtract(&extracted)
+	})
+
+	t.Run("container extract correct named pointer", func(t *testing.T) {
+		c := NewTestContainer(t)
+		foo := &ditest.Foo{}
+		c.MustProvideWithName("foo", ditest.CreateFooConstructor(foo))
+		c.MustCompile()
+
+		var extracted *ditest.Foo
+		c.MustExtractWithName("foo", &extracted)
+	})
+
+	t.Run("container extract correct interface implementation", func(t *testing.T) {
+		c := NewTestContainer(t)
+		bar := &ditest.Bar{}
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.CreateBarConstructor(bar), new(ditest.Fooer))
+		c.MustCompile()
+
+		var extracted ditest.Fooer
+		c.MustExtractPtr(bar, &extracted)
+	})
+
+	t.Run("container creates group from interface and extract it", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewBar, new(ditest.Fooer))
+		c.MustProvide(ditest.NewBaz, new(ditest.Fooer))
+		c.MustCompile()
+
+		var group []ditest.Fooer
+		c.MustExtract(&group)
+		require.Len(t, group, 2)
+	})
+
+	t.Run("container extract new instance of prototype by each extraction", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvidePrototype(ditest.NewBar)
+		c.MustCompile()
+
+		var extracted1 *ditest.Bar
+		c.MustExtract(&extracted1)
+		var extracted2 *ditest.Bar
+		c.MustExtract(&extracted2)
+
+		c.MustNotEqualPointer(extracted1, extracted2)
+	})
+
+	t.Run("container does not resolve dependencies of created singleton", func(t *testing.T) {
+		c := NewTestContainer(t)
+		var calls int
+		c.MustProvidePrototype(func() *ditest.Foo {
+			calls++
+			return ditest.NewFoo()
+		})
+		c.MustProvide(ditest.NewBar)
+		c.MustCompile()
+
+		var extracted1 *ditest.Bar
+		c.MustExtract(&extracted1)
+		var extracted2 *ditest.Bar
+		c.MustExtract(&extracted2)
+
+		c.MustEqualPointer(extracted1, extracted2)
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("container resolve interactor", func(t *testing.T) {
+		c := NewTestContainer(t)
+		foo := ditest.NewFoo()
+		c.MustProvide(ditest.CreateFooConstructor(foo))
+		c.MustCompile()
+		var interactor di.Interactor
+		c.MustExtract(&interactor)
+		var extractedFoo *ditest.Foo
+		require.NoError(t, interactor.Extract(&extractedFoo))
+		c.MustEqualPointer(foo, extractedFoo)
+	})
+}
+
+func TestContainerResolve(t *testing.T) {
+	t.Run("container resolve correct argument", func(t *testing.T) {
+		c := NewTestContainer(t)
+		foo := &ditest.Foo{}
+		c.MustProvide(ditest.CreateFooConstructor(foo))
+		c.MustProvide(ditest.NewBar)
+		c.MustCompile()
+
+		var bar *ditest.Bar
+		c.MustExtract(&bar)
+		c.MustEqualPointer(foo, bar.Foo())
+	})
+
+	t.Run("container resolve correct interface implementation", func(t *testing.T) {
+		c := NewTestContainer(t)
+
+		foo := ditest.NewFoo()
+		bar := ditest.NewBar(foo)
+
+		c.MustProvide(ditest.CreateFooConstructor(foo))
+		c.MustProvide(ditest.CreateBarConstructor(bar), new(ditest.Fooer))
+		c.MustProvide(ditest.NewQux)
+		c.MustCompile()
+
+		var qux *ditest.Qux
+		c.MustExtract(&qux)
+		c.MustEqualPointer(bar, qux.Fooer())
+	})
+
+	t.Run("container resolve correct group", func(t *testing.T) {
+		c := NewTestContainer(t)
+
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewBar, new(ditest.Fooer))
+		c.MustProvide(ditest.NewBaz, new(ditest.Fooer))
+		c.MustProvide(ditest.NewFooerGroup)
+		c.MustCompile()
+
+		var bar *ditest.Bar
+		c.MustExtract(&bar)
+
+		var baz *ditest.Baz
+		c.MustExtract(&baz)
+
+		var group *ditest.FooerGroup
+		c.MustExtract(&group)
+		require.Len(t, group.Fooers(), 2)
+		c.MustEqualPointer(bar, group.Fooers()[0])
+		c.MustEqualPointer(baz, group.Fooers()[1])
+	})
+}
+
+func TestContainerReplace(t *testing.T) {
+	t.Run("replacement resolves instead of replaced definition", func(t *testing.T) {
+		c := NewTestContainer(t)
+		foo := &ditest.Foo{}
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewBar)
+		c.Provide(ditest.CreateFooConstructor(foo), di.ProvideParams{IsReplacement: true})
+		c.MustCompile()
+
+		var bar *ditest.Bar
+		c.MustExtract(&bar)
+		c.MustEqualPointer(foo, bar.Foo())
+	})
+
+	t.Run("replacement keeps group position", func(t *testing.T) {
+		c := NewTestContainer(t)
+		bar := &ditest.Bar{}
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewBar, new(ditest.Fooer))
+		c.MustProvide(ditest.NewBaz, new(ditest.Fooer))
+		c.Provide(ditest.CreateBarConstructor(bar), di.ProvideParams{
+			Interfaces:    []interface{}{new(ditest.Fooer)},
+			IsReplacement: true,
+		})
+		c.MustCompile()
+
+		var group []ditest.Fooer
+		c.MustExtract(&group)
+		require.Len(t, group, 2)
+		c.MustEqualPointer(bar, group[0])
+	})
+}
+
+func TestContainerResolveEmbeddedInterfaces(t *testing.T) {
+	t.Run("extract interface embedded into provided interface", func(t *testing.T) {
+		c := NewTestContainer(t)
+		buf := &bytes.Buffer{}
+		c.MustProvide(func() *bytes.Buffer { return buf }, new(io.ReadWriter))
+		c.MustCompile()
+
+		var reader io.Reader
+		c.MustExtractPtr(buf, &reader)
+		var writer io.Writer
+		c.MustExtractPtr(buf, &writer)
+	})
+
+	t.Run("resolve embedded interface parameter", func(t *testing.T) {
+		c := NewTestContainer(t)
+		buf := &bytes.Buffer{}
+		c.MustProvide(func() *bytes.Buffer { return buf }, new(io.ReadWriter))
+		c.MustProvide(func(reader io.Reader) *bufio.Reader { return bufio.NewReader(reader) })
+		c.MustCompile()
+
+		var reader *bufio.Reader
+		c.MustExtract(&reader)
+	})
+
+	t.Run("explicitly provided interface has priority", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(func() *bytes.Buffer { return &bytes.Buffer{} }, new(io.ReadWriter))
+		reader := &strings.Reader{}
+		c.MustProvide(func() *strings.Reader { return reader }, new(io.Reader))
+		c.MustCompile()
+
+		var extracted io.Reader
+		c.MustExtractPtr(reader, &extracted)
+	})
+
+	t.Run("several embedding interfaces cause ambiguity error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(func() *bytes.Buffer { return &bytes.Buffer{} }, new(io.ReadWriter))
+		c.MustProvide(func() *os.File { return os.Stdin }, new(io.ReadCloser))
+		c.MustCompile()
+
+		var reader io.Reader
+		c.MustExtractError(&reader, "io.Reader: have several implementations: *bytes.Buffer, *os.File")
+	})
+
+	t.Run("primary marker resolves embedding ambiguity", func(t *testing.T) {
+		c := NewTestContainer(t)
+		buf := &bytes.Buffer{}
+		c.Provide(func() *bytes.Buffer { return buf }, di.ProvideParams{
+			Interfaces: []interface{}{new(io.ReadWriter)},
+			IsPrimary:  true,
+		})
+		c.MustProvide(func() *os.File { return os.Stdin }, new(io.ReadCloser))
+		c.MustCompile()
+
+		var reader io.Reader
+		c.MustExtractPtr(buf, &reader)
+	})
+}
+
+func TestContainerAnonymousStructs(t *testing.T) {
+	type bundle = struct {
+		Foo *ditest.Foo
+	}
+	newBundle := func(foo *ditest.Foo) bundle { return bundle{Foo: foo} }
+	newBundleError := func() (*bundle, error) { return nil, errors.New("internal error") }
+
+	t.Run("anonymous struct without name cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvideError(newBundle, location(newBundle)+": anonymous struct result requires a name, use WithName() provide option")
+	})
+
+	t.Run("named anonymous struct resolves", func(t *testing.T) {
+		c := NewTestContainer(t)
+		foo := &ditest.Foo{}
+		c.MustProvide(ditest.CreateFooConstructor(foo))
+		c.MustProvideWithName("bundle", newBundle)
+		c.MustCompile()
+
+		var extracted bundle
+		c.MustExtractWithName("bundle", &extracted)
+		c.MustEqualPointer(foo, extracted.Foo)
+	})
+
+	t.Run("anonymous struct rendered with location in errors", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvideWithName("bundle", newBundleError)
+		c.MustCompile()
+
+		var extracted *bundle
+		c.MustExtractWithNameError("bundle", &extracted, "pointer to anonymous struct provided at "+location(newBundleError)+" (name=bundle): internal error")
+	})
+
+	t.Run("anonymous struct with missing dependency", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvideWithName("bundle", newBundle)
+		c.MustCompileError("anonymous struct provided at " + location(newBundle) + " (name=bundle): dependency *ditest.Foo not exists in container")
+	})
+}
+
+func TestContainerConstructorError(t *testing.T) {
+	t.Run("singleton caches error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		var calls int
+		c.MustProvide(func() (*ditest.Foo, error) {
+			calls++
+			return nil, errors.New("database unavailable")
+		})
+		c.MustCompile()
+
+		var foo *ditest.Foo
+		c.MustExtractError(&foo, "*ditest.Foo: database unavailable")
+		c.MustExtractError(&foo, "*ditest.Foo: database unavailable")
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("dependent does not call failed dependency again", func(t *testing.T) {
+		c := NewTestContainer(t)
+		var calls int
+		c.MustProvide(func() (*ditest.Foo, error) {
+			calls++
+			return nil, errors.New("database unavailable")
+		})
+		c.MustProvide(ditest.NewBar)
+		c.MustCompile()
+
+		var foo *ditest.Foo
+		c.MustExtractError(&foo, "*ditest.Foo: database unavailable")
+		var bar *ditest.Bar
+		c.MustExtractError(&bar, "*ditest.Foo: database unavailable")
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("retry on error calls constructor again", func(t *testing.T) {
+		c := NewTestContainer(t)
+		var calls int
+		c.Provide(func() (*ditest.Foo, error) {
+			calls++
+			if calls == 1 {
+				return nil, errors.New("database unavailable")
+			}
+			return &ditest.Foo{}, nil
+		}, di.ProvideParams{IsRetryOnError: true})
+		c.MustCompile()
+
+		var foo *ditest.Foo
+		c.MustExtractError(&foo, "*ditest.Foo: database unavailable")
+		c.MustExtract(&foo)
+		var extracted *ditest.Foo
+		c.MustExtractPtr(foo, &extracted)
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("concurrent retry on error creates the only instance", func(t *testing.T) {
+		c := NewTestContainer(t)
+		var calls int
+		c.Provide(func() (*ditest.Foo, error) {
+			calls++
+			if calls%2 == 1 {
+				return nil, errors.New("database unavailable")
+			}
+			return &ditest.Foo{}, nil
+		}, di.ProvideParams{IsRetryOnError: true})
+		c.MustCompile()
+
+		var wg sync.WaitGroup
+		results := make([]*ditest.Foo, 20)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				_ = c.Extract(&results[i])
+			}(i)
+		}
+		wg.Wait()
+		var foo *ditest.Foo
+		c.MustExtract(&foo)
+		for _, result := range results {
+			if result != nil {
+				c.MustEqualPointer(foo, result)
+			}
+		}
+		require.Equal(t, 2, calls)
+	})
+}
+
+func TestContainerRetry(t *testing.T) {
+	t.Run("constructor called until success", func(t *testing.T) {
+		c := NewTestContainer(t)
+		var calls int
+		c.Provide(func() (*ditest.Foo, error) {
+			calls++
+			if calls < 3 {
+				return nil, errors.New("sidecar not ready")
+			}
+			return &ditest.Foo{}, nil
+		}, di.ProvideParams{RetryAttempts: 3, RetryBackoff: time.Millisecond})
+		c.MustCompile()
+
+		var foo *ditest.Foo
+		c.MustExtract(&foo)
+		require.Equal(t, 3, calls)
+	})
+
+	t.Run("error wraps last attempt error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		var calls int
+		errNotReady := errors.New("sidecar not ready")
+		c.Provide(func() (*ditest.Foo, error) {
+			calls++
+			return nil, errNotReady
+		}, di.ProvideParams{RetryAttempts: 3, RetryBackoff: time.Millisecond})
+		c.MustCompile()
+
+		var foo *ditest.Foo
+		err := c.Extract(&foo)
+		require.EqualError(t, err, "*ditest.Foo: failed after 3 attempts: sidecar not ready")
+		require.True(t, errors.Is(err, errNotReady))
+		require.Equal(t, 3, calls)
+	})
+
+	t.Run("exponential backoff doubles delay", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.Provide(func() (*ditest.Foo, error) {
+			return nil, errors.New("sidecar not ready")
+		}, di.ProvideParams{RetryAttempts: 3, RetryBackoff: 10 * time.Millisecond, IsExponentialBackoff: true})
+		c.MustCompile()
+
+		start := time.Now()
+		var foo *ditest.Foo
+		require.Error(t, c.Extract(&foo))
+		require.True(t, time.Since(start) >= 30*time.Millisecond)
+	})
+
+	t.Run("failed attempts are written to retry log", func(t *testing.T) {
+		c := NewTestContainer(t)
+		var b strings.Builder
+		c.SetRetryLog(&b)
+		var calls int
+		c.Provide(func() (*ditest.Foo, error) {
+			calls++
+			if calls < 3 {
+				return nil, errors.New("sidecar not ready")
+			}
+			return &ditest.Foo{}, nil
+		}, di.ProvideParams{RetryAttempts: 3, RetryBackoff: time.Millisecond})
+		c.MustCompile()
+
+		var foo *ditest.Foo
+		c.MustExtract(&foo)
+		require.Equal(t, "*ditest.Foo: attempt 1 of 3 failed: sidecar not ready\n"+
+			"*ditest.Foo: attempt 2 of 3 failed: sidecar not ready\n", b.String())
+	})
+
+	t.Run("canceled build context aborts backoff", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		c := NewTestContainer(t)
+		c.SetRetryLog(nil)
+		var calls int
+		c.Provide(func() (*ditest.Foo, error) {
+			calls++
+			cancel()
+			return nil, errors.New("sidecar not ready")
+		}, di.ProvideParams{RetryAttempts: 3, RetryBackoff: time.Hour})
+		c.MustCompile()
+
+		err := c.BuildContext(ctx)
+		require.EqualError(t, err, "*ditest.Foo: retry canceled after 1 of 3 attempts: context canceled")
+		require.True(t, errors.Is(err, context.Canceled))
+		require.Equal(t, 1, calls)
+	})
+
+	t.Run("not positive attempts cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		require.PanicsWithValue(t, "*ditest.Foo: retry attempts must be positive, got -1", func() {
+			c.Provide(ditest.NewFoo, di.ProvideParams{RetryAttempts: -1})
+		})
+	})
+}
+
+func TestContainerSubset(t *testing.T) {
+	t.Run("subset contains dependency closure of roots", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewBar, new(ditest.Fooer))
+		c.MustProvide(ditest.NewBaz)
+		c.MustProvide(ditest.NewQux)
+		c.MustCompile()
+
+		subset, err := c.Subset(new(*ditest.Qux))
+		require.NoError(t, err)
+		var qux *ditest.Qux
+		require.NoError(t, subset.Extract(&qux))
+		var baz *ditest.Baz
+		require.EqualError(t, subset.Extract(&baz), "*ditest.Baz: not exists in container")
+
+		var types []string
+		for _, def := range subset.Definitions() {
+			types = append(types, def.Type.String())
+		}
+		require.Equal(t, []string{"*ditest.Foo", "*ditest.Bar", "*ditest.Qux", "*di.Graph", "di.Interactor", "di.Resolver"}, types)
+
+		var graph *di.Graph
+		require.NoError(t, subset.Extract(&graph))
+		require.Contains(t, graph.String(), "*ditest.Qux")
+		require.NotContains(t, graph.String(), "*ditest.Baz")
+	})
+
+	t.Run("subset shares singletons", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewBar)
+		c.MustCompile()
+
+		var foo *ditest.Foo
+		c.MustExtract(&foo)
+		subset, err := c.Subset(new(*ditest.Bar))
+		require.NoError(t, err)
+		var bar *ditest.Bar
+		require.NoError(t, subset.Extract(&bar))
+		c.MustEqualPointer(foo, bar.Foo())
+		var extracted *ditest.Bar
+		c.MustExtractPtr(bar, &extracted)
+	})
+
+	t.Run("not existing root cause error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustCompile()
+		_, err := c.Subset(new(*ditest.Foo))
+		require.EqualError(t, err, "*ditest.Foo: not exists in container")
+	})
+
+	t.Run("not pointer root cause error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustCompile()
+		_, err := c.Subset(ditest.Foo{})
+		require.EqualError(t, err, "subset root must be a pointer, got `ditest.Foo`")
+	})
+}
+
+func TestContainerPrune(t *testing.T) {
+	t.Run("unreachable definitions removed", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewBar)
+		c.MustProvide(ditest.NewBaz)
+		c.MustProvide(ditest.NewQux) // ditest.Fooer not exists
+		c.Prune(new(*ditest.Bar))
+		c.MustCompile()
+
+		var bar *ditest.Bar
+		c.MustExtract(&bar)
+		var baz *ditest.Baz
+		c.MustExtractError(&baz, "*ditest.Baz: not exists in container")
+		var types []string
+		for _, def := range c.Definitions() {
+			types = append(types, def.Type.String())
+		}
+		require.Equal(t, []string{"*ditest.Foo", "*ditest.Bar", "*di.Graph", "di.Interactor", "di.Resolver"}, types)
+	})
+
+	t.Run("unreachable cycle is not checked", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewCycleFooBar)
+		c.MustProvide(ditest.NewBar)
+		c.ProvideValue(&http.Server{})
+		c.Prune(new(*http.Server))
+		c.MustCompile()
+
+		var server *http.Server
+		c.MustExtract(&server)
+	})
+
+	t.Run("not existing entry point cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.Prune(new(*ditest.Foo))
+		c.MustCompileError("Prune: *ditest.Foo: not exists in container")
+	})
+}
+
+func TestContainerCompiled(t *testing.T) {
+	t.Run("provide after compile cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustCompile()
+		require.PanicsWithValue(t, di.ErrContainerCompiled, func() {
+			c.Provide(ditest.NewFoo)
+		})
+	})
+
+	t.Run("provide value after compile cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustCompile()
+		require.PanicsWithValue(t, di.ErrContainerCompiled, func() {
+			c.ProvideValue(&ditest.Foo{})
+		})
+	})
+
+	t.Run("bind after compile cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewBar)
+		c.MustCompile()
+		require.PanicsWithValue(t, di.ErrContainerCompiled, func() {
+			c.Bind(new(ditest.Fooer), new(ditest.Bar))
+		})
+	})
+
+	t.Run("repeated compile does nothing", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustCompile()
+		var foo *ditest.Foo
+		c.MustExtract(&foo)
+		c.MustCompile()
+		c.MustExtractPtr(foo, &foo)
+		require.Len(t, c.Definitions(), 4)
+	})
+}
+
+func TestContainerConcurrentAccess(t *testing.T) {
+	c := NewTestContainer(t)
+	c.MustProvide(ditest.NewFoo)
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(3)
+		go func(i int) {
+			defer wg.Done()
+			c.ProvideValue(i, di.ProvideParams{Name: fmt.Sprintf("value-%d", i)})
+		}(i)
+		go func() {
+			defer wg.Done()
+			c.Definitions()
+		}()
+		go func() {
+			defer wg.Done()
+			var foo *ditest.Foo
+			require.EqualError(t, c.Extract(&foo), "container not compiled")
+		}()
+	}
+	wg.Wait()
+	c.MustCompile()
+
+	var foo *ditest.Foo
+	c.MustExtract(&foo)
+	for i := 0; i < 50; i++ {
+		wg.Add(3)
+		go func(i int) {
+			defer wg.Done()
+			var value int
+			require.NoError(t, c.Extract(&value, di.ExtractParams{Name: fmt.Sprintf("value-%d", i)}))
+			require.Equal(t, i, value)
+		}(i)
+		go func() {
+			defer wg.Done()
+			require.Len(t, c.Definitions(), 54)
+		}()
+		go func() {
+			defer wg.Done()
+			var extracted *ditest.Foo
+			c.MustExtractPtr(foo, &extracted)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestContainerExtractNamedInterface(t *testing.T) {
+	c := NewTestContainer(t)
+	c.MustProvide(ditest.NewFoo)
+	c.MustProvide(ditest.NewBar)
+	c.Provide(ditest.NewBar, di.ProvideParams{Name: "bar", Interfaces: []interface{}{new(ditest.Fooer)}})
+	c.Provide(ditest.NewBaz, di.ProvideParams{Name: "baz", Interfaces: []interface{}{new(ditest.Fooer)}})
+	c.MustCompile()
+
+	var bar *ditest.Bar
+	c.MustExtractWithName("bar", &bar)
+	var fooer ditest.Fooer
+	c.MustExtractPtrWithName(bar, "bar", &fooer)
+
+	c.MustExtractWithNameError("qux", &fooer, "ditest.Fooer[qux]: not exists in container, available names: `bar`, `baz`")
+	c.MustExtractError(&fooer, "ditest.Fooer: not exists in container, available names: `bar`, `baz`")
+}
+
+func TestContainerExtractFresh(t *testing.T) {
+	t.Run("fresh instance does not replace cached singleton", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewBar)
+		c.MustCompile()
+
+		var cached *ditest.Bar
+		c.MustExtract(&cached)
+		var fresh *ditest.Bar
+		require.NoError(t, c.Extract(&fresh, di.ExtractParams{IsFresh: true}))
+		c.MustNotEqualPointer(cached, fresh)
+		c.MustEqualPointer(cached.Foo(), fresh.Foo())
+
+		var bar *ditest.Bar
+		c.MustExtract(&bar)
+		c.MustEqualPointer(cached, bar)
+	})
+
+	t.Run("fresh instance dependencies resolved as usual", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvidePrototype(ditest.NewFoo)
+		c.MustProvide(ditest.NewBar)
+		c.MustCompile()
+
+		var cached *ditest.Bar
+		c.MustExtract(&cached)
+		var fresh *ditest.Bar
+		require.NoError(t, c.Extract(&fresh, di.ExtractParams{IsFresh: true}))
+		c.MustNotEqualPointer(cached.Foo(), fresh.Foo())
+	})
+
+	t.Run("fresh interface creates new implementation instance", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewBar, new(ditest.Fooer))
+		c.MustCompile()
+
+		var bar *ditest.Bar
+		c.MustExtract(&bar)
+		var fooer ditest.Fooer
+		require.NoError(t, c.Extract(&fooer, di.ExtractParams{IsFresh: true}))
+		c.MustNotEqualPointer(bar, fooer)
+	})
+
+	t.Run("fresh ambiguous interface cause error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewBar, new(ditest.Fooer))
+		c.MustProvide(ditest.NewBaz, new(ditest.Fooer))
+		c.MustCompile()
+
+		var fooer ditest.Fooer
+		require.EqualError(t, c.Extract(&fooer, di.ExtractParams{IsFresh: true}), "ditest.Fooer: have several implementations: *ditest.Bar, *ditest.Baz")
+	})
+}
+
+func TestContainerAppendValue(t *testing.T) {
+	first := di.Location{File: "first.go", Line: 1}
+	second := di.Location{File: "second.go", Line: 2}
+
+	t.Run("appended slices concatenated in registration order", func(t *testing.T) {
+		c := NewTestContainer(t)
+		values := []string{"a", "b"}
+		c.ProvideValue(values, di.ProvideParams{IsAppend: true})
+		c.ProvideValue([]string{"c"}, di.ProvideParams{IsAppend: true})
+		c.MustCompile()
+
+		var extracted []string
+		c.MustExtract(&extracted)
+		require.Equal(t, []string{"a", "b", "c"}, extracted)
+		require.Equal(t, []string{"a", "b"}, values)
+		require.Len(t, c.Definitions(), 4) // with container definitions
+	})
+
+	t.Run("appended maps merged", func(t *testing.T) {
+		c := NewTestContainer(t)
+		values := map[string]int{"a": 1}
+		c.ProvideValue(values, di.ProvideParams{IsAppend: true})
+		c.ProvideValue(map[string]int{"b": 2}, di.ProvideParams{IsAppend: true})
+		c.MustCompile()
+
+		var extracted map[string]int
+		c.MustExtract(&extracted)
+		require.Equal(t, map[string]int{"a": 1, "b": 2}, extracted)
+		require.Equal(t, map[string]int{"a": 1}, values)
+	})
+
+	t.Run("appended values with different names are different definitions", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.ProvideValue([]string{"a"}, di.ProvideParams{Name: "first", IsAppend: true})
+		c.ProvideValue([]string{"b"}, di.ProvideParams{Name: "second", IsAppend: true})
+		c.MustCompile()
+
+		var extracted []string
+		c.MustExtractWithName("second", &extracted)
+		require.Equal(t, []string{"b"}, extracted)
+	})
+
+	t.Run("map key collision causes panic with both locations", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.ProvideValue(map[string]int{"a": 1}, di.ProvideParams{IsAppend: true, Location: first})
+		require.PanicsWithValue(t, "second.go:2: map[string]int key `a` already provided at first.go:1", func() {
+			c.ProvideValue(map[string]int{"a": 2}, di.ProvideParams{IsAppend: true, Location: second})
+		})
+	})
+
+	t.Run("append to not appendable value causes panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.ProvideValue([]string{"a"})
+		require.PanicsWithValue(t, "The `[]string` type already exists in container", func() {
+			c.ProvideValue([]string{"b"}, di.ProvideParams{IsAppend: true})
+		})
+	})
+
+	t.Run("append of not slice or map causes panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		require.PanicsWithValue(t, "first.go:1: appended value must be a slice or a map, got `string`", func() {
+			c.ProvideValue("a", di.ProvideParams{IsAppend: true, Location: first})
+		})
+	})
+}
+
+func TestContainerRecoverMode(t *testing.T) {
+	t.Run("panic propagates by default", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(func() *ditest.Foo { panic("boom") })
+		c.MustCompile()
+		var foo *ditest.Foo
+		require.PanicsWithValue(t, "boom", func() { _ = c.Extract(&foo) })
+	})
+
+	t.Run("wrapped panic of dependency is returned with its value and stack", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.SetRecoverMode(di.WrapError)
+		calls := 0
+		c.MustProvide(func() *ditest.Foo {
+			calls++
+			panic(errors.New("boom"))
+		})
+		c.MustProvide(ditest.NewBar)
+		c.MustCompile()
+
+		var bar *ditest.Bar
+		err := c.Extract(&bar)
+		require.EqualError(t, err, "*ditest.Foo: panic: boom")
+		var recovered di.ErrPanic
+		require.True(t, errors.As(err, &recovered))
+		require.EqualError(t, recovered.Value().(error), "boom")
+		require.Contains(t, string(recovered.Stack()), "container_test.go")
+		require.Equal(t, []string{"*ditest.Bar", "*ditest.Foo"}, di.DependencyPath(err))
+		require.Error(t, c.Extract(&bar))
+		require.Equal(t, 2, calls)
+	})
+
+	t.Run("wrapped panic of invoked function is returned", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.SetRecoverMode(di.WrapError)
+		c.MustProvide(ditest.NewFoo)
+		c.MustCompile()
+		c.MustInvokeError(func(foo *ditest.Foo) { panic("boom") }, "panic: boom")
+	})
+}
+
+func TestContainerImplementationsOf(t *testing.T) {
+	c := NewTestContainer(t)
+	c.MustProvide(ditest.NewFoo)
+	c.MustProvide(ditest.NewBar, new(ditest.Fooer))
+	c.Provide(ditest.NewBaz, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}, Order: -1})
+	c.MustCompile()
+
+	infos, err := c.ImplementationsOf(new(ditest.Fooer))
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+	require.Equal(t, "*ditest.Baz", infos[0].Key.String())
+	require.Equal(t, "*ditest.Bar", infos[1].Key.String())
+	require.False(t, infos[0].Created)
+
+	var fooers []ditest.Fooer
+	c.MustExtract(&fooers)
+	require.IsType(t, &ditest.Baz{}, fooers[0])
+
+	infos, err = c.ImplementationsOf(new(fmt.Stringer))
+	require.NoError(t, err)
+	require.Empty(t, infos)
+
+	_, err = c.ImplementationsOf(new(int))
+	require.EqualError(t, err, "ImplementationsOf() argument must be a pointer to interface, got pointer to int *int")
+}
+
+func TestContainerHas(t *testing.T) {
+	c := NewTestContainer(t)
+	require.False(t, c.Has(new(*ditest.Foo)))
+	c.MustProvide(ditest.NewFoo)
+	c.MustProvide(ditest.NewBar, new(ditest.Fooer))
+	c.MustProvide(ditest.NewBaz, new(ditest.Fooer))
+	c.MustCompile()
+
+	require.True(t, c.Has(new(*ditest.Foo)))
+	require.False(t, c.Has(new(*ditest.Foo), di.ExtractParams{Name: "foo"}))
+	require.False(t, c.Has(new(*ditest.Qux)))
+	require.False(t, c.Has(new(ditest.Fooer)), "several implementations")
+	require.False(t, c.Has(nil))
+
+	var resolver di.Resolver
+	c.MustExtract(&resolver)
+	require.True(t, resolver.Has(new(*ditest.Bar)))
+	require.False(t, c.Definitions()[0].Created, "has does not create instances")
+}
+
+func TestContainerExtractOr(t *testing.T) {
+	t.Run("existing type is extracted", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustCompile()
+
+		var foo *ditest.Foo
+		require.NoError(t, c.ExtractOr(&foo, &ditest.Foo{Name: "fallback"}))
+		require.Equal(t, "", foo.Name)
+	})
+
+	t.Run("not existing type is filled with fallback", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustCompile()
+
+		fallback := &ditest.Foo{Name: "fallback"}
+		var foo *ditest.Foo
+		require.NoError(t, c.ExtractOr(&foo, fallback, di.ExtractParams{Name: "foo"}))
+		require.Same(t, fallback, foo)
+		require.False(t, c.Has(new(*ditest.Foo), di.ExtractParams{Name: "foo"}), "fallback is not registered")
+
+		var fooer ditest.Fooer = &ditest.Bar{}
+		require.NoError(t, c.ExtractOr(&fooer, nil))
+		require.Nil(t, fooer)
+	})
+
+	t.Run("construction error is returned", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.CreateFooConstructorWithError(errors.New("internal error")))
+		c.MustProvide(ditest.NewBar)
+		c.MustCompile()
+
+		var bar *ditest.Bar
+		require.EqualError(t, c.ExtractOr(&bar, &ditest.Bar{}), "*ditest.Foo: internal error")
+	})
+
+	t.Run("not assignable fallback cause error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustCompile()
+
+		var foo *ditest.Foo
+		require.EqualError(t, c.ExtractOr(&foo, ditest.Foo{}), "fallback of type `ditest.Foo` is not assignable to `*ditest.Foo`")
+	})
+}
+
+func TestContainerCompileLog(t *testing.T) {
+	c := NewTestContainer(t)
+	var b strings.Builder
+	c.SetCompileLog(&b)
+	c.MustProvide(ditest.NewFoo)
+	c.MustProvide(ditest.NewBar, new(ditest.Fooer))
+	c.Provide(ditest.NewBaz, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}, IsPrototype: true, IsPrimary: true})
+	c.MustCompile()
+	require.Equal(t, ""+
+		"compiled 3 definitions\n"+
+		"construction order:\n"+
+		"  1. *ditest.Foo [singleton]\n"+
+		"  2. *ditest.Bar [singleton]\n"+
+		"  3. *ditest.Baz [prototype]\n"+
+		"interface bindings:\n"+
+		"  ditest.Fooer -> *ditest.Baz: primary of 2 implementations\n",
+		b.String())
+}
+
+func TestContainerGraphLog(t *testing.T) {
+	c := NewTestContainer(t)
+	var b strings.Builder
+	c.SetGraphLog(&b)
+	c.Provide(ditest.NewBaz, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}, IsPrototype: true, IsPrimary: true})
+	c.MustProvide(ditest.NewBar, new(ditest.Fooer))
+	c.MustProvide(ditest.NewFoo)
+	c.MustCompile()
+	require.Equal(t, ""+
+		`key="*ditest.Bar" lifetime=singleton dependencies="*ditest.Foo" implements="" location="`+location(ditest.NewBar)+`"`+"\n"+
+		`key="*ditest.Baz" lifetime=prototype dependencies="*ditest.Foo, *ditest.Bar" implements="ditest.Fooer" location="`+location(ditest.NewBaz)+`"`+"\n"+
+		`key="*ditest.Foo" lifetime=singleton dependencies="" implements="" location="`+location(ditest.NewFoo)+`"`+"\n",
+		b.String())
+}
+
+func TestContainerProvideDedup(t *testing.T) {
+	t.Run("the same constructor is registered once", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.Provide(ditest.NewFoo, di.ProvideParams{IsDedup: true})
+		c.MustProvide(ditest.NewBar, new(ditest.Fooer))
+		c.Provide(ditest.NewBar, di.ProvideParams{IsDedup: true, Interfaces: []interface{}{new(ditest.Fooer)}})
+		c.MustCompile()
+		require.Len(t, c.Definitions(), 5)
+		var fooer ditest.Fooer
+		c.MustExtract(&fooer)
+	})
+
+	t.Run("different markers conflict", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		require.Panics(t, func() {
+			c.Provide(ditest.NewFoo, di.ProvideParams{IsDedup: true, IsPrototype: true})
+		})
+	})
+
+	t.Run("different constructors conflict with both locations", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		_, file, line, _ := runtime.Caller(0)
+		newFoo := func() *ditest.Foo { return &ditest.Foo{} }
+		require.PanicsWithValue(t, fmt.Sprintf("The `*ditest.Foo` type is provided by different providers at %s and at %s:%d",
+			c.Definitions()[0].Location, file, line+1), func() {
+			c.Provide(newFoo, di.ProvideParams{IsDedup: true})
+		})
+	})
+}
+
+func TestContainerProvideCondition(t *testing.T) {
+	type Config struct{ Tracing bool }
+
+	t.Run("definition is registered if condition with provided values returns true", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.ProvideValue(&Config{Tracing: true})
+		c.Provide(ditest.NewFoo, di.ProvideParams{Condition: func(cfg *Config) bool { return cfg.Tracing }})
+		c.MustCompile()
+		var foo *ditest.Foo
+		c.MustExtract(&foo)
+		require.Empty(t, c.Report().Findings)
+	})
+
+	t.Run("skipped definition is reported", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.ProvideValue(&Config{})
+		_, file, line, _ := runtime.Caller(0)
+		condition := func(cfg *Config) bool { return cfg.Tracing }
+		c.Provide(ditest.NewFoo, di.ProvideParams{Condition: condition})
+		c.MustProvide(ditest.NewFoo)
+		c.MustCompile()
+		require.Equal(t, []di.Finding{{
+			Kind:     di.FindingSkipped,
+			Severity: di.SeverityInfo,
+			Key:      "*ditest.Foo",
+			Message:  fmt.Sprintf("skipped by condition at %s:%d", file, line+1),
+			Location: &di.Location{File: file, Line: line + 1, Function: runtime.FuncForPC(reflect.ValueOf(condition).Pointer()).Name()},
+		}}, c.Report().Findings)
+	})
+
+	t.Run("incorrect condition causes panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		recovered := func(condition interface{}) (value interface{}) {
+			defer func() { value = recover() }()
+			c.Provide(ditest.NewFoo, di.ProvideParams{Condition: condition})
+			return nil
+		}
+		require.True(t, strings.HasSuffix(recovered(func() {}).(string),
+			"foo.go:11: *ditest.Foo: the condition must be a function like `func([value1, value2, ...]) bool`, got `func()`"))
+		require.True(t, strings.HasSuffix(recovered(func(cfg *Config) bool { return true }).(string),
+			"foo.go:11: *ditest.Foo: condition parameter *di_test.Config must be a value provided before"))
+	})
+}
+
+func TestContainerFactory(t *testing.T) {
+	t.Run("factory creates prototype with singleton dependencies on each call", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvidePrototype(ditest.NewBar)
+		c.MustCompile()
+		c.MustInvoke(func(newBar func() (*ditest.Bar, error)) {
+			bar1, err := newBar()
+			require.NoError(t, err)
+			bar2, err := newBar()
+			require.NoError(t, err)
+			require.False(t, bar1 == bar2)
+			require.True(t, bar1.Foo() == bar2.Foo())
+		})
+	})
+
+	t.Run("factory with context returns context error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvidePrototype(ditest.NewFoo)
+		c.MustCompile()
+		c.MustInvoke(func(newFoo func(ctx context.Context) (*ditest.Foo, error)) {
+			foo, err := newFoo(context.Background())
+			require.NoError(t, err)
+			require.NotNil(t, foo)
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			_, err = newFoo(ctx)
+			require.Equal(t, context.Canceled, err)
+		})
+	})
+
+	t.Run("factory returns constructor error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvidePrototype(ditest.CreateFooConstructorWithError(errors.New("boom")))
+		c.MustCompile()
+		c.MustInvoke(func(newFoo func() (*ditest.Foo, error)) {
+			_, err := newFoo()
+			require.EqualError(t, err, "*ditest.Foo: boom")
+		})
+	})
+
+	t.Run("provided function has priority and factory of missing type is not synthesized", func(t *testing.T) {
+		c := NewTestContainer(t)
+		foo := &ditest.Foo{}
+		c.MustProvide(func() func() (*ditest.Foo, error) {
+			return func() (*ditest.Foo, error) { return foo, nil }
+		})
+		c.MustCompile()
+		c.MustInvoke(func(newFoo func() (*ditest.Foo, error)) {
+			created, _ := newFoo()
+			require.True(t, created == foo)
+		})
+		var newBar func() (*ditest.Bar, error)
+		c.MustExtractError(&newBar, "func() (*ditest.Bar, error): not exists in container")
+	})
+
+	t.Run("factory is explained", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvidePrototype(ditest.NewFoo)
+		c.MustCompile()
+		var newFoo func() (*ditest.Foo, error)
+		var b strings.Builder
+		require.NoError(t, c.Explain(&newFoo, &b))
+		require.Equal(t, "func() (*ditest.Foo, error) [factory] creates *ditest.Foo on each call\n", b.String())
+	})
+}
+
+func TestContainerBuild(t *testing.T) {
+	type Order struct {
+		foo *ditest.Foo
+		id  string
+		qty int
+	}
+	newOrder := func(foo *ditest.Foo, id string, qty int) *Order { return &Order{foo: foo, id: id, qty: qty} }
+
+	t.Run("parameters are resolved from container and extras", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustCompile()
+		var foo *ditest.Foo
+		c.MustExtract(&foo)
+		built, err := c.Build(newOrder, []interface{}{3, "42"})
+		require.NoError(t, err)
+		require.Equal(t, &Order{foo: foo, id: "42", qty: 3}, built)
+		require.Len(t, c.Definitions(), 4)
+	})
+
+	t.Run("registered types are resolved from container unless extras are preferred", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.ProvideValue("registered")
+		c.MustCompile()
+		_, err := c.Build(newOrder, []interface{}{"42", 3})
+		require.EqualError(t, err, "extra argument 0 of type `string` does not match build parameters")
+		built, err := c.Build(newOrder, []interface{}{"42", 3}, di.BuildParams{IsPreferExtras: true})
+		require.NoError(t, err)
+		require.Equal(t, "42", built.(*Order).id)
+		built, err = c.Build(newOrder, []interface{}{3})
+		require.NoError(t, err)
+		require.Equal(t, "registered", built.(*Order).id)
+	})
+
+	t.Run("missing parameter and constructor error are returned", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustCompile()
+		_, err := c.Build(newOrder, []interface{}{"42", 3})
+		require.EqualError(t, err, "could not resolve build parameters: *ditest.Foo: not exists in container")
+		_, err = c.Build(func(id string) (*Order, error) { return nil, errors.New("unknown order") }, []interface{}{"42"})
+		require.EqualError(t, err, "*di_test.Order: unknown order")
+	})
+
+	t.Run("incorrect build function causes error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustCompile()
+		_, err := c.Build(func() {}, nil)
+		require.EqualError(t, err, "the build function must be a function like `func([dep1, dep2, ...]) (<result>, [cleanup, error])`, got `func()`")
+		_, err = c.Build("string", nil)
+		require.EqualError(t, err, "the build function must be a function like `func([dep1, dep2, ...]) (<result>, [cleanup, error])`, got `string`")
+	})
+}
+
+func TestContainerAutoConvert(t *testing.T) {
+	type Timeout time.Duration
+	type Delay time.Duration
+	newClient := func(timeout time.Duration) *http.Client { return &http.Client{Timeout: timeout} }
+
+	t.Run("missing type is not converted by default", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.ProvideValue(Timeout(time.Second))
+		c.MustCompile()
+		var timeout time.Duration
+		c.MustExtractError(&timeout, "time.Duration: not exists in container")
+	})
+
+	t.Run("missing type resolves by conversion of convertible definition", func(t *testing.T) {
+		c := NewTestContainer(t)
+		var b strings.Builder
+		c.SetCompileLog(&b)
+		c.SetAutoConvert()
+		c.ProvideValue(Timeout(time.Second))
+		c.MustProvide(newClient)
+		c.MustCompile()
+		var client *http.Client
+		c.MustExtract(&client)
+		require.Equal(t, time.Second, client.Timeout)
+		require.Contains(t, b.String(), "conversions:\n  time.Duration <- di_test.Timeout\n")
+
+		var explained strings.Builder
+		require.NoError(t, c.Explain(&client, &explained))
+		require.Equal(t, ""+
+			"*http.Client [singleton, built]\n"+
+			"  time.Duration [conversion] converted from di_test.Timeout\n"+
+			"    di_test.Timeout [singleton, built]\n",
+			explained.String())
+	})
+
+	t.Run("several convertible definitions fail resolution", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.SetAutoConvert()
+		c.ProvideValue(Timeout(time.Second))
+		c.ProvideValue(Delay(time.Millisecond))
+		c.MustCompile()
+		var timeout time.Duration
+		c.MustExtractError(&timeout, "time.Duration: have several convertible definitions: di_test.Timeout, di_test.Delay")
+	})
+
+	t.Run("types of different kinds and interfaces are not converted", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.SetAutoConvert()
+		c.ProvideValue(10)
+		c.MustProvide(ditest.NewFoo)
+		c.MustCompile()
+		var s string
+		c.MustExtractError(&s, "string: not exists in container")
+		var fooer ditest.Fooer
+		c.MustExtractError(&fooer, "ditest.Fooer: not exists in container")
+	})
+}
+
+func TestContainerChannels(t *testing.T) {
+	type Producer struct{ events chan<- int }
+	type Consumer struct{ events <-chan int }
+
+	t.Run("directional channels resolve from bidirectional channel", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(func() chan int { return make(chan int, 1) })
+		c.MustProvide(func(events chan<- int) *Producer { return &Producer{events: events} })
+		c.MustProvide(func(events <-chan int) *Consumer { return &Consumer{events: events} })
+		c.MustCompile()
+
+		var producer *Producer
+		var consumer *Consumer
+		c.MustExtract(&producer)
+		c.MustExtract(&consumer)
+		producer.events <- 42
+		require.Equal(t, 42, <-consumer.events)
+	})
+
+	t.Run("channels of different element types are different definitions", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(func() chan int { return make(chan int) })
+		c.MustProvide(func() chan string { return make(chan string) })
+		c.MustCompile()
+
+		var events <-chan string
+		c.MustExtract(&events)
+		var errs <-chan error
+		c.MustExtractError(&errs, "<-chan error: not exists in container, channel is not provided, provide the channel as value")
+	})
+
+	t.Run("nil channel value cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		require.PanicsWithValue(t, "config.go:12: chan int: the value is nil channel, operations on it block forever", func() {
+			c.ProvideValue((chan int)(nil), di.ProvideParams{Location: di.Location{File: "config.go", Line: 12}})
+		})
+		c.ProvideValue((chan int)(nil), di.ProvideParams{IsAllowNil: true})
+		c.MustCompile()
+	})
+}
+
+func TestContainerNames(t *testing.T) {
+	t.Run("additional names share definition instance", func(t *testing.T) {
+		c := NewTestContainer(t)
+		var calls int
+		c.Provide(func() *http.Client {
+			calls++
+			return &http.Client{}
+		}, di.ProvideParams{Name: "internal", Names: []string{"external"}})
+		c.MustCompile()
+
+		var internal, external *http.Client
+		require.NoError(t, c.Extract(&internal, di.ExtractParams{Name: "internal"}))
+		require.NoError(t, c.Extract(&external, di.ExtractParams{Name: "external"}))
+		require.Same(t, internal, external)
+		require.Equal(t, 1, calls)
+
+		infos := c.Definitions()
+		require.Len(t, infos[0].Keys, 2)
+		require.Equal(t, "*http.Client[internal]", infos[0].Keys[0].String())
+		require.Equal(t, "*http.Client[external]", infos[0].Keys[1].String())
+	})
+
+	t.Run("additional name of existing definition cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvideWithName("external", func() *http.Client { return &http.Client{} })
+		require.PanicsWithValue(t, "The `*http.Client[external]` type already exists in container", func() {
+			c.Provide(func() *http.Client { return &http.Client{} }, di.ProvideParams{Name: "internal", Names: []string{"external"}})
+		})
+	})
+
+	t.Run("definition with name of existing alias cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.Provide(func() *http.Client { return &http.Client{} }, di.ProvideParams{Name: "internal", Names: []string{"external"}})
+		require.Panics(t, func() {
+			c.Provide(func() *http.Client { return &http.Client{} }, di.ProvideParams{Name: "external"})
+		})
+	})
+}
+
+func TestContainerExplicitBindings(t *testing.T) {
+	t.Run("embedded interface does not resolve", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(func() *bytes.Buffer { return &bytes.Buffer{} }, new(io.ReadWriter))
+		c.SetExplicitBindings()
+		c.MustCompile()
+
+		var rw io.ReadWriter
+		c.MustExtract(&rw)
+		var r io.Reader
+		c.MustExtractError(&r, "io.Reader: not exists in container, *bytes.Buffer implements this interface but is not bound, add As(new(io.Reader)) provide option")
+	})
+
+	t.Run("bound interface resolves", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(func() *bytes.Buffer { return &bytes.Buffer{} }, new(io.ReadWriter))
+		c.Bind(new(io.Reader), &bytes.Buffer{})
+		c.SetExplicitBindings()
+		c.MustCompile()
+
+		var r io.Reader
+		c.MustExtract(&r)
+	})
+
+	t.Run("not found interface names unbound implementations", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(func() *bytes.Buffer { return &bytes.Buffer{} })
+		c.MustCompile()
+
+		var w io.Writer
+		c.MustExtractError(&w, "io.Writer: not exists in container, *bytes.Buffer implements this interface but is not bound, add As(new(io.Writer)) provide option")
+	})
+}
+
+func TestContainerSetters(t *testing.T) {
+	t.Run("mutual references are wired by setters", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.Provide(ditest.NewOrders, di.ProvideParams{Setters: []string{"SetNotifier"}})
+		c.Provide(ditest.NewNotifier, di.ProvideParams{Setters: []string{"SetOrders"}})
+		c.MustCompile()
+
+		var orders *ditest.Orders
+		var notifier *ditest.Notifier
+		c.MustExtract(&orders)
+		c.MustExtract(&notifier)
+		c.MustEqualPointer(notifier, orders.Notifier())
+		c.MustEqualPointer(orders, notifier.Orders())
+	})
+
+	t.Run("setter error contains setter and type", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.Provide(ditest.NewNotifier, di.ProvideParams{Setters: []string{"SetFoo"}})
+		c.MustCompile()
+
+		var notifier *ditest.Notifier
+		c.MustExtractError(&notifier, "*ditest.Notifier: setter SetFoo: foo is not supported")
+	})
+
+	t.Run("not provided setter dependency cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.Provide(ditest.NewOrders, di.ProvideParams{Setters: []string{"SetNotifier"}})
+		c.MustCompileError("*ditest.Orders: setter SetNotifier: dependency *ditest.Notifier not exists in container")
+	})
+
+	t.Run("unknown setter cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		require.PanicsWithValue(t, fmt.Sprintf("%s: *ditest.Orders has no setter method SetClock", location(ditest.NewOrders)), func() {
+			c.Provide(ditest.NewOrders, di.ProvideParams{Setters: []string{"SetClock"}})
+		})
+		require.PanicsWithValue(t, fmt.Sprintf("%s: setter Notifier of *ditest.Orders must be a method like `func(dep1, [dep2, ...]) [error]`, got `func(*ditest.Orders) *ditest.Notifier`", location(ditest.NewOrders)), func() {
+			c.Provide(ditest.NewOrders, di.ProvideParams{Setters: []string{"Notifier"}})
+		})
+	})
+}
+
+func TestContainerPhases(t *testing.T) {
+	t.Run("setters wait for wire phase", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.Provide(ditest.NewOrders, di.ProvideParams{Setters: []string{"SetNotifier"}})
+		c.Provide(ditest.NewNotifier, di.ProvideParams{Setters: []string{"SetOrders"}})
+		c.MustCompile()
+
+		require.NoError(t, c.BuildAll())
+		var orders *ditest.Orders
+		var notifier *ditest.Notifier
+		c.MustExtract(&orders)
+		c.MustExtract(&notifier)
+		require.Nil(t, orders.Notifier())
+		require.Nil(t, notifier.Orders())
+
+		require.NoError(t, c.Wire())
+		c.MustEqualPointer(notifier, orders.Notifier())
+		c.MustEqualPointer(orders, notifier.Orders())
+	})
+
+	t.Run("starters are started in construction order", func(t *testing.T) {
+		recorder := &ditest.Recorder{}
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewCar)
+		c.MustProvide(ditest.NewEngine)
+		c.ProvideValue(recorder)
+		c.MustCompile()
+
+		require.NoError(t, c.BuildAll())
+		require.NoError(t, c.Wire())
+		require.NoError(t, c.Start(context.Background()))
+		require.Equal(t, []string{"engine", "car"}, recorder.Started)
+		require.NoError(t, c.Start(context.Background()))
+		require.Len(t, recorder.Started, 2)
+	})
+
+	t.Run("phases must go in order", func(t *testing.T) {
+		c := NewTestContainer(t)
+		require.EqualError(t, c.BuildAll(), "container not compiled")
+		c.MustCompile()
+		require.EqualError(t, c.Wire(), "container not built, call BuildAll() before Wire()")
+		require.EqualError(t, c.Start(context.Background()), "container not wired, call Wire() before Start()")
+	})
+
+	t.Run("failed phases return errors", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.CreateFooConstructorWithError(errors.New("internal error")))
+		c.MustCompile()
+		require.EqualError(t, c.BuildAll(), "*ditest.Foo: internal error")
+
+		c = NewTestContainer(t)
+		c.MustProvide(ditest.NewBroken)
+		c.MustCompile()
+		require.NoError(t, c.BuildAll())
+		require.NoError(t, c.Wire())
+		require.EqualError(t, c.Start(context.Background()), "*ditest.Broken: start: port is busy")
+	})
+
+	t.Run("canceled build cleans up created instances", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		var cleaned bool
+		c := NewTestContainer(t)
+		c.MustProvide(func() (*ditest.Foo, func()) {
+			cancel()
+			return &ditest.Foo{}, func() { cleaned = true }
+		})
+		c.MustProvide(ditest.NewBar)
+		c.MustCompile()
+
+		err := c.BuildContext(ctx)
+		require.EqualError(t, err, "build canceled after 1 of 2 definitions: context canceled")
+		require.True(t, errors.Is(err, context.Canceled))
+		require.True(t, cleaned)
+		require.EqualError(t, c.BuildAll(), "container build canceled, create a new container")
+		require.EqualError(t, c.Wire(), "container build canceled, create a new container")
+	})
+}
+
+func TestContainerNonCopyable(t *testing.T) {
+	type Counter struct {
+		mu    sync.Mutex
+		count int
+	}
+	type Stats struct {
+		hits atomic.Int64
+	}
+	type Guarded struct {
+		counter Counter
+	}
+
+	t.Run("struct with mutex provided by value cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		require.PanicsWithValue(t, "config.go:12: provide *di_test.Counter instead of di_test.Counter: di_test.Counter contains sync.Mutex", func() {
+			c.ProvideValue(Counter{}, di.ProvideParams{Location: di.Location{File: "config.go", Line: 12}})
+		})
+	})
+
+	t.Run("constructor of struct with atomic type cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		require.Panics(t, func() {
+			c.Provide(func() Stats { return Stats{} })
+		})
+		require.PanicsWithValue(t, "config.go:12: provide *di_test.Stats instead of di_test.Stats: di_test.Stats contains atomic.Int64", func() {
+			c.ProvideValue(Stats{}, di.ProvideParams{Location: di.Location{File: "config.go", Line: 12}})
+		})
+	})
+
+	t.Run("nested lock is found", func(t *testing.T) {
+		c := NewTestContainer(t)
+		require.Panics(t, func() {
+			c.ProvideValue(Guarded{})
+		})
+	})
+
+	t.Run("pointer and allowed copy are provided", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(func() *Counter { return &Counter{} })
+		c.ProvideValue(Stats{}, di.ProvideParams{IsAllowCopy: true})
+		c.MustCompile()
+		var counter *Counter
+		c.MustExtract(&counter)
+	})
+}
+
+func TestContainerReport(t *testing.T) {
+	t.Run("report summarizes graph and tolerated issues", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.Provide(ditest.NewFoo, di.ProvideParams{Deprecated: "use NewFooV2"})
+		c.MustProvide(ditest.NewBar, new(ditest.Fooer))
+		c.Provide(ditest.NewBaz, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}, IsPrimary: true})
+		c.MustProvide(ditest.NewQux)
+		c.MustProvide(ditest.NewLogger)
+		c.MustProvide(ditest.NewRouter)
+		c.MustCompile()
+		report := c.Report()
+		require.True(t, report.Duration > 0)
+		report.Duration = 0
+		require.Equal(t, location(ditest.NewFoo), report.Findings[0].Location.String())
+		report.Findings[0].Location = nil
+		require.Equal(t, di.CompileReport{
+			Definitions: 6,
+			Interfaces:  1,
+			Groups:      1,
+			MaxDepth:    5,
+			FanIn: []di.NodeDegree{
+				{Key: "*ditest.Bar", Degree: 2},
+				{Key: "*ditest.Baz", Degree: 2},
+				{Key: "*ditest.Foo", Degree: 2},
+				{Key: "*log.Logger", Degree: 1},
+				{Key: "ditest.Fooer", Degree: 1},
+			},
+			FanOut: []di.NodeDegree{
+				{Key: "*ditest.Baz", Degree: 2},
+				{Key: "*http.ServeMux", Degree: 2},
+				{Key: "[]ditest.Fooer", Degree: 2},
+				{Key: "*ditest.Bar", Degree: 1},
+				{Key: "*ditest.Qux", Degree: 1},
+			},
+			Findings: []di.Finding{
+				{Kind: di.FindingDeprecated, Severity: di.SeverityWarning, Key: "*ditest.Foo", Message: "use NewFooV2"},
+				{Kind: di.FindingOptional, Severity: di.SeverityInfo, Key: "ditest.RouterParams", Message: "[]ditest.Controller is not provided"},
+				{Kind: di.FindingPrimary, Severity: di.SeverityInfo, Key: "ditest.Fooer", Message: "*ditest.Baz is primary of 2 implementations"},
+			},
+		}, report)
+	})
+
+	t.Run("ambiguous interface is reported", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewBar, new(ditest.Fooer))
+		c.MustProvide(ditest.NewBaz, new(ditest.Fooer))
+		c.MustCompile()
+		require.Equal(t, []di.Finding{
+			{Kind: di.FindingAmbiguous, Severity: di.SeverityWarning, Key: "ditest.Fooer", Message: "have several implementations: *ditest.Bar, *ditest.Baz"},
+		}, c.Report().Findings)
+	})
+
+	t.Run("report of not compiled container causes panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		require.PanicsWithValue(t, "Report() must be called after Compile()", func() { c.Report() })
+	})
+}
+
+func TestContainerRecover(t *testing.T) {
+	diagnostics := func(err error) *di.Diagnostics {
+		var failed di.ErrCompileFailed
+		require.True(t, errors.As(err, &failed))
+		return failed.Diagnostics()
+	}
+
+	t.Run("invalid provider is provide stage error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		err := c.Recover(func() { c.Provide(ditest.ConstructorWithoutResult) })
+		require.Error(t, err)
+		d := diagnostics(err)
+		require.Equal(t, di.StageProvide, d.Stage)
+		require.Len(t, d.Findings, 1)
+		require.Equal(t, di.FindingInvalid, d.Findings[0].Kind)
+		require.Equal(t, di.SeverityError, d.Findings[0].Severity)
+		require.Equal(t, err.Error(), d.Findings[0].Message)
+		require.Equal(t, location(ditest.ConstructorWithoutResult), d.Findings[0].Location.String())
+	})
+
+	t.Run("missing dependency is connect stage error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewBar)
+		err := c.Recover(c.Compile)
+		require.EqualError(t, err, "*ditest.Bar: dependency *ditest.Foo not exists in container")
+		d := diagnostics(err)
+		require.Equal(t, di.StageConnect, d.Stage)
+		require.Equal(t, di.FindingUnresolved, d.Findings[0].Kind)
+		require.Equal(t, "*ditest.Bar", d.Findings[0].Key)
+		require.Equal(t, location(ditest.NewBar), d.Findings[0].Location.String())
+	})
+
+	t.Run("dependency cycle is cycle stage error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewCycleFooBar)
+		c.MustProvide(ditest.NewBar)
+		err := c.Recover(c.Compile)
+		var cycle di.ErrDependencyCycle
+		require.True(t, errors.As(err, &cycle))
+		d := diagnostics(err)
+		require.Equal(t, di.StageCycle, d.Stage)
+		require.Equal(t, di.FindingCycle, d.Findings[0].Kind)
+		require.NotEmpty(t, d.Findings[0].Key)
+		require.NotNil(t, d.Findings[0].Location)
+	})
+
+	t.Run("failed constructor is build stage error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.CreateFooConstructorWithError(errors.New("internal error")))
+		require.NoError(t, c.Recover(c.Compile))
+		err := c.Recover(func() {
+			if err := c.BuildAll(); err != nil {
+				panic(err)
+			}
+		})
+		require.EqualError(t, err, "*ditest.Foo: internal error")
+		d := diagnostics(err)
+		require.Equal(t, di.StageBuild, d.Stage)
+		require.Equal(t, di.FindingFailed, d.Findings[0].Kind)
+		require.Equal(t, "*ditest.Foo", d.Findings[0].Key)
+	})
+}
+
+func TestContainerWarmUp(t *testing.T) {
+	t.Run("warm up creates targets and their dependencies only", func(t *testing.T) {
+		for _, parallel := range []bool{false, true} {
+			c := NewTestContainer(t)
+			c.MustProvide(ditest.NewFoo)
+			c.MustProvide(ditest.NewBar)
+			c.MustProvide(ditest.NewBaz)
+			c.MustCompile()
+			warmUp := c.WarmUp
+			if parallel {
+				warmUp = c.WarmUpParallel
+			}
+			require.NoError(t, warmUp(new(*ditest.Bar), new(*ditest.Foo)))
+			infos := c.Definitions()
+			require.True(t, infos[0].Created)
+			require.True(t, infos[1].Created)
+			require.False(t, infos[2].Created)
+		}
+	})
+
+	t.Run("warm up returns errors of failed roots with dependency paths", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.CreateFooConstructorWithError(errors.New("internal error")))
+		c.MustProvide(ditest.NewBar)
+		c.ProvideValue(&http.ServeMux{})
+		c.MustCompile()
+		err := c.WarmUpParallel(new(*ditest.Bar), new(*http.ServeMux), new(*http.Client))
+		require.EqualError(t, err, "warm up failed for 2 of 3 roots: "+
+			"*ditest.Bar: *ditest.Foo: internal error (path: *ditest.Bar -> *ditest.Foo); "+
+			"*http.Client: not exists in container")
+		var failed di.ErrWarmUp
+		require.True(t, errors.As(err, &failed))
+		require.Equal(t, []string{"*ditest.Bar", "*http.Client"}, failed.Roots())
+		var notFound di.ErrParameterProviderNotFound
+		require.True(t, errors.As(err, &notFound))
+	})
+
+	t.Run("warm up of not compiled container fails", func(t *testing.T) {
+		c := NewTestContainer(t)
+		require.EqualError(t, c.WarmUp(new(*ditest.Foo)), "warm up failed for 1 of 1 roots: **ditest.Foo: container not compiled")
+	})
+}
+
+func TestContainerRegistry(t *testing.T) {
+	registered := func(name string) di.ProvideParams {
+		return di.ProvideParams{Name: name, IsRegistry: true, Interfaces: []interface{}{new(ditest.Fooer)}}
+	}
+
+	t.Run("registry resolves as map of instances", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewBar)
+		c.Provide(ditest.NewBar, registered("bar"))
+		c.Provide(ditest.NewBaz, registered("baz"))
+		c.MustCompile()
+		var registry map[string]ditest.Fooer
+		c.MustExtract(&registry)
+		require.Len(t, registry, 2)
+		require.IsType(t, &ditest.Bar{}, registry["bar"])
+		require.IsType(t, &ditest.Baz{}, registry["baz"])
+		var bars map[string]*ditest.Bar
+		c.MustExtract(&bars)
+		require.Len(t, bars, 1)
+		c.MustEqualPointer(registry["bar"], bars["bar"])
+	})
+
+	t.Run("registry resolves as map of lazy factories", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewBar)
+		c.Provide(ditest.NewBar, registered("bar"))
+		c.Provide(ditest.NewBaz, registered("baz"))
+		c.MustCompile()
+		var factories map[string]func() (ditest.Fooer, error)
+		c.MustExtract(&factories)
+		require.Len(t, factories, 2)
+		for _, info := range c.Definitions() {
+			require.False(t, info.Created)
+		}
+		bar, err := factories["bar"]()
+		require.NoError(t, err)
+		require.IsType(t, &ditest.Bar{}, bar)
+		var extracted *ditest.Bar
+		c.MustExtractWithName("bar", &extracted)
+		c.MustEqualPointer(bar, extracted)
+	})
+
+	t.Run("duplicate registry key causes panic with both locations", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.Provide(ditest.NewBar, registered("x"))
+		require.PanicsWithValue(t, fmt.Sprintf("%s: map[string]ditest.Fooer key `x` already registered by *ditest.Bar at %s",
+			location(ditest.NewBaz), location(ditest.NewBar)), func() {
+			c.Provide(ditest.NewBaz, registered("x"))
+		})
+	})
+
+	t.Run("registry definition without name causes panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		require.PanicsWithValue(t, fmt.Sprintf("%s: *ditest.Bar registry definition requires a name", location(ditest.NewBar)),
+			func() {
+				c.Provide(ditest.NewBar, di.ProvideParams{IsRegistry: true})
+			})
+	})
+}
+
+func TestContainerFallbacks(t *testing.T) {
+	t.Run("missing type resolves from fallback owner", func(t *testing.T) {
+		host := NewTestContainer(t)
+		host.MustProvide(ditest.NewFoo)
+		host.MustCompile()
+		plugin := NewTestContainer(t)
+		plugin.MustProvide(ditest.NewBar)
+		plugin.SetFallbacks(host.Container)
+		plugin.MustCompile()
+		var bar *ditest.Bar
+		plugin.MustExtract(&bar)
+		var foo *ditest.Foo
+		host.MustExtract(&foo)
+		plugin.MustEqualPointer(foo, bar.Foo())
+	})
+
+	t.Run("fallbacks are consulted in order", func(t *testing.T) {
+		first := NewTestContainer(t)
+		first.MustProvide(ditest.NewFoo)
+		first.MustCompile()
+		second := NewTestContainer(t)
+		second.MustProvide(ditest.NewFoo)
+		second.MustCompile()
+		plugin := NewTestContainer(t)
+		plugin.SetFallbacks(first.Container, second.Container)
+		plugin.MustCompile()
+		var foo, expected *ditest.Foo
+		plugin.MustExtract(&foo)
+		first.MustExtract(&expected)
+		plugin.MustEqualPointer(expected, foo)
+	})
+
+	t.Run("not found error counts consulted fallbacks", func(t *testing.T) {
+		first := NewTestContainer(t)
+		first.MustCompile()
+		second := NewTestContainer(t)
+		second.MustCompile()
+		plugin := NewTestContainer(t)
+		plugin.SetFallbacks(first.Container, second.Container)
+		plugin.MustCompile()
+		var foo *ditest.Foo
+		err := plugin.Extract(&foo)
+		require.EqualError(t, err, "*ditest.Foo: not exists in container and its 2 fallback containers")
+		var notFound di.ErrParameterProviderNotFound
+		require.True(t, errors.As(err, &notFound))
+		require.Equal(t, 2, notFound.Fallbacks())
+	})
+
+	t.Run("edges of fallback container do not form cycle", func(t *testing.T) {
+		host := NewTestContainer(t)
+		host.MustProvide(ditest.NewFoo)
+		host.MustProvide(ditest.NewBar)
+		host.MustCompile()
+		plugin := NewTestContainer(t)
+		plugin.MustProvide(func(bar *ditest.Bar) *ditest.Foo { return &ditest.Foo{} })
+		plugin.SetFallbacks(host.Container)
+		plugin.MustCompile()
+		var foo *ditest.Foo
+		plugin.MustExtract(&foo)
+	})
+
+	t.Run("not compiled fallback causes panic", func(t *testing.T) {
+		host := NewTestContainer(t)
+		plugin := NewTestContainer(t)
+		require.PanicsWithValue(t, "fallback container not compiled", func() {
+			plugin.SetFallbacks(host.Container)
+		})
+	})
+}
+
+func TestContainerPrepare(t *testing.T) {
+	t.Run("prepare does not create instances", func(t *testing.T) {
+		type Parameters struct {
+			di.Parameter
+			Foo *ditest.Foo `di:""`
+			Bar *ditest.Bar `di:",optional"`
+		}
+		type Server struct {
+			Foo *ditest.Foo `di:""`
+		}
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(func(params Parameters) *ditest.Baz { return &ditest.Baz{} })
+		c.ProvideStruct(&Server{})
+		c.MustCompile()
+		require.NoError(t, c.Prepare())
+		require.NoError(t, c.Prepare())
+		for _, info := range c.Definitions() {
+			require.False(t, info.Created, info.Type)
+		}
+		var server *Server
+		c.MustExtract(&server)
+		var foo *ditest.Foo
+		c.MustExtract(&foo)
+		require.Same(t, foo, server.Foo)
+		var baz *ditest.Baz
+		c.MustExtract(&baz)
+		require.NoError(t, c.BuildAll())
+	})
+
+	t.Run("prepare of not compiled container fails", func(t *testing.T) {
+		c := NewTestContainer(t)
+		require.EqualError(t, c.Prepare(), "container not compiled")
+	})
+}
+
+func TestContainerFingerprint(t *testing.T) {
+	type provider struct {
+		ctor   interface{}
+		params di.ProvideParams
+	}
+	fingerprint := func(providers ...provider) string {
+		c := NewTestContainer(t)
+		for _, p := range providers {
+			c.Provide(p.ctor, p.params)
+		}
+		c.MustCompile()
+		return c.Fingerprint()
+	}
+	fooer := []interface{}{new(ditest.Fooer)}
+
+	t.Run("provide order does not change fingerprint", func(t *testing.T) {
+		foo := provider{ctor: ditest.NewFoo}
+		bar := provider{ctor: ditest.NewBar, params: di.ProvideParams{Interfaces: fooer, IsPrimary: true}}
+		baz := provider{ctor: ditest.NewBaz, params: di.ProvideParams{Interfaces: fooer, IsPrototype: true}}
+		qux := provider{ctor: ditest.NewQux}
+		first := fingerprint(foo, bar, baz, qux)
+		require.Len(t, first, 64)
+		require.Equal(t, first, fingerprint(qux, baz, bar, foo))
+	})
+
+	t.Run("chosen implementation changes fingerprint", func(t *testing.T) {
+		foo := provider{ctor: ditest.NewFoo}
+		qux := provider{ctor: ditest.NewQux}
+		bar := provider{ctor: ditest.NewBar, params: di.ProvideParams{Interfaces: fooer}}
+		baz := provider{ctor: ditest.NewBaz, params: di.ProvideParams{Interfaces: fooer}}
+		primaryBar, primaryBaz := bar, baz
+		primaryBar.params.IsPrimary = true
+		primaryBaz.params.IsPrimary = true
+		require.NotEqual(t, fingerprint(foo, primaryBar, baz, qux), fingerprint(foo, bar, primaryBaz, qux))
+	})
+
+	t.Run("order of group dependency depends on provide order without order markers", func(t *testing.T) {
+		foo := provider{ctor: ditest.NewFoo}
+		bar := provider{ctor: ditest.NewBar, params: di.ProvideParams{Interfaces: fooer}}
+		baz := provider{ctor: ditest.NewBaz, params: di.ProvideParams{Interfaces: fooer}}
+		group := provider{ctor: ditest.NewFooerGroup}
+		require.Equal(t, fingerprint(foo, bar, baz), fingerprint(foo, baz, bar))
+		require.NotEqual(t, fingerprint(foo, bar, baz, group), fingerprint(foo, baz, bar, group))
+		bar.params.Order, baz.params.Order = 1, 2
+		require.Equal(t, fingerprint(foo, bar, baz, group), fingerprint(foo, baz, bar, group))
+	})
+
+	t.Run("fingerprint of not compiled container is empty", func(t *testing.T) {
+		require.Empty(t, NewTestContainer(t).Fingerprint())
+	})
+}
+
+func TestContainerTypeNames(t *testing.T) {
+	const (
+		first  = "github.com/defval/inject/v2/di/internal/ditest/config.Config"
+		second = "github.com/defval/inject/v2/di/internal/ditest/other/config.Config"
+	)
+
+	t.Run("types with the same name from different packages are rendered with package paths", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.ProvideValue(&config.Config{Name: "first"})
+		c.MustCompile()
+		var cfg *otherconfig.Config
+		c.MustExtractError(&cfg, "*"+second+": not exists in container")
+		require.PanicsWithValue(t, "The `*"+first+"` type already exists in container", func() {
+			c := NewTestContainer(t)
+			c.ProvideValue(&config.Config{})
+			c.ProvideValue(&config.Config{})
+		})
+	})
+
+	t.Run("types with the same name from different packages are different graph nodes", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.ProvideValue(&config.Config{Name: "first"})
+		c.ProvideValue(&otherconfig.Config{Name: "second"})
+		c.MustProvide(func(first *config.Config, second *otherconfig.Config) *ditest.Foo { return ditest.NewFoo() })
+		c.MustCompile()
+		graph := c.Graph().String()
+		require.Contains(t, graph, fmt.Sprintf("label=%q", "*"+first))
+		require.Contains(t, graph, fmt.Sprintf("label=%q", "*"+second))
+	})
+
+	t.Run("qualified type names render all types with package paths", func(t *testing.T) {
+		defer di.SetQualifiedTypeNames(false)
+		di.SetQualifiedTypeNames(true)
+		c := NewTestContainer(t)
+		c.MustCompile()
+		var foo []*ditest.Foo
+		c.MustExtractError(&foo, "[]*github.com/defval/inject/v2/di/internal/ditest.Foo: not exists in container, slice is not provided, provide the slice or extract a named group into it")
+	})
+}
+
+func TestContainerLifetime(t *testing.T) {
+	singleton, prototype := di.Singleton, di.Prototype
+	for _, container := range []*di.Lifetime{nil, &singleton, &prototype} {
+		for _, module := range []*di.Lifetime{nil, &singleton, &prototype} {
+			for _, option := range []*di.Lifetime{nil, &singleton, &prototype} {
+				expected, source := di.Singleton, di.LifetimeFromContainer
+				if container != nil {
+					expected = *container
+				}
+				if module != nil {
+					expected, source = *module, di.LifetimeFromModule
+				}
+				if option != nil {
+					expected, source = *option, di.LifetimeFromProvide
+				}
+				describe := func(lifetime *di.Lifetime) string {
+					if lifetime == nil {
+						return "unset"
+					}
+					return lifetime.String()
+				}
+				name := fmt.Sprintf("container %s, module %s, option %s", describe(container), describe(module), describe(option))
+				t.Run(name, func(t *testing.T) {
+					c := NewTestContainer(t)
+					if container != nil {
+						c.SetDefaultLifetime(*container)
+					}
+					params := di.ProvideParams{ModuleLifetime: module}
+					if option != nil {
+						params.IsPrototype = *option == di.Prototype
+						params.IsSingleton = *option == di.Singleton
+					}
+					c.Provide(ditest.NewFoo, params)
+					c.MustCompile()
+					info := c.Definitions()[0]
+					require.Equal(t, expected, info.Lifetime)
+					require.Equal(t, source, info.LifetimeSource)
+					var first, second *ditest.Foo
+					c.MustExtract(&first)
+					c.MustExtract(&second)
+					require.Equal(t, expected == di.Singleton, first == second)
+				})
+			}
+		}
+	}
+
+	t.Run("provided values are singletons", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.SetDefaultLifetime(di.Prototype)
+		c.ProvideValue(&ditest.Foo{}, di.ProvideParams{ModuleLifetime: &prototype})
+		c.MustCompile()
+		info := c.Definitions()[0]
+		require.Equal(t, di.Singleton, info.Lifetime)
+		require.Equal(t, di.LifetimeFromProvide, info.LifetimeSource)
+	})
+
+	t.Run("singleton and prototype options cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		require.PanicsWithValue(t, location(ditest.NewFoo)+": *ditest.Foo could not be singleton and prototype", func() {
+			c.Provide(ditest.NewFoo, di.ProvideParams{IsPrototype: true, IsSingleton: true})
+		})
+	})
+}
+
+func TestContainerProvideValueCleanup(t *testing.T) {
+	t.Run("value cleanup runs in order of creation like constructor cleanups", func(t *testing.T) {
+		c := NewTestContainer(t)
+		var cleanupCalls []string
+		c.ProvideValue(&ditest.Bar{}, di.ProvideParams{
+			Interfaces: []interface{}{new(ditest.Fooer)},
+			Cleanup:    func() { cleanupCalls = append(cleanupCalls, "bar") },
+		})
+		c.MustProvide(func(fooer ditest.Fooer) (*ditest.Foo, func()) {
+			return &ditest.Foo{}, func() { cleanupCalls = append(cleanupCalls, "foo") }
+		})
+		c.MustCompile()
+
+		var foo *ditest.Foo
+		c.MustExtract(&foo)
+		var bar *ditest.Bar
+		c.MustExtract(&bar)
+		c.Cleanup()
+		require.Equal(t, []string{"bar", "foo"}, cleanupCalls)
+	})
+
+	t.Run("cleanup of unresolved value does not run", func(t *testing.T) {
+		c := NewTestContainer(t)
+		var cleanupCalled bool
+		c.ProvideValue(&ditest.Bar{}, di.ProvideParams{Cleanup: func() { cleanupCalled = true }})
+		c.MustCompile()
+		c.Cleanup()
+		require.False(t, cleanupCalled)
+	})
+
+	t.Run("nil pointer bound to interfaces cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		require.PanicsWithValue(t, "config.go:12: *ditest.Bar: nil pointer could not be bound to interfaces", func() {
+			c.ProvideValue((*ditest.Bar)(nil), di.ProvideParams{
+				Interfaces: []interface{}{new(ditest.Fooer)},
+				Location:   di.Location{File: "config.go", Line: 12},
+			})
+		})
+	})
+
+	t.Run("cleanup of constructor cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		require.PanicsWithValue(t, location(ditest.NewFoo)+": *ditest.Foo: cleanup option is supported by values only, return cleanup from the constructor", func() {
+			c.Provide(ditest.NewFoo, di.ProvideParams{Cleanup: func() {}})
+		})
+	})
+}
+
+func TestContainerStats(t *testing.T) {
+	t.Run("stats count definitions, built instances and resolutions", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.Provide(ditest.NewBar, di.ProvideParams{IsPrototype: true})
+		c.ProvideValue(&ditest.Baz{})
+		c.MustCompile()
+		var bar *ditest.Bar
+		c.MustExtract(&bar)
+		c.MustExtract(&bar)
+		var baz *ditest.Baz
+		c.MustExtract(&baz)
+		var qux *ditest.Qux
+		c.MustExtractError(&qux, "*ditest.Qux: not exists in container")
+		stats := c.Stats()
+		require.True(t, stats.ConstructionTime >= stats.MaxConstructionTime)
+		stats.ConstructionTime, stats.MaxConstructionTime = 0, 0
+		require.Equal(t, di.Stats{
+			Definitions: 3,
+			Singletons:  2,
+			Prototypes:  1,
+			Built:       3,
+			Resolutions: 4,
+			Failures:    1,
+		}, stats)
+	})
+
+	t.Run("snapshot is consistent under concurrent resolutions", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.Provide(ditest.NewFoo, di.ProvideParams{IsPrototype: true})
+		c.MustCompile()
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					var foo *ditest.Foo
+					_ = c.Extract(&foo)
+				}
+			}()
+		}
+		for i := 0; i < 100; i++ {
+			// instance is built before its resolution is counted
+			stats := c.Stats()
+			require.True(t, stats.Built >= stats.Resolutions)
+		}
+		wg.Wait()
+		require.Equal(t, uint64(1000), c.Stats().Resolutions)
+	})
+
+	t.Run("snapshot returns under steady resolution traffic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.Provide(ditest.NewFoo, di.ProvideParams{IsPrototype: true})
+		c.MustCompile()
+		stop := make(chan struct{})
+		var wg sync.WaitGroup
+		for i := 0; i < 8; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for {
+					select {
+					case <-stop:
+						return
+					default:
+					}
+					var foo *ditest.Foo
+					_ = c.Extract(&foo)
+				}
+			}()
+		}
+		defer wg.Wait()
+		defer close(stop)
+		done := make(chan di.Stats)
+		go func() {
+			for i := 0; i < 100; i++ {
+				c.Stats()
+			}
+			done <- c.Stats()
+		}()
+		select {
+		case stats := <-done:
+			require.True(t, stats.Built >= stats.Resolutions)
+		case <-time.After(5 * time.Second):
+			t.Fatal("Stats() did not return under concurrent resolutions")
+		}
+	})
+}
+
+func TestContainerReleaseInstances(t *testing.T) {
+	t.Run("released singleton is created again", func(t *testing.T) {
+		c := NewTestContainer(t)
+		var cleanups int
+		c.MustProvide(ditest.CreateFooConstructorWithCleanup(func() { cleanups++ }))
+		c.MustCompile()
+		var first *ditest.Foo
+		c.MustExtract(&first)
+		require.EqualError(t, c.ReleaseInstances(di.ReleaseParams{}), "instances could not be released before Cleanup()")
+		c.Cleanup()
+		require.NoError(t, c.ReleaseInstances(di.ReleaseParams{}))
+		require.True(t, c.Definitions()[0].Created)
+
+		var second *ditest.Foo
+		c.MustExtract(&second)
+		require.True(t, first != second)
+		c.Cleanup()
+		require.Equal(t, 2, cleanups)
+	})
+
+	t.Run("resolution after release with rejection fails", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewBar)
+		c.ProvideValue(&ditest.Baz{})
+		c.MustCompile()
+		var bar *ditest.Bar
+		c.MustExtract(&bar)
+		c.Cleanup()
+		require.NoError(t, c.ReleaseInstances(di.ReleaseParams{IsRejectResolution: true}))
+
+		err := c.Extract(&bar)
+		require.True(t, errors.Is(err, di.ErrReleased))
+		require.Equal(t, []string{"*ditest.Bar", "*ditest.Foo"}, di.DependencyPath(err))
+		var baz *ditest.Baz
+		c.MustExtract(&baz)
+	})
+
+	t.Run("release of not compiled container fails", func(t *testing.T) {
+		c := NewTestContainer(t)
+		require.EqualError(t, c.ReleaseInstances(di.ReleaseParams{}), "container not compiled")
+	})
+}
+
+func TestContainerContextFirst(t *testing.T) {
+	type ctxKey struct{}
+
+	t.Run("context is not a dependency and is background on resolution", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		var received context.Context
+		c.MustProvide(func(ctx context.Context, foo *ditest.Foo) *ditest.Bar {
+			received = ctx
+			return ditest.NewBar(foo)
+		})
+		c.MustCompile()
+		for _, info := range c.Definitions() {
+			if info.Key.String() == "*ditest.Bar" {
+				require.Equal(t, "[*ditest.Foo]", fmt.Sprint(info.Dependencies))
+			}
+		}
+		var bar *ditest.Bar
+		c.MustExtract(&bar)
+		require.Equal(t, context.Background(), received)
+	})
+
+	t.Run("constructors built by BuildContext receive its context", func(t *testing.T) {
+		c := NewTestContainer(t)
+		var received context.Context
+		c.MustProvide(func(ctx context.Context) (*ditest.Foo, error) {
+			received = ctx
+			return &ditest.Foo{}, nil
+		})
+		c.MustCompile()
+		ctx := context.WithValue(context.Background(), ctxKey{}, "build")
+		require.NoError(t, c.BuildContext(ctx))
+		require.Equal(t, "build", received.Value(ctxKey{}))
+	})
+
+	t.Run("context in other position cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		ctor := func(foo *ditest.Foo, ctx context.Context) *ditest.Bar { return ditest.NewBar(foo) }
+		require.PanicsWithValue(t, location(ctor)+": invalid constructor `func(*ditest.Foo, context.Context) *ditest.Bar`: "+
+			"context.Context is parameter 2, the context must be the first parameter like "+
+			"`func(ctx context.Context, [dep1, dep2, ...]) (<result>, [cleanup, error])`", func() {
+			c.Provide(ctor)
+		})
+	})
+}
+
+func TestContainerDevMode(t *testing.T) {
+	t.Run("resolution from provider on the same goroutine is reported", func(t *testing.T) {
+		c := NewTestContainer(t)
+		var log bytes.Buffer
+		c.SetDevMode(&log)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(func(resolver di.Resolver) (*ditest.Bar, error) {
+			var foo *ditest.Foo
+			if err := resolver.Extract(&foo); err != nil {
+				return nil, err
+			}
+			return ditest.NewBar(foo), nil
+		})
+		c.MustCompile()
+		var bar *ditest.Bar
+		c.MustExtract(&bar)
+		require.Contains(t, log.String(), "dev mode: resolution of *ditest.Foo re-entered from provider of *ditest.Bar on the same goroutine")
+		require.Contains(t, log.String(), "provider call:\n")
+		require.Contains(t, log.String(), "resolution:\n")
+	})
+
+	t.Run("resolution during compile returns error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.SetDevMode(io.Discard)
+		c.MustProvide(ditest.NewFoo)
+		var err error
+		c.SetCompileLog(writerFunc(func(p []byte) (int, error) {
+			var foo *ditest.Foo
+			err = c.Extract(&foo)
+			return len(p), nil
+		}))
+		c.MustCompile()
+		require.Equal(t, di.ErrCompiling, err)
+	})
+
+	t.Run("change of cached singleton is reported once", func(t *testing.T) {
+		type Config struct {
+			Addr string
+		}
+		c := NewTestContainer(t)
+		var log bytes.Buffer
+		c.SetDevMode(&log)
+		c.MustProvide(func() *Config { return &Config{Addr: ":80"} })
+		c.MustCompile()
+		var config *Config
+		c.MustExtract(&config)
+		c.MustExtract(&config)
+		require.Empty(t, log.String())
+		config.Addr = ":8080"
+		c.MustExtract(&config)
+		config.Addr = ":8081"
+		c.MustExtract(&config)
+		require.Equal(t, "dev mode: *di_test.Config changed after it was cached, singleton is shared by all dependents\n", log.String())
+	})
+}
+
+func TestContainerExtractTargetKinds(t *testing.T) {
+	type unexported struct {
+		foo *ditest.Foo
+	}
+	var (
+		nilMap     map[string]int
+		fn         func() int
+		ch         chan int
+		unexp      unexported
+		nilPointer *ditest.Foo
+	)
+	c := NewTestContainer(t)
+	c.MustProvide(ditest.NewFoo)
+	c.MustCompile()
+	for _, tt := range []struct {
+		name   string
+		target interface{}
+		err    string
+	}{
+		{
+			name:   "nil interface",
+			target: nil,
+			err:    "extract target must be a non-nil pointer, got `nil`, pass address of variable like &target",
+		},
+		{
+			name:   "typed nil pointer",
+			target: nilPointer,
+			err:    "extract target must be a non-nil pointer, got nil `*ditest.Foo`, pass address of variable like &target",
+		},
+		{
+			name:   "non-pointer",
+			target: ditest.Foo{},
+			err:    "extract target must be a non-nil pointer, got struct `ditest.Foo`, pass address of variable like &target",
+		},
+		{
+			name:   "map",
+			target: nilMap,
+			err:    "extract target must be a non-nil pointer, got map `map[string]int`, pass address of variable like &target, the map is replaced by extracted one",
+		},
+		{
+			name:   "func",
+			target: fn,
+			err:    "extract target must be a non-nil pointer, got func `func() int`, use Invoke() to call function with dependencies",
+		},
+		{
+			name:   "pointer to nil map",
+			target: &nilMap,
+			err:    "map[string]int: not exists in container, map is not provided, provide the map or extract a named group into it",
+		},
+		{
+			name:   "pointer to func",
+			target: &fn,
+			err:    "func() int: not exists in container, func is not provided, provide the function as value or use a factory like `func([context.Context]) (<type>, error)`",
+		},
+		{
+			name:   "pointer to channel",
+			target: &ch,
+			err:    "chan int: not exists in container, channel is not provided, provide the channel as value",
+		},
+		{
+			name:   "pointer to struct with unexported fields only",
+			target: &unexp,
+			err:    "di_test.unexported: not exists in container, struct without exported fields is not provided, provide the struct or embed di.Parameter and export fields to fill them from the container",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c.MustExtractError(tt.target, tt.err)
+		})
+	}
+}
+
+func TestContainerLate(t *testing.T) {
+	t.Run("late definition is built after ordinary ones", func(t *testing.T) {
+		var built []string
+		c := NewTestContainer(t)
+		newMux := func() *http.ServeMux {
+			built = append(built, "mux")
+			return &http.ServeMux{}
+		}
+		newServer := func(mux *http.ServeMux) *http.Server {
+			built = append(built, "server")
+			return &http.Server{Handler: mux}
+		}
+		newClient := func() *http.Client {
+			built = append(built, "client")
+			return &http.Client{}
+		}
+		c.Provide(newServer, di.ProvideParams{IsLate: true})
+		c.MustProvide(newMux)
+		c.MustProvide(newClient)
+		c.MustCompile()
+
+		require.NoError(t, c.BuildAll())
+		require.Equal(t, "server", built[len(built)-1])
+		var server *http.Server
+		c.MustExtract(&server)
+	})
+
+	t.Run("late definition depends on late one", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.Provide(func() *http.ServeMux { return &http.ServeMux{} }, di.ProvideParams{IsLate: true})
+		c.Provide(func(mux *http.ServeMux) *http.Server { return &http.Server{Handler: mux} }, di.ProvideParams{IsLate: true})
+		c.MustCompile()
+
+		var server *http.Server
+		c.MustExtract(&server)
+	})
+
+	t.Run("ordinary definition depending on late one cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.Provide(ditest.NewBar, di.ProvideParams{IsLate: true, Interfaces: []interface{}{new(ditest.Fooer)}})
+		c.MustProvide(ditest.NewQux)
+		require.PanicsWithValue(t, fmt.Sprintf("%s: *ditest.Qux depends on *ditest.Bar provided late at %s, "+
+			"only late definitions could depend on it", location(ditest.NewQux), location(ditest.NewBar)), func() {
+			c.Compile()
+		})
+	})
+
+	t.Run("provided value could not be late", func(t *testing.T) {
+		c := NewTestContainer(t)
+		require.Panics(t, func() {
+			c.ProvideValue(&http.ServeMux{}, di.ProvideParams{IsLate: true})
+		})
+	})
+}
+
+func TestContainerDeprecated(t *testing.T) {
+	t.Run("first resolution writes warning with dependent", func(t *testing.T) {
+		c := NewTestContainer(t)
+		var b strings.Builder
+		c.SetDeprecationLog(&b)
+		c.Provide(ditest.NewFoo, di.ProvideParams{Deprecated: "use NewFooV2"})
+		c.MustProvide(ditest.NewBar)
+		c.MustProvidePrototype(ditest.NewBaz)
+		c.MustCompile()
+		require.Empty(t, b.String())
+
+		var baz1, baz2 *ditest.Baz
+		c.MustExtract(&baz1)
+		c.MustExtract(&baz2)
+		lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+		require.Len(t, lines, 1)
+		require.Contains(t, lines[0], "foo.go:")
+		require.True(t, strings.HasSuffix(lines[0], ": *ditest.Foo is deprecated: use NewFooV2, required by *ditest.Baz"))
+		require.Equal(t, "use NewFooV2", c.Definitions()[0].Deprecated)
+		require.Contains(t, c.Graph().String(), "*ditest.Foo (deprecated)")
+	})
+
+	t.Run("resolved directly", func(t *testing.T) {
+		c := NewTestContainer(t)
+		var b strings.Builder
+		c.SetDeprecationLog(&b)
+		c.ProvideValue(&ditest.Foo{}, di.ProvideParams{Deprecated: "use NewFooV2"})
+		c.MustCompile()
+
+		var foo *ditest.Foo
+		c.MustExtract(&foo)
+		require.Equal(t, "*ditest.Foo is deprecated: use NewFooV2, resolved directly\n", b.String())
+	})
+
+	t.Run("strict mode returns error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.SetStrictDeprecation()
+		c.Provide(ditest.NewFoo, di.ProvideParams{Deprecated: "use NewFooV2"})
+		c.MustProvide(ditest.NewBar)
+		c.MustCompile()
+
+		var bar *ditest.Bar
+		err := c.Extract(&bar)
+		require.EqualError(t, err, "*ditest.Foo: deprecated: use NewFooV2")
+		require.True(t, errors.As(err, new(di.ErrDeprecated)))
+		require.Equal(t, []string{"*ditest.Bar", "*ditest.Foo"}, di.DependencyPath(err))
+	})
+
+	t.Run("fresh resolution that bypasses index is checked", func(t *testing.T) {
+		c := NewTestContainer(t)
+		var b strings.Builder
+		c.SetDeprecationLog(&b)
+		c.Provide(ditest.NewFoo, di.ProvideParams{Deprecated: "use NewFooV2"})
+		c.MustCompile()
+
+		var foo *ditest.Foo
+		require.NoError(t, c.Extract(&foo, di.ExtractParams{IsFresh: true}))
+		require.Equal(t, location(ditest.NewFoo)+": *ditest.Foo is deprecated: use NewFooV2, resolved directly\n", b.String())
+
+		strict := NewTestContainer(t)
+		strict.SetStrictDeprecation()
+		strict.Provide(ditest.NewFoo, di.ProvideParams{Deprecated: "use NewFooV2"})
+		strict.MustCompile()
+		err := strict.Extract(&foo, di.ExtractParams{IsFresh: true})
+		require.True(t, errors.As(err, new(di.ErrDeprecated)))
+	})
+}
+
+func TestContainerDescription(t *testing.T) {
+	t.Run("description is shown by diagnostics", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.Provide(ditest.NewFoo, di.ProvideParams{Description: "the only foo"})
+		c.MustProvide(ditest.NewBar)
+		c.MustCompile()
+		require.Equal(t, "the only foo", c.Definitions()[0].Description)
+		require.Contains(t, c.Graph().String(), `label="*ditest.Foo\nthe only foo"`)
+
+		var b strings.Builder
+		var bar *ditest.Bar
+		require.NoError(t, c.Explain(&bar, &b))
+		require.Equal(t, "*ditest.Bar [singleton, not built]\n  *ditest.Foo (the only foo) [singleton, not built]\n", b.String())
+	})
+
+	t.Run("ambiguity error lists candidates with description", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.Provide(ditest.NewBar, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}, Description: "bar of foo"})
+		c.MustProvide(ditest.NewBaz, new(ditest.Fooer))
+		c.MustCompile()
+
+		var fooer ditest.Fooer
+		c.MustExtractError(&fooer, "ditest.Fooer: have several implementations: *ditest.Bar (bar of foo), *ditest.Baz")
+	})
+}
+
+func TestContainerProvideStruct(t *testing.T) {
+	type Handler struct {
+		Foo  *ditest.Foo `di:""`
+		Bar  *ditest.Bar `di:"optional"`
+		Name string
+	}
+
+	t.Run("provided instance is shared", func(t *testing.T) {
+		c := NewTestContainer(t)
+		handler := &Handler{Name: "handler"}
+		c.MustProvide(ditest.NewFoo)
+		c.ProvideStruct(handler)
+		c.MustCompile()
+
+		var extracted1, extracted2 *Handler
+		c.MustExtract(&extracted1)
+		c.MustExtract(&extracted2)
+		c.MustEqualPointer(handler, extracted1)
+		c.MustEqualPointer(handler, extracted2)
+		require.NotNil(t, handler.Foo)
+		require.Nil(t, handler.Bar)
+		require.Equal(t, di.Singleton, c.Definitions()[1].Lifetime)
+	})
+
+	t.Run("provided type is allocated on each resolution", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.ProvideType(&Handler{Name: "handler"})
+		c.MustCompile()
+
+		var extracted1, extracted2 *Handler
+		c.MustExtract(&extracted1)
+		c.MustExtract(&extracted2)
+		c.MustNotEqualPointer(extracted1, extracted2)
+		c.MustEqualPointer(extracted1.Foo, extracted2.Foo)
+		require.Equal(t, "handler", extracted1.Name)
+		info := c.Definitions()[1]
+		require.Equal(t, di.Prototype, info.Lifetime)
+		require.True(t, info.Created)
+	})
+
+	t.Run("provided struct type is a value", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.ProvideType(Handler{}, di.ProvideParams{Name: "handler"})
+		c.MustCompile()
+
+		var handler Handler
+		c.MustExtractWithName("handler", &handler)
+		require.NotNil(t, handler.Foo)
+	})
+
+	t.Run("provided struct value is a template of singleton", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.ProvideStruct(Handler{Name: "handler"})
+		c.MustCompile()
+
+		var extracted1, extracted2 Handler
+		c.MustExtract(&extracted1)
+		c.MustExtract(&extracted2)
+		c.MustEqualPointer(extracted1.Foo, extracted2.Foo)
+		require.Equal(t, "handler", extracted1.Name)
+		require.Equal(t, di.Singleton, c.Definitions()[1].Lifetime)
+	})
+
+	t.Run("struct provider of constructed type is reported", func(t *testing.T) {
+		c := NewTestContainer(t)
+		var b strings.Builder
+		c.SetCompileLog(&b)
+		c.Provide(ditest.NewFoo, di.ProvideParams{Name: "default"})
+		c.ProvideStruct(&ditest.Foo{})
+		c.MustCompile()
+
+		warning := fmt.Sprintf("struct provider shadows constructor at %s, fields are set without calling it",
+			location(ditest.NewFoo))
+		var findings []di.Finding
+		for _, finding := range c.Report().Findings {
+			if finding.Kind == di.FindingShadowed {
+				findings = append(findings, finding)
+			}
+		}
+		require.Equal(t, []di.Finding{{
+			Kind:     di.FindingShadowed,
+			Severity: di.SeverityWarning,
+			Key:      "*ditest.Foo",
+			Message:  warning,
+		}}, findings)
+		require.Contains(t, b.String(), "warnings:\n  *ditest.Foo: "+warning+"\n")
+	})
+
+	t.Run("replacement of constructor by struct provider is reported", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.ProvideStruct(&ditest.Foo{}, di.ProvideParams{IsReplacement: true})
+		c.MustCompile()
+
+		var messages []string
+		for _, finding := range c.Report().Findings {
+			if finding.Kind == di.FindingShadowed {
+				messages = append(messages, finding.Message)
+			}
+		}
+		require.Equal(t, []string{fmt.Sprintf("struct provider shadows constructor at %s, fields are set without calling it",
+			location(ditest.NewFoo))}, messages)
+	})
+
+	t.Run("provided instance could not be prototype", func(t *testing.T) {
+		c := NewTestContainer(t)
+		require.Panics(t, func() {
+			c.ProvideStruct(&Handler{}, di.ProvideParams{IsPrototype: true})
+		})
+	})
+
+	t.Run("provided instance must be a pointer to struct", func(t *testing.T) {
+		c := NewTestContainer(t)
+		require.PanicsWithValue(t, "The value must be a pointer to struct, got `string`", func() {
+			c.ProvideStruct("handler")
+		})
+		require.PanicsWithValue(t, "The value must be a struct or a pointer to struct, got `string`", func() {
+			c.ProvideType("handler")
+		})
+	})
+}
+
+func TestContainerProvideValue(t *testing.T) {
+	t.Run("value resolves as definition", func(t *testing.T) {
+		c := NewTestContainer(t)
+		foo := &ditest.Foo{}
+		c.ProvideValue(foo)
+		c.MustProvide(ditest.NewBar)
+		c.MustCompile()
+
+		var bar *ditest.Bar
+		c.MustExtract(&bar)
+		c.MustEqualPointer(foo, bar.Foo())
+	})
+
+	t.Run("nil value cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		require.PanicsWithValue(t, "The value must not be nil, use typed value instead", func() {
+			c.ProvideValue(nil)
+		})
+	})
+
+	t.Run("duplicate value cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.ProvideValue("addr", di.ProvideParams{Name: "addr"})
+		require.PanicsWithValue(t, "The `string[addr]` type already exists in container", func() {
+			c.ProvideValue("addr", di.ProvideParams{Name: "addr"})
+		})
+	})
+}
+
+func TestContainerTags(t *testing.T) {
+	provideTagged := func(c *TestContainer, foo *ditest.Foo, tags di.Tags) {
+		c.Provide(ditest.CreateFooConstructor(foo), di.ProvideParams{Tags: tags})
+	}
+
+	t.Run("definitions of same type with different tags", func(t *testing.T) {
+		c := NewTestContainer(t)
+		premium, basic := &ditest.Foo{}, &ditest.Foo{}
+		provideTagged(c, premium, di.Tags{"tier": "premium"})
+		provideTagged(c, basic, di.Tags{"tier": "basic"})
+		c.MustCompile()
+
+		var extracted *ditest.Foo
+		require.NoError(t, c.Extract(&extracted, di.ExtractParams{Tags: di.Tags{"tier": "premium"}}))
+		c.MustEqualPointer(premium, extracted)
+		require.NoError(t, c.Extract(&extracted, di.ExtractParams{Tags: di.Tags{"tier": "basic"}}))
+		c.MustEqualPointer(basic, extracted)
+	})
+
+	t.Run("tags with separators in values do not collide", func(t *testing.T) {
+		c := NewTestContainer(t)
+		joined, split := &ditest.Foo{}, &ditest.Foo{}
+		provideTagged(c, joined, di.Tags{"a": "1,b=2"})
+		provideTagged(c, split, di.Tags{"a": "1", "b": "2"})
+		c.MustCompile()
+
+		var extracted *ditest.Foo
+		require.NoError(t, c.Extract(&extracted, di.ExtractParams{Tags: di.Tags{"a": "1,b=2"}}))
+		c.MustEqualPointer(joined, extracted)
+		require.NoError(t, c.Extract(&extracted, di.ExtractParams{Tags: di.Tags{"a": "1", "b": "2"}}))
+		c.MustEqualPointer(split, extracted)
+	})
+
+	t.Run("requested tags intersected", func(t *testing.T) {
+		c := NewTestContainer(t)
+		eu, us := &ditest.Foo{}, &ditest.Foo{}
+		provideTagged(c, eu, di.Tags{"tier": "premium", "region": "eu"})
+		provideTagged(c, us, di.Tags{"tier": "premium", "region": "us"})
+		c.MustCompile()
+
+		var extracted *ditest.Foo
+		require.NoError(t, c.Extract(&extracted, di.ExtractParams{Tags: di.Tags{"tier": "premium", "region": "us"}}))
+		c.MustEqualPointer(us, extracted)
+		require.EqualError(t, c.Extract(&extracted, di.ExtractParams{Tags: di.Tags{"tier": "premium"}}),
+			"*ditest.Foo{tier=premium}: have several implementations: "+
+				"*ditest.Foo{region=eu,tier=premium}, *ditest.Foo{region=us,tier=premium}")
+	})
+
+	t.Run("exact tags have priority", func(t *testing.T) {
+		c := NewTestContainer(t)
+		exact, superset := &ditest.Foo{}, &ditest.Foo{}
+		provideTagged(c, superset, di.Tags{"tier": "premium", "region": "eu"})
+		provideTagged(c, exact, di.Tags{"tier": "premium"})
+		c.MustCompile()
+
+		var extracted *ditest.Foo
+		require.NoError(t, c.Extract(&extracted, di.ExtractParams{Tags: di.Tags{"tier": "premium"}}))
+		c.MustEqualPointer(exact, extracted)
+	})
+
+	t.Run("not matched tags error names eliminating tag", func(t *testing.T) {
+		c := NewTestContainer(t)
+		provideTagged(c, &ditest.Foo{}, di.Tags{"tier": "premium"})
+		provideTagged(c, &ditest.Foo{}, di.Tags{"tier": "basic", "region": "eu"})
+		c.MustCompile()
+
+		var extracted *ditest.Foo
+		require.EqualError(t, c.Extract(&extracted, di.ExtractParams{Tags: di.Tags{"tier": "free"}}),
+			"*ditest.Foo{tier=free}: not exists in container, "+
+				"2 definitions of *ditest.Foo but none have tag tier=free; available: tier=basic, tier=premium")
+	})
+
+	t.Run("single tagged definition resolves as parameter", func(t *testing.T) {
+		c := NewTestContainer(t)
+		foo := &ditest.Foo{}
+		provideTagged(c, foo, di.Tags{"tier": "premium"})
+		c.MustProvide(ditest.NewBar)
+		c.MustCompile()
+
+		var bar *ditest.Bar
+		c.MustExtract(&bar)
+		c.MustEqualPointer(foo, bar.Foo())
+	})
+
+	t.Run("primary marker resolves tag ambiguity", func(t *testing.T) {
+		c := NewTestContainer(t)
+		foo := &ditest.Foo{}
+		provideTagged(c, &ditest.Foo{}, di.Tags{"tier": "premium", "region": "eu"})
+		c.Provide(ditest.CreateFooConstructor(foo), di.ProvideParams{Tags: di.Tags{"tier": "premium", "region": "us"}, IsPrimary: true})
+		c.MustCompile()
+
+		var extracted *ditest.Foo
+		require.NoError(t, c.Extract(&extracted, di.ExtractParams{Tags: di.Tags{"tier": "premium"}}))
+		c.MustEqualPointer(foo, extracted)
+	})
+
+	t.Run("interface, name and tags select implementation", func(t *testing.T) {
+		c := NewTestContainer(t)
+		eu, us := &ditest.Bar{}, &ditest.Bar{}
+		for bar, region := range map[*ditest.Bar]string{eu: "eu", us: "us"} {
+			bar := bar
+			c.Provide(func() *ditest.Bar { return bar }, di.ProvideParams{
+				Name:       "sessions",
+				Tags:       di.Tags{"region": region},
+				Interfaces: []interface{}{new(ditest.Fooer)},
+			})
+		}
+		c.MustCompile()
+
+		var fooer ditest.Fooer
+		require.NoError(t, c.Extract(&fooer, di.ExtractParams{Name: "sessions", Tags: di.Tags{"region": "eu"}}))
+		c.MustEqualPointer(eu, fooer)
+		require.EqualError(t, c.Extract(&fooer, di.ExtractParams{Name: "sessions", Tags: di.Tags{"region": "ap"}}),
+			"ditest.Fooer[sessions]{region=ap}: not exists in container, "+
+				"2 implementations of ditest.Fooer matched name=sessions but none have tag region=ap; available: region=eu, region=us")
+	})
+}
+
+func TestContainerBind(t *testing.T) {
+	t.Run("bound interface resolves as definition", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewBar)
+		c.Bind(new(ditest.Fooer), new(ditest.Bar))
+		c.MustCompile()
+
+		var bar *ditest.Bar
+		c.MustExtract(&bar)
+		var fooer ditest.Fooer
+		c.MustExtractPtr(bar, &fooer)
+		var group []ditest.Fooer
+		c.MustExtract(&group)
+		require.Len(t, group, 1)
+	})
+
+	t.Run("named binding names interface key", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewBar)
+		c.Bind(new(ditest.Fooer), new(ditest.Bar), di.BindParams{Name: "primary"})
+		c.MustCompile()
+
+		var bar *ditest.Bar
+		c.MustExtract(&bar)
+		var fooer ditest.Fooer
+		c.MustExtractWithName("primary", &fooer)
+		c.MustEqualPointer(bar, fooer)
+		c.MustExtractError(&fooer, "ditest.Fooer: not exists in container, available names: `primary`")
+		keys := c.Keys()
+		require.Equal(t, "ditest.Fooer[primary]", keys[2].String())
+		require.True(t, keys[2].IsAlias())
+	})
+
+	t.Run("bind to not existing type cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		require.PanicsWithValue(t, "Bind to *ditest.Bar: type not exists in container", func() {
+			c.Bind(new(ditest.Fooer), new(ditest.Bar))
+		})
+	})
+
+	t.Run("bind not implemented interface cause panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		require.PanicsWithValue(t, "*ditest.Foo not implement ditest.Fooer", func() {
+			c.Bind(new(ditest.Fooer), new(ditest.Foo))
+		})
+	})
+}
+
+func TestContainerInherit(t *testing.T) {
+	t.Run("derived container shares singletons not depending on replacements", func(t *testing.T) {
+		parent := NewTestContainer(t)
+		parent.MustProvide(ditest.NewFoo)
+		parent.MustProvide(ditest.NewBar, new(ditest.Fooer))
+		parent.MustProvide(ditest.NewQux)
+		parent.MustCompile()
+
+		foo := &ditest.Foo{}
+		derived := NewTestContainer(t)
+		derived.MustProvide(ditest.NewFoo)
+		derived.MustProvide(ditest.NewBar, new(ditest.Fooer))
+		derived.MustProvide(ditest.NewQux)
+		derived.Provide(ditest.CreateFooConstructor(foo), di.ProvideParams{IsReplacement: true})
+		derived.MustCompile()
+		derived.Inherit(parent.Container)
+
+		var parentQux, derivedQux *ditest.Qux
+		parent.MustExtract(&parentQux)
+		derived.MustExtract(&derivedQux)
+		derived.MustNotEqualPointer(parentQux, derivedQux)
+		derived.MustEqualPointer(foo, derivedQux.Fooer().Foo())
+	})
+
+	t.Run("cleanup of shared singleton belongs to parent", func(t *testing.T) {
+		var cleanupCalled bool
+		parent := NewTestContainer(t)
+		parent.MustProvide(ditest.CreateFooConstructorWithCleanup(func() { cleanupCalled = true }))
+		parent.MustCompile()
+
+		derived := NewTestContainer(t)
+		derived.MustProvide(ditest.CreateFooConstructorWithCleanup(func() {}))
+		derived.MustCompile()
+		derived.Inherit(parent.Container)
+
+		var derivedFoo, parentFoo *ditest.Foo
+		derived.MustExtract(&derivedFoo)
+		parent.MustExtractPtr(derivedFoo, &parentFoo)
+
+		derived.Cleanup()
+		require.False(t, cleanupCalled)
+		parent.Cleanup()
+		require.True(t, cleanupCalled)
+	})
+
+	t.Run("interactor not inherited", func(t *testing.T) {
+		parent := NewTestContainer(t)
+		parent.MustCompile()
+		derived := NewTestContainer(t)
+		derived.MustCompile()
+		derived.Inherit(parent.Container)
+
+		var interactor di.Interactor
+		derived.MustExtractPtr(derived.Container, &interactor)
+	})
+}
+
+func TestContainerSingletonInvariant(t *testing.T) {
+	type Buffer bytes.Buffer
+
+	t.Run("every key path resolves the same instance created once", func(t *testing.T) {
+		c := NewTestContainer(t)
+		var calls int32
+		c.Provide(func() *bytes.Buffer {
+			atomic.AddInt32(&calls, 1)
+			return &bytes.Buffer{}
+		}, di.ProvideParams{Interfaces: []interface{}{new(io.ReadWriter)}})
+		c.Bind(new(io.Writer), &bytes.Buffer{})
+		c.SetAutoConvert()
+		c.MustCompile()
+
+		targets := []func() (interface{}, error){
+			func() (interface{}, error) {
+				var buf *bytes.Buffer
+				err := c.Extract(&buf)
+				return buf, err
+			},
+			func() (interface{}, error) {
+				var rw io.ReadWriter
+				err := c.Extract(&rw)
+				return rw, err
+			},
+			func() (interface{}, error) {
+				var w io.Writer
+				err := c.Extract(&w)
+				return w, err
+			},
+			func() (interface{}, error) {
+				var r io.Reader
+				err := c.Extract(&r)
+				return r, err
+			},
+			func() (interface{}, error) {
+				var group []io.ReadWriter
+				err := c.Extract(&group)
+				if err != nil {
+					return nil, err
+				}
+				return group[0], nil
+			},
+			func() (interface{}, error) {
+				var buf *Buffer
+				err := c.Extract(&buf)
+				return (*bytes.Buffer)(buf), err
+			},
+		}
+		instances := make([]interface{}, len(targets))
+		errs := make([]error, len(targets))
+		var wg sync.WaitGroup
+		for i, target := range targets {
+			wg.Add(1)
+			go func(i int, target func() (interface{}, error)) {
+				defer wg.Done()
+				instances[i], errs[i] = target()
+			}(i, target)
+		}
+		wg.Wait()
+		for i, instance := range instances {
+			require.NoError(t, errs[i])
+			require.Same(t, instances[0], instance)
+		}
+		require.Equal(t, int32(1), calls)
+	})
+
+	t.Run("names of replacement resolve the same instance created once", func(t *testing.T) {
+		c := NewTestContainer(t)
+		var original, replacement int
+		c.Provide(func() *bytes.Buffer {
+			original++
+			return &bytes.Buffer{}
+		}, di.ProvideParams{Name: "internal", Names: []string{"external"}, Interfaces: []interface{}{new(io.Writer)}})
+		c.Provide(func() *bytes.Buffer {
+			replacement++
+			return &bytes.Buffer{}
+		}, di.ProvideParams{Name: "internal", IsReplacement: true})
+		c.MustCompile()
+
+		var internal, external *bytes.Buffer
+		var w io.Writer
+		require.NoError(t, c.Extract(&internal, di.ExtractParams{Name: "internal"}))
+		require.NoError(t, c.Extract(&external, di.ExtractParams{Name: "external"}))
+		require.NoError(t, c.Extract(&w, di.ExtractParams{Name: "internal"}))
+		require.Same(t, internal, external)
+		require.Same(t, internal, w)
+		require.Equal(t, 0, original)
+		require.Equal(t, 1, replacement)
+	})
+
+	t.Run("derived container shares the instance on every key path", func(t *testing.T) {
+		var calls int
+		newBuffer := func() *bytes.Buffer {
+			calls++
+			return &bytes.Buffer{}
+		}
+		parent := NewTestContainer(t)
+		parent.MustProvide(newBuffer, new(io.ReadWriter))
+		parent.MustProvide(ditest.NewFoo)
+		parent.MustCompile()
+
+		derived := NewTestContainer(t)
+		derived.MustProvide(newBuffer, new(io.ReadWriter))
+		derived.MustProvide(ditest.NewFoo)
+		derived.Provide(ditest.NewFoo, di.ProvideParams{IsReplacement: true})
+		derived.MustCompile()
+		derived.Inherit(parent.Container)
+
+		var parentBuf, derivedBuf *bytes.Buffer
+		var rw io.ReadWriter
+		parent.MustExtract(&parentBuf)
+		derived.MustExtract(&derivedBuf)
+		derived.MustExtract(&rw)
+		require.Same(t, parentBuf, derivedBuf)
+		require.Same(t, parentBuf, rw)
+		require.Equal(t, 1, calls)
+	})
+}
+
+func TestDependencyPath(t *testing.T) {
+	t.Run("path of failed dependency", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.CreateFooConstructorWithError(errors.New("internal error")))
+		c.MustProvide(ditest.NewBar, new(ditest.Fooer))
+		c.MustProvide(ditest.NewQux)
+		c.MustCompile()
+
+		var qux *ditest.Qux
+		err := c.Extract(&qux)
+		require.Equal(t, []string{"*ditest.Qux", "ditest.Fooer", "*ditest.Bar", "*ditest.Foo"}, di.DependencyPath(err))
+	})
+
+	t.Run("path of not existing type", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustCompile()
+
+		var foo *ditest.Foo
+		err := c.Extract(&foo)
+		require.Equal(t, []string{"*ditest.Foo"}, di.DependencyPath(err))
+	})
+
+	t.Run("no path for other errors", func(t *testing.T) {
+		require.Nil(t, di.DependencyPath(errors.New("other")))
+	})
+}
+
+func TestContainerMaxDepth(t *testing.T) {
+	t.Run("too deep resolution returns error with ends of chain", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.SetMaxDepth(50)
+		typ := provideChain(c.Container, 60, di.ProvideParams{})
+		c.MustCompile()
+
+		err := c.Extract(reflect.New(typ).Interface())
+		require.True(t, errors.As(err, new(di.ErrResolutionTooDeep)))
+		var head, tail []string
+		for i := 60; i > 40; i-- {
+			head = append(head, fmt.Sprintf("*[%d]int", i))
+		}
+		for i := 29; i >= 10; i-- {
+			tail = append(tail, fmt.Sprintf("*[%d]int", i))
+		}
+		require.EqualError(t, err, fmt.Sprintf("resolution depth exceeds limit of 50: %s -> ... 11 types ... -> %s",
+			strings.Join(head, " -> "), strings.Join(tail, " -> ")))
+		require.Len(t, di.DependencyPath(err), 51)
+	})
+
+	t.Run("chain within limit resolves", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.SetMaxDepth(60)
+		typ := provideChain(c.Container, 60, di.ProvideParams{})
+		c.MustCompile()
+
+		require.NoError(t, c.Extract(reflect.New(typ).Interface()))
+	})
+
+	t.Run("max depth must be positive", func(t *testing.T) {
+		c := NewTestContainer(t)
+		require.PanicsWithValue(t, "The max depth must be positive, got 0", func() {
+			c.SetMaxDepth(0)
+		})
+	})
+}
+
+func TestContainerResolveOrder(t *testing.T) {
+	t.Run("group sorted by order marker", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.Provide(ditest.NewBar, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}, Order: 2})
+		c.Provide(ditest.NewBaz, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}, Order: 1})
+		c.MustCompile()
+
+		var bar *ditest.Bar
+		c.MustExtract(&bar)
+		var baz *ditest.Baz
+		c.MustExtract(&baz)
+
+		var group []ditest.Fooer
+		c.MustExtract(&group)
+		require.Len(t, group, 2)
+		c.MustEqualPointer(baz, group[0])
+		c.MustEqualPointer(bar, group[1])
+	})
+
+	t.Run("group members with equal order keep registration order", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewBaz, new(ditest.Fooer))
+		c.MustProvide(ditest.NewBar, new(ditest.Fooer))
+		c.MustCompile()
+
+		var baz *ditest.Baz
+		c.MustExtract(&baz)
+
+		var group []ditest.Fooer
+		c.MustExtract(&group)
+		require.Len(t, group, 2)
+		c.MustEqualPointer(baz, group[0])
+	})
+
+	t.Run("primary implementation resolves as interface", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewBar, new(ditest.Fooer))
+		c.Provide(ditest.NewBaz, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}, IsPrimary: true})
+		c.MustCompile()
+
+		var baz *ditest.Baz
+		c.MustExtract(&baz)
+
+		var fooer ditest.Fooer
+		c.MustExtractPtr(baz, &fooer)
+	})
+
+	t.Run("several primary implementations cause error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.Provide(ditest.NewBar, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}, IsPrimary: true})
+		c.Provide(ditest.NewBaz, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}, IsPrimary: true})
+		c.MustCompile()
+
+		var fooer ditest.Fooer
+		c.MustExtractError(&fooer, "ditest.Fooer: have several primary implementations: *ditest.Bar, *ditest.Baz")
+	})
+
+	t.Run("definitions numbered in registration order", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.Provide(ditest.NewBar, di.ProvideParams{Order: 3, IsPrimary: true})
+
+		defs := c.Definitions()
+		require.Len(t, defs, 2)
+		require.Equal(t, reflect.TypeOf(&ditest.Foo{}), defs[0].Type)
+		require.Equal(t, 1, defs[0].Sequence)
+		require.Equal(t, reflect.TypeOf(&ditest.Bar{}), defs[1].Type)
+		require.Equal(t, 2, defs[1].Sequence)
+		require.Equal(t, 3, defs[1].Order)
+		require.True(t, defs[1].Primary)
+	})
+}
+
+func TestContainerDefinitionInfo(t *testing.T) {
+	t.Run("definition info describes key, lifetime and dependencies", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.Provide(ditest.NewBar, di.ProvideParams{
+			Name:        "bar",
+			Interfaces:  []interface{}{new(ditest.Fooer)},
+			IsPrototype: true,
+		})
+
+		defs := c.Definitions()
+		require.Len(t, defs, 2)
+		require.Equal(t, reflect.TypeOf(&ditest.Foo{}), defs[0].Key.Type())
+		require.Equal(t, "", defs[0].Key.Name())
+		require.Equal(t, di.Singleton, defs[0].Lifetime)
+		require.Empty(t, defs[0].Dependencies)
+		require.Equal(t, reflect.TypeOf(&ditest.Bar{}), defs[1].Key.Type())
+		require.Equal(t, "bar", defs[1].Key.Name())
+		require.Equal(t, di.Prototype, defs[1].Lifetime)
+		require.Equal(t, []reflect.Type{reflect.TypeOf(new(ditest.Fooer)).Elem()}, defs[1].Implements)
+		require.Len(t, defs[1].Dependencies, 1)
+		require.Equal(t, reflect.TypeOf(&ditest.Foo{}), defs[1].Dependencies[0].Type())
+	})
+
+	t.Run("definition info contains constructor location", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+
+		loc := c.Definitions()[0].Location
+		require.True(t, strings.HasSuffix(loc.File, "ditest/foo.go"))
+		require.NotZero(t, loc.Line)
+		require.Equal(t, "github.com/defval/inject/v2/di/internal/ditest.NewFoo", loc.Function)
+	})
+
+	t.Run("definition info reports creation after extract", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustCompile()
+
+		require.False(t, c.Definitions()[0].Created)
+
+		before := time.Now()
+		var foo *ditest.Foo
+		c.MustExtract(&foo)
+
+		info := c.Definitions()[0]
+		require.True(t, info.Created)
+		require.False(t, info.CreatedAt.Before(before))
+		require.True(t, info.Duration >= 0)
+	})
+
+	t.Run("definition info of failed constructor is not created", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(func() (*ditest.Foo, error) { return nil, errors.New("boom") })
+		c.MustCompile()
+
+		var foo *ditest.Foo
+		require.Error(t, c.Extract(&foo))
+		require.False(t, c.Definitions()[0].Created)
+	})
+
+	t.Run("definition info returns copy of implemented interfaces", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.Provide(ditest.NewBar, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}})
+
+		c.Definitions()[1].Implements[0] = nil
+		require.NotNil(t, c.Definitions()[1].Implements[0])
+	})
+}
+
+func TestContainerNamedGroups(t *testing.T) {
+	type FooerParameters struct {
+		di.Parameter
+		Fooers []ditest.Fooer `group:"fooers"`
+	}
+
+	t.Run("named group contains members in registration order", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.Provide(ditest.NewBar, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}, Groups: []string{"fooers"}})
+		c.Provide(ditest.NewBaz, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}, Groups: []string{"fooers"}})
+		c.Provide(func(params FooerParameters) *ditest.FooerGroup {
+			return ditest.NewFooerGroup(params.Fooers)
+		})
+		c.MustCompile()
+
+		var group *ditest.FooerGroup
+		c.MustExtract(&group)
+		require.Len(t, group.Fooers(), 2)
+		var bar *ditest.Bar
+		c.MustExtract(&bar)
+		c.MustEqualPointer(bar, group.Fooers()[0])
+
+		var fooers []ditest.Fooer
+		require.NoError(t, c.Extract(&fooers, di.ExtractParams{Group: "fooers"}))
+		require.Len(t, fooers, 2)
+		require.Equal(t, []string{"fooers"}, c.Definitions()[1].Groups)
+	})
+
+	t.Run("named group of result type", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.Provide(ditest.NewFoo, di.ProvideParams{Groups: []string{"foos"}})
+		c.Provide(ditest.NewFoo, di.ProvideParams{Name: "second", Groups: []string{"foos"}})
+		c.MustCompile()
+
+		var foos []*ditest.Foo
+		require.NoError(t, c.Extract(&foos, di.ExtractParams{Group: "foos"}))
+		require.Len(t, foos, 2)
+	})
+
+	t.Run("named group members created lazily", func(t *testing.T) {
+		c := NewTestContainer(t)
+		var created bool
+		c.Provide(func() *ditest.Foo { created = true; return ditest.NewFoo() }, di.ProvideParams{Groups: []string{"foos"}})
+		c.MustCompile()
+		require.False(t, created)
+
+		var foos []*ditest.Foo
+		require.NoError(t, c.Extract(&foos, di.ExtractParams{Group: "foos"}))
+		require.True(t, created)
+	})
+
+	t.Run("empty group resolves as empty slice", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.Provide(func(params FooerParameters) *ditest.FooerGroup {
+			return ditest.NewFooerGroup(params.Fooers)
+		})
+		c.MustCompile()
+
+		var group *ditest.FooerGroup
+		c.MustExtract(&group)
+		require.NotNil(t, group.Fooers())
+		require.Len(t, group.Fooers(), 0)
+	})
+
+	t.Run("named group member cycle detected", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.Provide(func(params FooerParameters) *ditest.Foo { return ditest.NewFoo() })
+		c.Provide(ditest.NewBar, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}, Groups: []string{"fooers"}})
+		require.Panics(t, c.Compile)
+	})
+
+	t.Run("extract group into not slice causes error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustCompile()
+
+		var foo *ditest.Foo
+		require.EqualError(t, c.Extract(&foo, di.ExtractParams{Group: "foos"}),
+			"extract target of group must be a pointer to slice or map with string keys, got `**ditest.Foo`")
+	})
+
+	t.Run("group field must be a slice", func(t *testing.T) {
+		type Parameters struct {
+			di.Parameter
+			Foo *ditest.Foo `group:"foos"`
+		}
+		c := NewTestContainer(t)
+		c.Provide(func(params Parameters) *ditest.Bar { return ditest.NewBar(params.Foo) })
+		require.PanicsWithValue(t, "di_test.Parameters: group field Foo must be a slice or a map with string keys, got `*ditest.Foo`", c.Compile)
+	})
+}
+
+func TestContainerNamedMapGroups(t *testing.T) {
+	type FooerParameters struct {
+		di.Parameter
+		Fooers map[string]ditest.Fooer `group:"fooers"`
+	}
+
+	t.Run("map group contains named members", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.Provide(ditest.NewBar, di.ProvideParams{Name: "bar", Interfaces: []interface{}{new(ditest.Fooer)}, Groups: []string{"fooers"}})
+		c.Provide(ditest.NewBaz, di.ProvideParams{Name: "baz", Interfaces: []interface{}{new(ditest.Fooer)}, Groups: []string{"fooers"}})
+		c.Provide(ditest.NewBar, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}, Groups: []string{"fooers"}})
+		c.Provide(func(params FooerParameters) *ditest.Qux { return ditest.NewQux(params.Fooers["baz"]) })
+		c.MustCompile()
+
+		var fooers map[string]ditest.Fooer
+		require.NoError(t, c.Extract(&fooers, di.ExtractParams{Group: "fooers"}))
+		require.Len(t, fooers, 2)
+		var bar *ditest.Bar
+		c.MustExtractWithName("bar", &bar)
+		c.MustEqualPointer(bar, fooers["bar"])
+
+		var qux *ditest.Qux
+		c.MustExtract(&qux)
+	})
+
+	t.Run("map group creates only members", func(t *testing.T) {
+		c := NewTestContainer(t)
+		var created []string
+		c.Provide(func() *ditest.Foo { created = append(created, "member"); return ditest.NewFoo() },
+			di.ProvideParams{Name: "member", Groups: []string{"foos"}})
+		c.Provide(func() *ditest.Foo { created = append(created, "other"); return ditest.NewFoo() },
+			di.ProvideParams{Name: "other"})
+		c.MustCompile()
+
+		var foos map[string]*ditest.Foo
+		require.NoError(t, c.Extract(&foos, di.ExtractParams{Group: "foos"}))
+		require.Len(t, foos, 1)
+		require.Equal(t, []string{"member"}, created)
+	})
+
+	t.Run("empty map group resolves as empty map", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustCompile()
+
+		var foos map[string]*ditest.Foo
+		require.NoError(t, c.Extract(&foos, di.ExtractParams{Group: "foos"}))
+		require.NotNil(t, foos)
+		require.Len(t, foos, 0)
+	})
+
+	t.Run("duplicate member names cause compile error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewBar)
+		c.Provide(ditest.NewBar, di.ProvideParams{Name: "same", Interfaces: []interface{}{new(ditest.Fooer)}, Groups: []string{"fooers"}})
+		c.Provide(ditest.NewBaz, di.ProvideParams{Name: "same", Interfaces: []interface{}{new(ditest.Fooer)}, Groups: []string{"fooers"}})
+		c.Provide(func(params FooerParameters) *ditest.Qux { return ditest.NewQux(params.Fooers["same"]) })
+		c.MustCompileError(fmt.Sprintf("map[string]ditest.Fooer[fooers]: name `same` provided by *ditest.Bar at %s and *ditest.Baz at %s",
+			location(ditest.NewBar), location(ditest.NewBaz)))
+	})
+
+	t.Run("duplicate member names cause extract error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewBar)
+		c.Provide(ditest.NewBar, di.ProvideParams{Name: "same", Interfaces: []interface{}{new(ditest.Fooer)}, Groups: []string{"fooers"}})
+		c.Provide(ditest.NewBaz, di.ProvideParams{Name: "same", Interfaces: []interface{}{new(ditest.Fooer)}, Groups: []string{"fooers"}})
+		c.MustCompile()
+
+		var fooers map[string]ditest.Fooer
+		require.EqualError(t, c.Extract(&fooers, di.ExtractParams{Group: "fooers"}), fmt.Sprintf(
+			"map[string]ditest.Fooer[fooers]: name `same` provided by *ditest.Bar at %s and *ditest.Baz at %s",
+			location(ditest.NewBar), location(ditest.NewBaz)))
+	})
+}
+
+func TestContainerExclusive(t *testing.T) {
+	t.Run("exclusive definition resolves as interface only", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.Provide(ditest.NewBar, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}, IsExclusive: true})
+		c.MustProvide(ditest.NewQux)
+		c.MustCompile()
+
+		var qux *ditest.Qux
+		c.MustExtract(&qux)
+		var fooers []ditest.Fooer
+		c.MustExtract(&fooers)
+		require.Len(t, fooers, 1)
+
+		var bar *ditest.Bar
+		c.MustExtractError(&bar, "*ditest.Bar: not exists in container, definition provided exclusively as ditest.Fooer")
+		require.True(t, c.Definitions()[1].Exclusive)
+	})
+
+	t.Run("dependency on exclusive definition type causes compile error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.Provide(ditest.NewBar, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}, IsExclusive: true})
+		c.MustProvide(ditest.NewBaz)
+		c.MustCompileError("*ditest.Baz: dependency *ditest.Bar not exists in container")
+	})
+
+	t.Run("exclusive definition with tags resolves as interface only", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.Provide(ditest.NewBar, di.ProvideParams{
+			Interfaces:  []interface{}{new(ditest.Fooer)},
+			Tags:        di.Tags{"tier": "premium"},
+			IsExclusive: true,
+		})
+		c.MustCompile()
+
+		var fooer ditest.Fooer
+		c.MustExtract(&fooer)
+		var bar *ditest.Bar
+		require.Error(t, c.Extract(&bar, di.ExtractParams{Tags: di.Tags{"tier": "premium"}}))
+	})
+
+	t.Run("exclusive definition without interfaces causes panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		require.PanicsWithValue(t, fmt.Sprintf("%s: *ditest.Foo exclusive definition requires interfaces, use As() provide option", location(ditest.NewFoo)), func() {
+			c.Provide(ditest.NewFoo, di.ProvideParams{IsExclusive: true})
+		})
+	})
+}
+
+func TestContainerKeys(t *testing.T) {
+	t.Run("keys returned in registration order with interface aliases", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.Provide(ditest.NewBar, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}})
+		c.Provide(ditest.NewBaz, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}})
+
+		keys := c.Keys()
+		require.Len(t, keys, 4)
+		require.Equal(t, reflect.TypeOf(&ditest.Foo{}), keys[0].Type())
+		require.Equal(t, reflect.TypeOf(&ditest.Bar{}), keys[1].Type())
+		require.Equal(t, reflect.TypeOf(new(ditest.Fooer)).Elem(), keys[2].Type())
+		require.True(t, keys[2].IsAlias())
+		require.Equal(t, reflect.TypeOf(&ditest.Baz{}), keys[3].Type())
+		require.False(t, keys[3].IsAlias())
+	})
+
+	t.Run("keys contain definition name", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.Provide(ditest.NewFoo, di.ProvideParams{Name: "foo"})
+
+		require.Equal(t, "foo", c.Keys()[0].Name())
+		require.Equal(t, "*ditest.Foo[foo]", c.Keys()[0].String())
+	})
+
+	t.Run("keys are copy", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewBar)
+
+		keys := c.Keys()
+		keys[0], keys[1] = keys[1], keys[0]
+		require.Equal(t, reflect.TypeOf(&ditest.Foo{}), c.Keys()[0].Type())
+	})
+}
+
+func TestContainerDump(t *testing.T) {
+	t.Run("string represents definitions as table", func(t *testing.T) {
 		c := NewTestContainer(t)
-		foo := &ditest.Foo{}
-		c.MustProvide(ditest.CreateFooConstructor(foo))
+		c.MustProvide(ditest.NewFoo)
+		c.Provide(ditest.NewBar, di.ProvideParams{IsPrototype: true})
+		c.MustProvide(ditest.NewBaz)
+		c.Provide(ditest.NewFoo, di.ProvideParams{Name: "foo"})
 		c.MustCompile()
 
-		var extracted *ditest.Foo
-		c.MustExtractPtr(foo, &extracted)
+		var foo *ditest.Foo
+		c.MustExtract(&foo)
+
+		require.Equal(t, ""+
+			"TYPE           NAME  LIFETIME   DEPS  BUILT\n"+
+			"*ditest.Foo    -     singleton  0     yes\n"+
+			"*ditest.Bar    -     prototype  1     no\n"+
+			"*ditest.Baz    -     singleton  2     no\n"+
+			"*ditest.Foo    foo   singleton  0     no\n"+
+			"*di.Graph      -     singleton  0     no\n"+
+			"di.Interactor  -     singleton  0     no\n"+
+			"di.Resolver    -     singleton  0     no\n",
+			c.String())
 	})
 
-	t.Run("container extract same pointer on each extraction", func(t *testing.T) {
+	t.Run("string truncates long type names", func(t *testing.T) {
+		type VeryLongTypeNameThatDoesNotFitIntoTheColumnOfDefinitionTable struct{}
 		c := NewTestContainer(t)
-		foo := &ditest.Foo{}
-		c.MustProvide(ditest.CreateFooConstructor(foo))
-		c.MustCompile()
-
-		var extracted1 *ditest.Foo
-		c.MustExtractPtr(foo, &extracted1)
+		c.MustProvide(func() *VeryLongTypeNameThatDoesNotFitIntoTheColumnOfDefinitionTable {
+			return &VeryLongTypeNameThatDoesNotFitIntoTheColumnOfDefinitionTable{}
+		})
 
-		var extracted2 *ditest.Foo
-		c.MustExtractPtr(foo, &extracted2)
+		require.Contains(t, c.String(), "*di_test.VeryLongTypeNameThatDoesNotFitIntoTheColumnOfDef...  -")
 	})
 
-	t.Run("container extract instance if error is nil", func(t *testing.T) {
+	t.Run("debug dump writes dependency tree", func(t *testing.T) {
 		c := NewTestContainer(t)
-		c.MustProvide(ditest.CreateFooConstructorWithError(nil))
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(ditest.NewBar)
+		c.Provide(ditest.NewBaz, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}})
+		c.MustProvide(ditest.NewQux)
 		c.MustCompile()
 
-		var extracted *ditest.Foo
-		c.MustExtract(&extracted)
+		var bar *ditest.Bar
+		c.MustExtract(&bar)
+
+		var b bytes.Buffer
+		require.NoError(t, c.DebugDump(&b, di.DumpParams{}))
+		require.Equal(t, ""+
+			"*ditest.Qux [singleton, not built]\n"+
+			"  ditest.Fooer [interface]\n"+
+			"    *ditest.Baz [singleton, not built]\n"+
+			"      *ditest.Foo [singleton, built]\n"+
+			"      *ditest.Bar [singleton, built]\n"+
+			"        *ditest.Foo [singleton, built]\n"+
+			"*di.Graph [singleton, not built]\n"+
+			"di.Interactor [singleton, not built]\n"+
+			"di.Resolver [singleton, not built]\n",
+			b.String())
 	})
 
-	t.Run("container extract instance if cleanup and error is nil", func(t *testing.T) {
+	t.Run("verbose debug dump writes full type names", func(t *testing.T) {
+		type VeryLongTypeNameThatDoesNotFitIntoTheColumnOfDefinitionTable struct{}
 		c := NewTestContainer(t)
+		c.MustProvide(func() *VeryLongTypeNameThatDoesNotFitIntoTheColumnOfDefinitionTable {
+			return &VeryLongTypeNameThatDoesNotFitIntoTheColumnOfDefinitionTable{}
+		})
 
-		c.MustProvide(ditest.CreateFooConstructorWithCleanupAndError(nil, nil))
-		c.MustCompile()
-
-		var extracted *ditest.Foo
-		c.MustExtract(&extracted)
+		var b bytes.Buffer
+		require.NoError(t, c.DebugDump(&b, di.DumpParams{IsVerbose: true}))
+		require.Equal(t, "*di_test.VeryLongTypeNameThatDoesNotFitIntoTheColumnOfDefinitionTable [singleton, not built]\n", b.String())
 	})
 
-	t.Run("container extract correct named pointer", func(t *testing.T) {
+	t.Run("debug dump marks not existing dependencies", func(t *testing.T) {
 		c := NewTestContainer(t)
-		foo := &ditest.Foo{}
-		c.MustProvideWithName("foo", ditest.CreateFooConstructor(foo))
-		c.MustCompile()
+		c.MustProvide(ditest.NewBar)
 
-		var extracted *ditest.Foo
-		c.MustExtractWithName("foo", &extracted)
+		var b bytes.Buffer
+		require.NoError(t, c.DebugDump(&b, di.DumpParams{}))
+		require.Equal(t, ""+
+			"*ditest.Bar [singleton, not built]\n"+
+			"  *ditest.Foo (not exists)\n",
+			b.String())
 	})
+}
 
-	t.Run("container extract correct interface implementation", func(t *testing.T) {
+func TestContainerExplain(t *testing.T) {
+	t.Run("explain writes dependency tree of target", func(t *testing.T) {
 		c := NewTestContainer(t)
-		bar := &ditest.Bar{}
 		c.MustProvide(ditest.NewFoo)
-		c.MustProvide(ditest.CreateBarConstructor(bar), new(ditest.Fooer))
+		c.Provide(ditest.NewBar, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}})
+		c.MustProvide(ditest.NewQux)
 		c.MustCompile()
 
-		var extracted ditest.Fooer
-		c.MustExtractPtr(bar, &extracted)
+		var foo *ditest.Foo
+		c.MustExtract(&foo)
+
+		var b bytes.Buffer
+		var qux *ditest.Qux
+		require.NoError(t, c.Explain(&qux, &b))
+		require.Equal(t, ""+
+			"*ditest.Qux [singleton, not built]\n"+
+			"  ditest.Fooer [interface] resolved as *ditest.Bar: the only implementation\n"+
+			"    *ditest.Bar [singleton, not built]\n"+
+			"      *ditest.Foo [singleton, built]\n",
+			b.String())
+		require.NoError(t, c.Extract(&foo))
 	})
 
-	t.Run("container creates group from interface and extract it", func(t *testing.T) {
+	t.Run("explain describes primary implementation", func(t *testing.T) {
 		c := NewTestContainer(t)
 		c.MustProvide(ditest.NewFoo)
-		c.MustProvide(ditest.NewBar, new(ditest.Fooer))
-		c.MustProvide(ditest.NewBaz, new(ditest.Fooer))
+		c.Provide(ditest.NewBar, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}, IsPrimary: true})
+		c.Provide(ditest.NewBaz, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}})
 		c.MustCompile()
 
-		var group []ditest.Fooer
-		c.MustExtract(&group)
-		require.Len(t, group, 2)
+		var b bytes.Buffer
+		var fooer ditest.Fooer
+		require.NoError(t, c.Explain(&fooer, &b))
+		require.Equal(t, ""+
+			"ditest.Fooer [interface] resolved as *ditest.Bar: primary of 2 implementations\n"+
+			"  *ditest.Bar [singleton, not built]\n"+
+			"    *ditest.Foo [singleton, not built]\n",
+			b.String())
 	})
 
-	t.Run("container extract new instance of prototype by each extraction", func(t *testing.T) {
+	t.Run("explain writes partial tree of failed resolution", func(t *testing.T) {
 		c := NewTestContainer(t)
 		c.MustProvide(ditest.NewFoo)
-		c.MustProvidePrototype(ditest.NewBar)
+		c.Provide(ditest.NewBar, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}})
+		c.Provide(ditest.NewBaz, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}})
+		c.MustProvide(ditest.NewQux)
 		c.MustCompile()
 
-		var extracted1 *ditest.Bar
-		c.MustExtract(&extracted1)
-		var extracted2 *ditest.Bar
-		c.MustExtract(&extracted2)
-
-		c.MustNotEqualPointer(extracted1, extracted2)
+		var b bytes.Buffer
+		var qux *ditest.Qux
+		err := c.Explain(&qux, &b)
+		require.EqualError(t, err, "ditest.Fooer: have several implementations: *ditest.Bar, *ditest.Baz")
+		require.Equal(t, []string{"*ditest.Qux", "ditest.Fooer"}, di.DependencyPath(err))
+		require.Equal(t, ""+
+			"*ditest.Qux [singleton, not built]\n"+
+			"  ditest.Fooer [interface]: have several implementations: *ditest.Bar, *ditest.Baz\n",
+			b.String())
+		require.EqualError(t, c.Extract(&qux), err.Error())
 	})
 
-	t.Run("container resolve interactor", func(t *testing.T) {
+	t.Run("explain reports not existing target", func(t *testing.T) {
 		c := NewTestContainer(t)
-		foo := ditest.NewFoo()
-		c.MustProvide(ditest.CreateFooConstructor(foo))
 		c.MustCompile()
-		var interactor di.Interactor
-		c.MustExtract(&interactor)
-		var extractedFoo *ditest.Foo
-		require.NoError(t, interactor.Extract(&extractedFoo))
-		c.MustEqualPointer(foo, extractedFoo)
+
+		var b bytes.Buffer
+		var foo *ditest.Foo
+		require.EqualError(t, c.Explain(&foo, &b), "*ditest.Foo: not exists in container")
+		require.Equal(t, "*ditest.Foo (not exists)\n", b.String())
 	})
-}
 
-func TestContainerResolve(t *testing.T) {
-	t.Run("container resolve correct argument", func(t *testing.T) {
+	t.Run("explain describes tags match", func(t *testing.T) {
 		c := NewTestContainer(t)
-		foo := &ditest.Foo{}
-		c.MustProvide(ditest.CreateFooConstructor(foo))
-		c.MustProvide(ditest.NewBar)
+		c.Provide(ditest.NewFoo, di.ProvideParams{Tags: di.Tags{"tier": "premium", "region": "eu"}})
 		c.MustCompile()
 
-		var bar *ditest.Bar
-		c.MustExtract(&bar)
-		c.MustEqualPointer(foo, bar.Foo())
+		var b bytes.Buffer
+		var foo *ditest.Foo
+		require.NoError(t, c.Explain(&foo, &b, di.ExtractParams{Tags: di.Tags{"tier": "premium"}}))
+		require.Equal(t, "*ditest.Foo{region=eu,tier=premium} [singleton, not built] matched by tags {tier=premium}\n", b.String())
 	})
 
-	t.Run("container resolve correct interface implementation", func(t *testing.T) {
+	t.Run("explain reports ambiguous interface", func(t *testing.T) {
 		c := NewTestContainer(t)
-
-		foo := ditest.NewFoo()
-		bar := ditest.NewBar(foo)
-
-		c.MustProvide(ditest.CreateFooConstructor(foo))
-		c.MustProvide(ditest.CreateBarConstructor(bar), new(ditest.Fooer))
-		c.MustProvide(ditest.NewQux)
+		c.MustProvide(ditest.NewFoo)
+		c.Provide(ditest.NewBar, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}})
+		c.Provide(ditest.NewBaz, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}})
 		c.MustCompile()
 
-		var qux *ditest.Qux
-		c.MustExtract(&qux)
-		c.MustEqualPointer(bar, qux.Fooer())
+		var b bytes.Buffer
+		var fooer ditest.Fooer
+		require.EqualError(t, c.Explain(&fooer, &b), "ditest.Fooer: have several implementations: *ditest.Bar, *ditest.Baz")
+		require.Equal(t, "ditest.Fooer [interface]: have several implementations: *ditest.Bar, *ditest.Baz\n", b.String())
 	})
 
-	t.Run("container resolve correct group", func(t *testing.T) {
+	t.Run("explain target must be a pointer", func(t *testing.T) {
 		c := NewTestContainer(t)
-
-		c.MustProvide(ditest.NewFoo)
-		c.MustProvide(ditest.NewBar, new(ditest.Fooer))
-		c.MustProvide(ditest.NewBaz, new(ditest.Fooer))
-		c.MustProvide(ditest.NewFooerGroup)
 		c.MustCompile()
 
-		var bar *ditest.Bar
-		c.MustExtract(&bar)
-
-		var baz *ditest.Baz
-		c.MustExtract(&baz)
-
-		var group *ditest.FooerGroup
-		c.MustExtract(&group)
-		require.Len(t, group.Fooers(), 2)
-		c.MustEqualPointer(bar, group.Fooers()[0])
-		c.MustEqualPointer(baz, group.Fooers()[1])
+		require.EqualError(t, c.Explain(ditest.Foo{}, &bytes.Buffer{}), "explain target must be a pointer, got `ditest.Foo`")
 	})
 }
 
@@ -398,8 +4175,8 @@ func TestContainerResolveEmbedParameters(t *testing.T) {
 		c := NewTestContainer(t)
 		type Param struct {
 			di.Parameter
-			private    []http.Handler `di:"optional"`
-			Addrs      []net.Addr     `di:"optional"`
+			private    []http.Handler
+			Addrs      []net.Addr `di:"optional"`
 			HaveNotTag string
 		}
 		c.MustProvide(func(param Param) bool {
@@ -410,6 +4187,157 @@ func TestContainerResolveEmbedParameters(t *testing.T) {
 		c.MustExtract(&extracted)
 		require.True(t, extracted)
 	})
+
+	t.Run("tagged private field of parameter causes compile panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		type Param struct {
+			di.Parameter
+			foo *ditest.Foo `di:""`
+		}
+		c.MustProvide(ditest.NewFoo)
+		c.MustProvide(func(param Param) bool { return true })
+		require.PanicsWithValue(t, "field foo of di_test.Param is unexported, enable AllowUnexported() or export the field", c.Compile)
+	})
+
+	t.Run("tagged private field of invoke parameter causes error", func(t *testing.T) {
+		c := NewTestContainer(t)
+		type Param struct {
+			di.Parameter
+			foo *ditest.Foo `di:""`
+		}
+		c.MustProvide(ditest.NewFoo)
+		c.MustCompile()
+		c.MustInvokeError(func(param Param) {}, "could not resolve invoke parameters: di_test.Param: field foo of di_test.Param is unexported, enable AllowUnexported() or export the field")
+	})
+
+	t.Run("container set allowed private fields", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.AllowUnexported()
+		type Param struct {
+			di.Parameter
+			foo     *ditest.Foo    `di:""`
+			fooer   ditest.Fooer   `di:"optional"`
+			fooers  []ditest.Fooer `group:"fooers"`
+			private string
+		}
+		foo := ditest.NewFoo()
+		c.MustProvide(ditest.CreateFooConstructor(foo))
+		c.MustProvide(func(param Param) *ditest.Bar {
+			require.Nil(t, param.fooer)
+			require.Empty(t, param.fooers)
+			return ditest.NewBar(param.foo)
+		})
+		c.MustCompile()
+		var bar *ditest.Bar
+		c.MustExtract(&bar)
+		c.MustEqualPointer(foo, bar.Foo())
+		c.MustInvoke(func(param Param) {
+			c.MustEqualPointer(foo, param.foo)
+		})
+	})
+
+	t.Run("container does not touch skipped fields", func(t *testing.T) {
+		c := NewTestContainer(t)
+		type Param struct {
+			di.Parameter
+			Foo    *ditest.Foo    `di:""`
+			Bar    *ditest.Bar    `di:"-"`
+			Fooers []ditest.Fooer `di:"-" group:"fooers"`
+			foo    *ditest.Foo    `di:"-"`
+		}
+		c.MustProvide(ditest.NewFoo)
+		c.Provide(ditest.NewBar, di.ProvideParams{Groups: []string{"fooers"}})
+		c.MustProvide(func(param Param) bool {
+			return param.Foo != nil && param.Bar == nil && param.Fooers == nil && param.foo == nil
+		})
+		c.MustCompile()
+		var extracted bool
+		c.MustExtract(&extracted)
+		require.True(t, extracted)
+
+		var b bytes.Buffer
+		require.NoError(t, c.Explain(&extracted, &b))
+		require.Equal(t, strings.Join([]string{
+			"bool [singleton, built]",
+			"  di_test.Param [parameters]",
+			"    *ditest.Foo [singleton, built]",
+			"    Bar *ditest.Bar (skipped by `di:\"-\"` tag)",
+			"    Fooers []ditest.Fooer (skipped by `di:\"-\"` tag)",
+			"    foo *ditest.Foo (skipped by `di:\"-\"` tag)",
+			"",
+		}, "\n"), b.String())
+	})
+
+	t.Run("container resolve named optional field", func(t *testing.T) {
+		c := NewTestContainer(t)
+		type Param struct {
+			di.Parameter
+			Foo *ditest.Foo `di:"name=foo,optional"`
+			Bar *ditest.Bar `di:"name=bar,optional"`
+		}
+		c.Provide(ditest.NewFoo, di.ProvideParams{Name: "foo"})
+		c.MustProvide(func(param Param) bool {
+			return param.Foo != nil && param.Bar == nil
+		})
+		c.MustCompile()
+		var extracted bool
+		c.MustExtract(&extracted)
+		require.True(t, extracted)
+	})
+
+	t.Run("incorrect tag causes compile panic", func(t *testing.T) {
+		c := NewTestContainer(t)
+		type Param struct {
+			di.Parameter
+			Foo *ditest.Foo `di:"foo,optinal"`
+		}
+		c.MustProvide(func(param Param) bool { return true })
+		require.PanicsWithValue(t, "di_test.Param: field Foo has incorrect di tag `foo,optinal`: unknown option `optinal`", c.Compile)
+	})
+
+	t.Run("container resolve named fields of embedded struct", func(t *testing.T) {
+		c := NewTestContainer(t)
+		type FooerParams struct {
+			Primary ditest.Fooer   `di:"name=primary"`
+			Fooers  []ditest.Fooer `group:"fooers"`
+		}
+		type Param struct {
+			di.Parameter
+			FooerParams
+			Foo *ditest.Foo `di:"name=foo"`
+		}
+		foo := ditest.NewFoo()
+		c.Provide(ditest.CreateFooConstructor(foo), di.ProvideParams{Name: "foo"})
+		c.MustProvide(ditest.NewFoo)
+		c.Provide(ditest.NewBar, di.ProvideParams{Name: "primary", Interfaces: []interface{}{new(ditest.Fooer)}, Groups: []string{"fooers"}})
+		c.MustProvideWithName("bar", func(param Param) *ditest.Bar {
+			require.NotNil(t, param.Primary)
+			require.Len(t, param.Fooers, 1)
+			return ditest.NewBar(param.Foo)
+		})
+		c.MustCompile()
+		var bar *ditest.Bar
+		c.MustExtractWithName("bar", &bar)
+		c.MustEqualPointer(foo, bar.Foo())
+	})
+
+	t.Run("not existing named field lists available names", func(t *testing.T) {
+		c := NewTestContainer(t)
+		type Param struct {
+			di.Parameter
+			Foo *ditest.Foo `di:"name=replica"`
+		}
+		c.Provide(ditest.NewFoo, di.ProvideParams{Name: "primary"})
+		c.Provide(ditest.NewFoo, di.ProvideParams{Name: "secondary"})
+		c.MustCompile()
+		c.MustInvokeError(func(param Param) {}, "could not resolve invoke parameters: *ditest.Foo[replica]: not exists in container, available names: `primary`, `secondary`")
+	})
+
+	t.Run("allow private fields after provide panics", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		require.PanicsWithValue(t, "AllowUnexported() must be called before Provide()", c.AllowUnexported)
+	})
 }
 
 func TestContainerInvoke(t *testing.T) {
@@ -450,6 +4378,37 @@ func TestContainerInvoke(t *testing.T) {
 			return nil
 		})
 	})
+
+	t.Run("container resolve groups in invoke function", func(t *testing.T) {
+		c := NewTestContainer(t)
+		c.MustProvide(ditest.NewFoo)
+		c.Provide(ditest.NewBar, di.ProvideParams{Interfaces: []interface{}{new(ditest.Fooer)}})
+		c.MustCompile()
+		c.MustInvoke(func(fooers []ditest.Fooer) {
+			require.Len(t, fooers, 1)
+		})
+	})
+
+	t.Run("container resolve parameter struct in invoke function", func(t *testing.T) {
+		type InvokeParameters struct {
+			di.Parameter
+			Foo      *ditest.Foo             `di:"foo"`
+			Bar      *ditest.Bar             `di:"optional"`
+			Fooers   []ditest.Fooer          `group:"fooers"`
+			Registry map[string]ditest.Fooer `group:"fooers"`
+		}
+		c := NewTestContainer(t)
+		c.Provide(ditest.NewFoo, di.ProvideParams{Name: "foo"})
+		c.MustProvide(ditest.NewFoo)
+		c.Provide(ditest.NewBar, di.ProvideParams{Name: "bar", Interfaces: []interface{}{new(ditest.Fooer)}, Groups: []string{"fooers"}})
+		c.MustCompile()
+		c.MustInvoke(func(params InvokeParameters) {
+			require.NotNil(t, params.Foo)
+			require.Nil(t, params.Bar)
+			require.Len(t, params.Fooers, 1)
+			require.Contains(t, params.Registry, "bar")
+		})
+	})
 }
 
 func TestContainerResolveParameterBag(t *testing.T) {
@@ -565,6 +4524,7 @@ func TestContainer_GraphVisualizing(t *testing.T) {
 		bgcolor="#E8E8E8";color="lightgrey";fontcolor="#46494C";fontname="COURIER";label="";style="rounded";
 		n9[color="#46494C",fontcolor="white",fontname="COURIER",label="*di.Graph",shape="box",style="filled"];
 		n10[color="#46494C",fontcolor="white",fontname="COURIER",label="di.Interactor",shape="box",style="filled"];
+		n11[color="#46494C",fontcolor="white",fontname="COURIER",label="di.Resolver",shape="box",style="filled"];
 		
 	}subgraph cluster_s2 {
 		ID = "cluster_s2";
@@ -589,12 +4549,12 @@ func TestContainer_GraphVisualizing(t *testing.T) {
 	}splines="ortho";
 	n6->n7[color="#949494"];
 	n8->n7[color="#949494"];
-	n3->n5[color="#949494"];
 	n1->n2[color="#949494"];
 	n1->n3[color="#949494"];
 	n1->n6[color="#949494"];
 	n1->n8[color="#949494"];
-	n7->n4[color="#949494"];
+	n3->n5[color="#949494"];
+	n7->n4[color="#949494",style="dashed"];
 	n4->n3[color="#949494"];
 	n5->n2[color="#949494"];
 	
@@ -602,6 +4562,13 @@ func TestContainer_GraphVisualizing(t *testing.T) {
 	})
 }
 
+// location returns source location of function.
+func location(fn interface{}) string {
+	f := runtime.FuncForPC(reflect.ValueOf(fn).Pointer())
+	file, line := f.FileLine(f.Entry())
+	return fmt.Sprintf("%s:%d", file, line)
+}
+
 // NewTestContainer
 func NewTestContainer(t *testing.T) *TestContainer {
 	return &TestContainer{t, di.New()}
@@ -654,9 +4621,12 @@ func (c *TestContainer) MustCompile() {
 }
 
 func (c *TestContainer) MustCompileError(msg string) {
-	require.PanicsWithValue(c.t, msg, func() {
-		c.Compile()
-	})
+	defer func() {
+		r := recover()
+		require.NotNil(c.t, r, "compile should panic")
+		require.Equal(c.t, msg, fmt.Sprint(r))
+	}()
+	c.Compile()
 }
 
 func (c *TestContainer) MustExtract(target interface{}) {
@@ -718,3 +4688,135 @@ func (c *TestContainer) MustNotEqualPointer(expected interface{}, actual interfa
 		"actual and expected pointers should not be equal",
 	)
 }
+
+func BenchmarkContainerResolvePrototypeChain(b *testing.B) {
+	type level1 struct{}
+	type level2 struct{ *level1 }
+	type level3 struct{ *level2 }
+	type level4 struct{ *level3 }
+	type level5 struct{ *level4 }
+	c := di.New()
+	prototype := di.ProvideParams{IsPrototype: true}
+	c.Provide(func() *level1 { return &level1{} }, prototype)
+	c.Provide(func(l *level1) *level2 { return &level2{l} }, prototype)
+	c.Provide(func(l *level2) *level3 { return &level3{l} }, prototype)
+	c.Provide(func(l *level3) *level4 { return &level4{l} }, prototype)
+	c.Provide(func(l *level4) *level5 { return &level5{l} }, prototype)
+	c.Compile()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var l *level5
+		if err := c.Extract(&l); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkContainerResolveValues(b *testing.B) {
+	c := di.New()
+	names := make([]string, 32)
+	for i := range names {
+		names[i] = fmt.Sprintf("value%d", i)
+		c.ProvideValue(i, di.ProvideParams{Name: names[i]})
+	}
+	c.Compile()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, name := range names {
+			var value int
+			if err := c.Extract(&value, di.ExtractParams{Name: name}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkContainerResolveSingletonWithoutArguments(b *testing.B) {
+	c := di.New()
+	c.Provide(ditest.NewFoo)
+	c.Compile()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var foo *ditest.Foo
+		if err := c.Extract(&foo); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkContainerResolveCreatedSingleton(b *testing.B) {
+	c := di.New()
+	c.Provide(ditest.NewFoo)
+	c.Provide(ditest.NewBar)
+	c.Provide(ditest.NewBaz)
+	c.Compile()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		var baz *ditest.Baz
+		if err := c.Extract(&baz); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkContainerResolveDeepGraph(b *testing.B) {
+	for _, depth := range []int{10, 100} {
+		b.Run(fmt.Sprintf("depth %d", depth), func(b *testing.B) {
+			c := di.New()
+			typ := provideChain(c, depth, di.ProvideParams{IsPrototype: true})
+			c.Compile()
+			target := reflect.New(typ).Interface()
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if err := c.Extract(target); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkContainerBuildAll(b *testing.B) {
+	for _, size := range []int{100, 1000} {
+		b.Run(fmt.Sprintf("size %d", size), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c := di.New()
+				provideChain(c, size, di.ProvideParams{})
+				c.Compile()
+				if err := c.BuildAll(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+// provideChain provides constructors of types *[1]int ... *[depth]int, each type depends on the previous one. It
+// returns the last type.
+func provideChain(c *di.Container, depth int, params di.ProvideParams) reflect.Type {
+	var prev reflect.Type
+	for i := 0; i < depth; i++ {
+		typ := reflect.PtrTo(reflect.ArrayOf(i+1, reflect.TypeOf(0)))
+		var in []reflect.Type
+		if prev != nil {
+			in = append(in, prev)
+		}
+		ctor := reflect.MakeFunc(reflect.FuncOf(in, []reflect.Type{typ}, false), func(args []reflect.Value) []reflect.Value {
+			return []reflect.Value{reflect.New(typ.Elem())}
+		})
+		c.Provide(ctor.Interface(), params)
+		prev = typ
+	}
+	return prev
+}
+
+// writerFunc is an io.Writer function.
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
@@ -0,0 +1,49 @@
+package di
+
+import (
+	"context"
+	"reflect"
+)
+
+// contextHolder holds the context of constructors, atomic.Value requires the same concrete type.
+type contextHolder struct {
+	ctx context.Context
+}
+
+// setBuildContext sets context passed to context-first constructors, nil resets it to context.Background().
+func (c *Container) setBuildContext(ctx context.Context) {
+	c.ctx.Store(contextHolder{ctx: ctx})
+}
+
+// buildContext returns context passed to context-first constructors.
+func (c *Container) buildContext() context.Context {
+	if holder, ok := c.ctx.Load().(contextHolder); ok && holder.ctx != nil {
+		return holder.ctx
+	}
+	return context.Background()
+}
+
+// withContext prepends context to arguments of context-first constructor.
+func (c *Container) withContext(provider internalProvider, values []reflect.Value) []reflect.Value {
+	if !isContextFirst(provider) {
+		return values
+	}
+	args := make([]reflect.Value, 0, len(values)+1)
+	args = append(args, reflect.ValueOf(c.buildContext()))
+	return append(args, values...)
+}
+
+// isContextFirst checks that constructor of provider takes context.Context as the first parameter.
+func isContextFirst(provider internalProvider) bool {
+	switch p := provider.(type) {
+	case *singletonWrapper:
+		return isContextFirst(p.internalProvider)
+	case *providerRetry:
+		return isContextFirst(p.internalProvider)
+	case *providerInherited:
+		return isContextFirst(p.internalProvider)
+	case *providerConstructor:
+		return p.context
+	}
+	return false
+}
@@ -0,0 +1,298 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/defval/inject/v2/di/internal/reflection"
+)
+
+// DefinitionInfo is a snapshot of a registered definition.
+type DefinitionInfo struct {
+	// Key identifies the definition.
+	Key Key
+	// Type is a result type of the definition.
+	Type reflect.Type
+	// Name is a definition name.
+	Name string
+	// Keys are keys of the definition: the definition key and keys of additional names.
+	Keys []Key
+	// Sequence is a registration number of the definition. Definitions are numbered in provide order starting from 1.
+	Sequence int
+	// Order is a value of the order marker. Definitions with lower order goes first in groups.
+	Order int
+	// Primary is a marker of definition that wins if interface has several implementations.
+	Primary bool
+	// Tags are definition tags.
+	Tags Tags
+	// Lifetime is a lifetime of definition instances.
+	Lifetime Lifetime
+	// LifetimeSource is a source of the lifetime: provide option, default lifetime of module or of container.
+	LifetimeSource LifetimeSource
+	// Implements are interfaces that the definition is bound to.
+	Implements []reflect.Type
+	// Exclusive reports that the definition is reachable only through its interfaces.
+	Exclusive bool
+	// Groups are names of collections the definition joins.
+	Groups []string
+	// Dependencies are keys of constructor parameters.
+	Dependencies []Key
+	// Location is a constructor location. It is empty for provided values.
+	Location Location
+	// Created reports that the instance was created. For prototypes, CreatedAt and Duration describe the last
+	// created instance.
+	Created bool
+	// CreatedAt is a time when the constructor call started.
+	CreatedAt time.Time
+	// Duration is a duration of the constructor call.
+	Duration time.Duration
+	// Deprecated is a deprecation message. It is empty if the definition is not deprecated.
+	Deprecated string
+	// Description is a human readable purpose of the definition.
+	Description string
+}
+
+// Lifetime is a lifetime of definition instances.
+type Lifetime int
+
+const (
+	// Singleton instance is created once.
+	Singleton Lifetime = iota
+	// Prototype instance is created on each resolution.
+	Prototype
+)
+
+func (l Lifetime) String() string {
+	if l == Prototype {
+		return "prototype"
+	}
+	return "singleton"
+}
+
+// Location is a source code position of the constructor.
+type Location struct {
+	File     string
+	Line     int
+	Function string
+}
+
+func (l Location) String() string {
+	return fmt.Sprintf("%s:%d", l.File, l.Line)
+}
+
+// definition stores provider with its registration metadata.
+type definition struct {
+	key        key
+	seq        int
+	order      int
+	primary    bool
+	prototype  bool
+	source     LifetimeSource // source of lifetime
+	exclusive  bool           // reachable only through interfaces
+	tags       Tags
+	replaced   bool // provided as replacement
+	isolated   bool // container specific definition
+	location   reflection.Location
+	implements []reflect.Type // bound interfaces
+	bindings   []binding      // interfaces bound under names of interface keys
+	groups     []string       // named groups
+	names      []string       // additional names
+	setters    []setter       // methods called after creation
+	wired      uint32         // singleton setters are called
+	late       bool           // only late definitions depend on it
+	deprecated string         // deprecation message
+	label      string         // human readable description
+	warned     uint32         // deprecation warning is written
+	provider   internalProvider
+	shadows    *reflection.Location // constructor of the type bypassed by struct provider
+}
+
+// String represents definition as string. Anonymous structs rendered with source location, because their types are
+// not readable.
+func (d *definition) String() string {
+	if !isAnonymousStruct(d.key.res) {
+		return d.key.String()
+	}
+	var pointer string
+	if d.key.res.Kind() == reflect.Ptr {
+		pointer = "pointer to "
+	}
+	return fmt.Sprintf("%sanonymous struct provided at %s (name=%s)", pointer, d.location, d.key.name)
+}
+
+// described represents definition as string with its description.
+func (d *definition) described() string {
+	if d.label == "" {
+		return d.String()
+	}
+	return fmt.Sprintf("%s (%s)", d, d.label)
+}
+
+// Info returns definition snapshot.
+func (d *definition) Info() DefinitionInfo {
+	info := DefinitionInfo{
+		Key:         d.key.export(),
+		Type:        d.key.res,
+		Name:        d.key.name,
+		Sequence:    d.seq,
+		Order:       d.order,
+		Primary:     d.primary,
+		Tags:        d.tags,
+		Implements:  append([]reflect.Type(nil), d.implements...),
+		Exclusive:   d.exclusive,
+		Groups:      append([]string(nil), d.groups...),
+		Deprecated:  d.deprecated,
+		Description: d.label,
+	}
+	info.Keys = append(info.Keys, info.Key)
+	for _, name := range d.names {
+		info.Keys = append(info.Keys, key{name: name, res: d.key.res, tags: d.key.tags}.export())
+	}
+	if d.prototype {
+		info.Lifetime = Prototype
+	}
+	info.LifetimeSource = d.source
+	if d.location.File != "" {
+		info.Location = Location{File: d.location.File, Line: d.location.Line, Function: d.location.Function}
+	}
+	for _, param := range d.provider.ParameterList() {
+		info.Dependencies = append(info.Dependencies, key{name: param.name, res: param.res, tags: param.tags.String()}.export())
+	}
+	creation := lastCreation(d.provider)
+	info.Created = creation.created
+	info.CreatedAt = creation.at
+	info.Duration = creation.duration
+	return info
+}
+
+// isDuplicate checks that provider is the same constructor as the definition one and parameters have the same
+// markers, interfaces and groups.
+func (d *definition) isDuplicate(provider internalProvider, params ProvideParams) bool {
+	ctor, ok := provider.(*providerConstructor)
+	existing, isCtor := unwrapped(d.provider).(*providerConstructor)
+	if !ok || !isCtor || ctor.ctor.Pointer() != existing.ctor.Pointer() {
+		return false
+	}
+	if d.prototype != params.IsPrototype || d.order != params.Order || d.primary != params.IsPrimary ||
+		d.exclusive != params.IsExclusive || d.deprecated != params.Deprecated ||
+		len(d.implements) != len(params.Interfaces) || len(d.groups) != len(params.Groups) {
+		return false
+	}
+	for i, iface := range params.Interfaces {
+		if typ := reflect.TypeOf(iface); typ == nil || typ.Kind() != reflect.Ptr || d.implements[i] != typ.Elem() {
+			return false
+		}
+	}
+	for i, group := range params.Groups {
+		if d.groups[i] != group {
+			return false
+		}
+	}
+	return true
+}
+
+// bind adds interface to definition interfaces.
+func (d *definition) bind(iface reflect.Type) {
+	for _, typ := range d.implements {
+		if typ == iface {
+			return
+		}
+	}
+	d.implements = append(d.implements, iface)
+}
+
+// unbind removes interface from bound interfaces.
+func (d *definition) unbind(iface reflect.Type) {
+	for i, typ := range d.implements {
+		if typ == iface {
+			d.implements = append(d.implements[:i:i], d.implements[i+1:]...)
+			return
+		}
+	}
+}
+
+// binding is an interface that definition is bound to under name of the interface key.
+type binding struct {
+	name  string
+	iface reflect.Type
+}
+
+// bindNamed adds interface bound under name.
+func (d *definition) bindNamed(iface reflect.Type, name string) {
+	for _, b := range d.bindings {
+		if b.iface == iface && b.name == name {
+			return
+		}
+	}
+	d.bindings = append(d.bindings, binding{name: name, iface: iface})
+}
+
+// boundNamed checks that definition is bound to interface under a name of the interface key.
+func (d *definition) boundNamed(iface reflect.Type) bool {
+	for _, b := range d.bindings {
+		if b.iface == iface {
+			return true
+		}
+	}
+	return false
+}
+
+// join adds group to definition groups.
+func (d *definition) join(group string) {
+	for _, name := range d.groups {
+		if name == group {
+			return
+		}
+	}
+	d.groups = append(d.groups, group)
+}
+
+// definitionList
+type definitionList []*definition
+
+// addDefinition appends definition to container definitions and indexes it by key.
+func (c *Container) addDefinition(def *definition) {
+	if c.byKey == nil {
+		c.byKey = map[key]*definition{}
+	}
+	c.definitions = append(c.definitions, def)
+	c.byKey[def.key] = def
+}
+
+// definition returns definition by key or nil if it does not exist.
+func (c *Container) definition(k key) *definition {
+	return c.byKey[k]
+}
+
+// Get returns definition by key or nil if it does not exist.
+func (l definitionList) Get(k key) *definition {
+	for _, def := range l {
+		if def.key == k {
+			return def
+		}
+	}
+	return nil
+}
+
+// Sort sorts definitions by order marker. Definitions with the same order are sorted by registration sequence.
+// The sequence is unique per container therefore the result does not depend on initial slice order.
+func (l definitionList) Sort() {
+	sort.SliceStable(l, func(i, j int) bool {
+		if l[i].order != l[j].order {
+			return l[i].order < l[j].order
+		}
+		return l[i].seq < l[j].seq
+	})
+}
+
+// described represents definitions as comma separated string with their descriptions.
+func (l definitionList) described() string {
+	names := make([]string, 0, len(l))
+	for _, def := range l {
+		names = append(names, def.described())
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,197 @@
+package di
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"reflect"
+	"runtime"
+	"runtime/debug"
+	"strconv"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrCompiling is an error of resolution during compile in development mode. Without development mode the resolution
+// waits for compile, it deadlocks if compile waits for the resolution.
+var ErrCompiling = errors.New("container is compiling, resolution must wait for Compile()")
+
+// SetDevMode enables invariant checks of development mode and sets writer of their warnings:
+//
+//   - resolution from a provider on the same goroutine is reported with stacks of the provider call and the
+//     resolution, re-entry into the singleton that is being created deadlocks;
+//   - resolution during compile returns ErrCompiling instead of waiting for compile;
+//   - change of exported comparable fields of singleton instance after it is cached is reported once per definition.
+//     Definitions with setters are not checked, setters change instances by design.
+//
+// Checks capture stacks and hash instances, so they are slow. Nil writer disables development mode, the checks cost
+// nothing then.
+//
+//   c.SetDevMode(os.Stderr)
+func (c *Container) SetDevMode(w io.Writer) {
+	c.storage.Lock()
+	defer c.storage.Unlock()
+	c.mustNotCompiled()
+	if w == nil {
+		c.dev = nil
+		return
+	}
+	c.dev = &devMode{
+		log:     w,
+		calls:   map[uint64][]devCall{},
+		hashes:  map[*definition]uint64{},
+		mutated: map[*definition]bool{},
+	}
+}
+
+// devMode is a state of development mode checks.
+type devMode struct {
+	compiling int32 // compile is in progress
+	log       io.Writer
+	mu        sync.Mutex             // guards log and maps
+	calls     map[uint64][]devCall   // provider calls in progress by goroutine
+	hashes    map[*definition]uint64 // hashes of cached singletons
+	mutated   map[*definition]bool   // mutation is reported
+}
+
+// devCall is a provider call in progress.
+type devCall struct {
+	key   key
+	stack []byte
+}
+
+// checkCompiling returns ErrCompiling if resolution is called during compile.
+func (d *devMode) checkCompiling() error {
+	if d != nil && atomic.LoadInt32(&d.compiling) != 0 {
+		return ErrCompiling
+	}
+	return nil
+}
+
+// compile marks compile in progress.
+func (d *devMode) compile(compiling bool) {
+	if d == nil {
+		return
+	}
+	var flag int32
+	if compiling {
+		flag = 1
+	}
+	atomic.StoreInt32(&d.compiling, flag)
+}
+
+// enter reports resolution of target from a provider call on the same goroutine.
+func (d *devMode) enter(target string) {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	calls := d.calls[goroutineID()]
+	if len(calls) == 0 {
+		return
+	}
+	call := calls[len(calls)-1]
+	fmt.Fprintf(d.log, "dev mode: resolution of %s re-entered from provider of %s on the same goroutine\n"+
+		"provider call:\n%s\nresolution:\n%s\n", target, call.key, call.stack, debug.Stack())
+}
+
+// begin marks provider call in progress on the current goroutine.
+func (d *devMode) begin(k key) {
+	if d == nil {
+		return
+	}
+	id := goroutineID()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.calls[id] = append(d.calls[id], devCall{key: k, stack: debug.Stack()})
+}
+
+// end completes provider call on the current goroutine.
+func (d *devMode) end() {
+	if d == nil {
+		return
+	}
+	id := goroutineID()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if calls := d.calls[id]; len(calls) > 1 {
+		d.calls[id] = calls[:len(calls)-1]
+	} else {
+		delete(d.calls, id)
+	}
+}
+
+// cached remembers hash of created singleton.
+func (d *devMode) cached(def *definition, instance reflect.Value) {
+	if d == nil || def == nil || def.prototype || len(def.setters) != 0 {
+		return
+	}
+	hash, ok := instanceHash(instance)
+	if !ok {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.hashes[def] = hash
+}
+
+// check reports change of cached singleton.
+func (d *devMode) check(def *definition, instance reflect.Value) {
+	if d == nil || def == nil {
+		return
+	}
+	d.mu.Lock()
+	expected, hashed := d.hashes[def]
+	d.mu.Unlock()
+	if !hashed {
+		return
+	}
+	hash, _ := instanceHash(instance)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if hash == expected || d.mutated[def] {
+		return
+	}
+	d.mutated[def] = true
+	fmt.Fprintf(d.log, "dev mode: %s changed after it was cached, singleton is shared by all dependents\n", def)
+}
+
+// instanceHash returns hash of exported comparable fields of struct or pointer to struct.
+func instanceHash(instance reflect.Value) (uint64, bool) {
+	value := instance
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return 0, false
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return 0, false
+	}
+	h := fnv.New64a()
+	var hashed bool
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		if field.PkgPath != "" || !field.Type.Comparable() {
+			continue
+		}
+		fmt.Fprintf(h, "%s=%#v;", field.Name, value.Field(i).Interface())
+		hashed = true
+	}
+	return h.Sum64(), hashed
+}
+
+// goroutineID returns id of the current goroutine from its stack header like `goroutine 18 [running]:`.
+func goroutineID() uint64 {
+	var buf [64]byte
+	header := buf[:runtime.Stack(buf[:], false)]
+	header = bytes.TrimPrefix(header, []byte("goroutine "))
+	if i := bytes.IndexByte(header, ' '); i >= 0 {
+		header = header[:i]
+	}
+	id, _ := strconv.ParseUint(string(header), 10, 64)
+	return id
+}
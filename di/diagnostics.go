@@ -0,0 +1,148 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/defval/inject/v2/di/internal/reflection"
+)
+
+// Severity is a severity of a finding.
+type Severity string
+
+const (
+	// SeverityError is a finding that fails container assembly.
+	SeverityError Severity = "error"
+	// SeverityWarning is a finding that could fail resolution or resolve unexpected instance.
+	SeverityWarning Severity = "warning"
+	// SeverityInfo is a finding that describes a decision of compile.
+	SeverityInfo Severity = "info"
+)
+
+// Stage is a stage of container assembly.
+type Stage string
+
+const (
+	// StageProvide is validation of providers.
+	StageProvide Stage = "provide"
+	// StageBind is binding of interfaces to provided types.
+	StageBind Stage = "bind"
+	// StageConnect is connection of definitions with their dependencies and setters.
+	StageConnect Stage = "connect"
+	// StageCycle is a check of dependency cycles.
+	StageCycle Stage = "cycle"
+	// StageBuild is creation of singletons by BuildAll().
+	StageBuild Stage = "build"
+)
+
+// stageKinds are kinds of error findings of stages.
+var stageKinds = map[Stage]FindingKind{
+	StageProvide: FindingInvalid,
+	StageBind:    FindingInvalid,
+	StageConnect: FindingUnresolved,
+	StageCycle:   FindingCycle,
+	StageBuild:   FindingFailed,
+}
+
+// Diagnostics are findings of container assembly. If the assembly failed, Stage is the failed stage and the first
+// finding is the error. Otherwise Stage is empty and findings are issues tolerated by compile.
+type Diagnostics struct {
+	Stage    Stage     `json:"stage,omitempty"`
+	Findings []Finding `json:"findings"`
+}
+
+// ErrCompileFailed is an error of container assembly returned by Recover(). The message is the message of the
+// recovered panic.
+type ErrCompileFailed struct {
+	recovered   interface{}
+	diagnostics *Diagnostics
+}
+
+func (e ErrCompileFailed) Error() string {
+	return fmt.Sprint(e.recovered)
+}
+
+// Unwrap returns the recovered error, for example ErrDependencyCycle.
+func (e ErrCompileFailed) Unwrap() error {
+	err, _ := e.recovered.(error)
+	return err
+}
+
+// Diagnostics returns the failed stage and the error finding.
+func (e ErrCompileFailed) Diagnostics() *Diagnostics {
+	return e.diagnostics
+}
+
+// step is a step of container assembly. It identifies the failure of recovered panic.
+type step struct {
+	stage    Stage
+	key      key
+	location reflection.Location
+}
+
+// Recover calls fn that provides definitions, compiles or builds the container and returns its panic as
+// ErrCompileFailed. Diagnostics of the error have the stage that panicked, the key and the location of the definition.
+//
+//   err := c.Recover(func() {
+//     c.Provide(NewServer)
+//     c.Compile()
+//   })
+func (c *Container) Recover(fn func()) (err error) {
+	c.begin(step{})
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = ErrCompileFailed{recovered: recovered, diagnostics: c.failure(recovered)}
+		}
+	}()
+	fn()
+	return nil
+}
+
+// begin sets the current assembly step.
+func (c *Container) begin(s step) {
+	c.storage.Lock()
+	defer c.storage.Unlock()
+	c.step = s
+}
+
+// failure returns diagnostics of recovered panic of the current step.
+func (c *Container) failure(recovered interface{}) *Diagnostics {
+	c.storage.RLock()
+	defer c.storage.RUnlock()
+	s := c.step
+	if s.stage == "" {
+		// options are applied before providers
+		s.stage = StageProvide
+	}
+	if err, ok := recovered.(error); ok {
+		var cycle ErrDependencyCycle
+		var failed ErrParameterProvideFailed
+		switch {
+		case errors.As(err, &cycle) && len(cycle.cycle) != 0:
+			s.key = cycle.cycle[0].k
+		case errors.As(err, &failed):
+			s.key = failed.k
+		}
+	}
+	finding := Finding{
+		Kind:     stageKinds[s.stage],
+		Severity: SeverityError,
+		Message:  fmt.Sprint(recovered),
+	}
+	if s.key.res != nil {
+		finding.Key = c.describe(s.key)
+	}
+	if def := c.definition(s.key); def != nil && s.location.File == "" {
+		s.location = def.location
+	}
+	finding.Location = findingLocation(s.location)
+	return &Diagnostics{Stage: s.stage, Findings: []Finding{finding}}
+}
+
+// findingLocation returns location of finding, nil if the location is unknown.
+func findingLocation(location reflection.Location) *Location {
+	if location.File == "" {
+		return nil
+	}
+	return &Location{File: location.File, Line: location.Line, Function: location.Function}
+}
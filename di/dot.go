@@ -11,8 +11,10 @@ type Graph struct {
 	graph *dot.Graph
 }
 
-func (g *Graph) WriteTo(writer io.Writer) {
-	g.graph.Write(writer)
+// WriteTo writes graph in DOT format into writer. It implements io.WriterTo.
+func (g *Graph) WriteTo(writer io.Writer) (int64, error) {
+	n, err := io.WriteString(writer, g.graph.String())
+	return int64(n), err
 }
 
 func (g *Graph) String() string {
@@ -0,0 +1,283 @@
+package di
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// maxTypeLength is a length of type names in dump that are not verbose.
+const maxTypeLength = 60
+
+// dumpKinds are labels of graph nodes that are not definitions.
+var dumpKinds = map[providerType]string{
+	ptInterface:      "interface",
+	ptGroup:          "group",
+	ptEmbedParameter: "parameters",
+	ptConversion:     "conversion",
+	ptFactory:        "factory",
+	ptAlias:          "alias",
+	ptRegistry:       "registry",
+	ptFallback:       "fallback",
+}
+
+// DumpParams is a `DebugDump()` method options. IsVerbose prints full type names instead of truncated ones.
+type DumpParams struct {
+	IsVerbose bool
+}
+
+// String represents container definitions as table in registration order: type, name, lifetime, number of
+// dependencies and built marker.
+func (c *Container) String() string {
+	c.storage.RLock()
+	defer c.storage.RUnlock()
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tNAME\tLIFETIME\tDEPS\tBUILT")
+	for _, def := range c.definitions {
+		info := def.Info()
+		name := info.Name
+		if name == "" {
+			name = "-"
+		}
+		built := "no"
+		if info.Created {
+			built = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", truncateType(typeString(def.key), false), name, info.Lifetime,
+			len(info.Dependencies), built)
+	}
+	w.Flush()
+	return b.String()
+}
+
+// DebugDump writes dependency tree of the container into writer. Definitions that are not dependencies of other
+// definitions are roots of the tree, their dependencies are indented under them. Subtree of a dependency is
+// written once, later occurrences are marked as shown above.
+//
+//   *http.Server [singleton, built]
+//     *http.ServeMux [singleton, built]
+//       *log.Logger [singleton, built]
+//     http.Handler [interface]
+//       *http.ServeMux [singleton, built] (shown above)
+//
+// DebugDump does not create instances.
+func (c *Container) DebugDump(w io.Writer, params DumpParams) error {
+	c.storage.RLock()
+	defer c.storage.RUnlock()
+	// interfaces, groups and parameters are passed through to find definitions that are dependencies
+	dependencies := map[key]bool{}
+	var mark func(provider internalProvider)
+	mark = func(provider internalProvider) {
+		for _, param := range provider.ParameterList() {
+			dependency, exists := param.ResolveProvider(c)
+			if !exists || dependencies[dependency.Key()] {
+				continue
+			}
+			dependencies[dependency.Key()] = true
+			if c.definition(dependency.Key()) == nil {
+				mark(dependency)
+			}
+		}
+	}
+	for _, def := range c.definitions {
+		mark(def.provider)
+	}
+	b := bufio.NewWriter(w)
+	expanded := map[key]bool{}
+	var write func(provider internalProvider, depth int)
+	write = func(provider internalProvider, depth int) {
+		k := provider.Key()
+		plist := provider.ParameterList()
+		fmt.Fprintf(b, "%s%s", strings.Repeat("  ", depth), c.dumpNode(k, params.IsVerbose))
+		if expanded[k] && len(plist) != 0 {
+			fmt.Fprintln(b, " (shown above)")
+			return
+		}
+		fmt.Fprintln(b)
+		expanded[k] = true
+		for _, param := range plist {
+			dependency, exists := param.ResolveProvider(c)
+			if !exists {
+				fmt.Fprintf(b, "%s%s (not exists)\n", strings.Repeat("  ", depth+1), truncateType(param.String(), params.IsVerbose))
+				continue
+			}
+			write(dependency, depth+1)
+		}
+		writeSkippedFields(b, provider, depth+1)
+	}
+	for _, def := range c.definitions {
+		if !dependencies[def.key] {
+			write(def.provider, 0)
+		}
+	}
+	return b.Flush()
+}
+
+// writeCompileLog writes compile summary. Construction order lists definitions after their dependencies, so eager
+// build of all definitions creates them in this order.
+//
+//   compiled 2 definitions
+//   construction order:
+//     1. *http.ServeMux [singleton]
+//     2. *http.Server [singleton]
+//   interface bindings:
+//     http.Handler -> *http.ServeMux: the only implementation
+//   conversions:
+//     time.Duration <- main.Timeout
+func (c *Container) writeCompileLog(w io.Writer) {
+	b := bufio.NewWriter(w)
+	defer b.Flush()
+	sorted, _ := c.graph.Sort()
+	order := c.constructionOrder()
+	fmt.Fprintf(b, "compiled %d definitions\n", len(order))
+	fmt.Fprintln(b, "construction order:")
+	for i, def := range order {
+		fmt.Fprintf(b, "  %d. %s [%s]\n", i+1, def.key, def.Info().Lifetime)
+	}
+	var bindings []string
+	for _, k := range sorted {
+		iface, ok := c.graph.Get(k).Value.(*providerInterface)
+		if !ok {
+			continue
+		}
+		def, err := iface.Implementation()
+		switch {
+		case err != nil:
+			bindings = append(bindings, fmt.Sprintf("  %s: %s", iface.res, err))
+		case len(iface.impls) == 1:
+			bindings = append(bindings, fmt.Sprintf("  %s -> %s: the only implementation", iface.res, def.key))
+		default:
+			bindings = append(bindings, fmt.Sprintf("  %s -> %s: primary of %d implementations", iface.res, def.key,
+				len(iface.impls)))
+		}
+	}
+	if len(bindings) != 0 {
+		fmt.Fprintln(b, "interface bindings:")
+		for _, binding := range bindings {
+			fmt.Fprintln(b, binding)
+		}
+	}
+	var conversions []string
+	for _, k := range sorted {
+		conversion, ok := c.graph.Get(k).Value.(*providerConversion)
+		if !ok {
+			continue
+		}
+		if def, err := conversion.Source(); err != nil {
+			conversions = append(conversions, fmt.Sprintf("  %s: %s", conversion.res, err))
+		} else {
+			conversions = append(conversions, fmt.Sprintf("  %s <- %s", conversion.res, def))
+		}
+	}
+	if len(conversions) != 0 {
+		fmt.Fprintln(b, "conversions:")
+		for _, conversion := range conversions {
+			fmt.Fprintln(b, conversion)
+		}
+	}
+	var warnings []string
+	for _, def := range c.definitions {
+		if warning := def.shadowWarning(); warning != "" {
+			warnings = append(warnings, fmt.Sprintf("  %s: %s", def, warning))
+		}
+	}
+	if len(warnings) != 0 {
+		fmt.Fprintln(b, "warnings:")
+		for _, warning := range warnings {
+			fmt.Fprintln(b, warning)
+		}
+	}
+}
+
+// writeGraphLog writes one line per definition sorted by key: key, lifetime, dependency keys, interfaces that resolve
+// to the definition and location.
+//
+//   key="*http.Server" lifetime=singleton dependencies="*log.Logger, http.Handler" implements="" location="main.go:20"
+func (c *Container) writeGraphLog(w io.Writer) {
+	implements := map[key][]string{}
+	for _, node := range c.graph.Nodes() {
+		iface, ok := node.Value.(*providerInterface)
+		if !ok {
+			continue
+		}
+		if def, err := iface.Implementation(); err == nil {
+			implements[def.key] = append(implements[def.key], iface.res.String())
+		}
+	}
+	var lines []string
+	for _, def := range c.definitions {
+		if def.isolated {
+			continue
+		}
+		info := def.Info()
+		var dependencies []string
+		for _, dep := range info.Dependencies {
+			dependencies = append(dependencies, dep.String())
+		}
+		sort.Strings(implements[def.key])
+		var location string
+		if info.Location.File != "" {
+			location = info.Location.String()
+		}
+		lines = append(lines, fmt.Sprintf("key=%q lifetime=%s dependencies=%q implements=%q location=%q\n", def.key,
+			info.Lifetime, strings.Join(dependencies, ", "), strings.Join(implements[def.key], ", "), location))
+	}
+	sort.Strings(lines)
+	b := bufio.NewWriter(w)
+	defer b.Flush()
+	for _, line := range lines {
+		b.WriteString(line)
+	}
+}
+
+// writeSkippedFields writes fields of parameter struct that are skipped by tag, so it is clear why they are not set.
+func writeSkippedFields(w io.Writer, provider internalProvider, depth int) {
+	var embed *providerEmbed
+	switch p := provider.(type) {
+	case *providerEmbed:
+		embed = p
+	case *providerStruct:
+		embed = p.fields
+	default:
+		return
+	}
+	for _, field := range embed.skippedFields() {
+		fmt.Fprintf(w, "%s%s %s (skipped by `di:\"-\"` tag)\n", strings.Repeat("  ", depth), field.Name, field.Type)
+	}
+}
+
+// dumpNode represents graph node with its description, lifetime and built marker.
+func (c *Container) dumpNode(k key, verbose bool) string {
+	def := c.definition(k)
+	if def == nil {
+		return fmt.Sprintf("%s [%s]", truncateType(k.String(), verbose), dumpKinds[k.typ])
+	}
+	info := def.Info()
+	built := "not built"
+	if info.Created {
+		built = "built"
+	}
+	name := truncateType(k.String(), verbose)
+	if def.label != "" {
+		name = fmt.Sprintf("%s (%s)", name, def.label)
+	}
+	return fmt.Sprintf("%s [%s, %s]", name, info.Lifetime, built)
+}
+
+// typeString represents definition type with tags.
+func typeString(k key) string {
+	return key{res: k.res, tags: k.tags}.String()
+}
+
+// truncateType truncates long type name if verbose is not set.
+func truncateType(typ string, verbose bool) string {
+	runes := []rune(typ)
+	if verbose || len(runes) <= maxTypeLength {
+		return typ
+	}
+	return string(runes[:maxTypeLength-3]) + "..."
+}
@@ -1,22 +1,272 @@
 package di
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime/debug"
+	"strings"
+
+	"github.com/emicklei/dot"
+)
+
+// ErrContainerCompiled is a panic value of container changes after compile.
+var ErrContainerCompiled = errors.New("container already compiled")
 
 // ErrParameterProvideFailed
 type ErrParameterProvideFailed struct {
-	k   key
-	err error
+	k    key
+	desc string
+	err  error
+	path []key
 }
 
 func (e ErrParameterProvideFailed) Error() string {
-	return fmt.Sprintf("%s: %s", e.k, e.err)
+	return fmt.Sprintf("%s: %s", e.desc, e.err)
+}
+
+// Unwrap returns constructor error.
+func (e ErrParameterProvideFailed) Unwrap() error {
+	return e.err
 }
 
-// ErrParameterProviderNotFound
+// ErrParameterProviderNotFound is an error of type that does not exist in container. Use errors.As() to get the
+// requested type and candidates, for example to fall back to alternative resolution.
 type ErrParameterProviderNotFound struct {
-	param parameter
+	param      parameter
+	hint       string
+	candidates []Key
+	path       []key
+	fallbacks  int // number of consulted fallback containers
 }
 
 func (e ErrParameterProviderNotFound) Error() string {
-	return fmt.Sprintf("%s: not exists in container", e.param)
+	where := "container"
+	switch {
+	case e.fallbacks == 1:
+		where = "container and its fallback container"
+	case e.fallbacks > 1:
+		where = fmt.Sprintf("container and its %d fallback containers", e.fallbacks)
+	}
+	if e.hint != "" {
+		return fmt.Sprintf("%s: not exists in %s, %s", e.param, where, e.hint)
+	}
+	return fmt.Sprintf("%s: not exists in %s", e.param, where)
+}
+
+// As makes errors.As() find the error with pointer target too, both forms work:
+//
+//   var notFound ErrParameterProviderNotFound
+//   var notFoundPtr *ErrParameterProviderNotFound
+//   errors.As(err, &notFound)
+//   errors.As(err, &notFoundPtr)
+func (e ErrParameterProviderNotFound) As(target interface{}) bool {
+	ptr, ok := target.(**ErrParameterProviderNotFound)
+	if !ok {
+		return false
+	}
+	copied := e
+	*ptr = &copied
+	return true
+}
+
+// Fallbacks returns number of fallback containers that were consulted after the container, see SetFallbacks().
+func (e ErrParameterProviderNotFound) Fallbacks() int {
+	return e.fallbacks
+}
+
+// Type returns the requested type.
+func (e ErrParameterProviderNotFound) Type() reflect.Type {
+	return e.param.res
+}
+
+// Name returns the requested definition name.
+func (e ErrParameterProviderNotFound) Name() string {
+	return e.param.name
+}
+
+// Candidates returns keys of definitions that could be meant: definitions of the requested type with other names or
+// tags and, if the requested type is an interface, definitions that implement it.
+func (e ErrParameterProviderNotFound) Candidates() []Key {
+	return append([]Key(nil), e.candidates...)
+}
+
+// ErrResolutionTooDeep is an error of resolution that exceeds the depth limit, see SetMaxDepth(). Error message
+// contains the first and the last types of the resolution chain to identify the repeated pattern.
+type ErrResolutionTooDeep struct {
+	limit int
+	path  []key // the deepest type goes first
+}
+
+// resolutionChainEdge is a number of types that are printed from each end of too deep resolution chain.
+const resolutionChainEdge = 20
+
+func (e ErrResolutionTooDeep) Error() string {
+	chain := make([]string, 0, len(e.path))
+	for i := len(e.path) - 1; i >= 0; i-- {
+		chain = append(chain, e.path[i].String())
+	}
+	if skipped := len(chain) - 2*resolutionChainEdge; skipped > 0 {
+		head := append(chain[:resolutionChainEdge:resolutionChainEdge], fmt.Sprintf("... %d types ...", skipped))
+		chain = append(head, chain[len(chain)-resolutionChainEdge:]...)
+	}
+	return fmt.Sprintf("resolution depth exceeds limit of %d: %s", e.limit, strings.Join(chain, " -> "))
+}
+
+// ErrPanic is a recovered panic of constructor or invoked function, see SetRecoverMode(). It keeps the panic value
+// and the stack of the panic.
+type ErrPanic struct {
+	value interface{}
+	stack []byte
+}
+
+// newErrPanic creates error of recovered value with the current stack.
+func newErrPanic(value interface{}) ErrPanic {
+	return ErrPanic{value: value, stack: debug.Stack()}
+}
+
+func (e ErrPanic) Error() string {
+	return fmt.Sprintf("panic: %v", e.value)
+}
+
+// Value returns the panic value.
+func (e ErrPanic) Value() interface{} {
+	return e.value
+}
+
+// Stack returns the stack of the panic.
+func (e ErrPanic) Stack() []byte {
+	return e.stack
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e ErrPanic) Unwrap() error {
+	err, _ := e.value.(error)
+	return err
+}
+
+// ErrDeprecated is an error of deprecated definition resolution in strict mode, see SetStrictDeprecation().
+type ErrDeprecated struct {
+	message string
+}
+
+func (e ErrDeprecated) Error() string {
+	return fmt.Sprintf("deprecated: %s", e.message)
+}
+
+// ErrDependencyCycle is a compile error caused by dependency cycle. Error message contains the shortest chain of
+// types where each type depends on the next one. Use DOT() or Mermaid() to get a diagram of the cycle.
+type ErrDependencyCycle struct {
+	cycle     []cycleNode // dependents go first
+	component []cycleNode // strongly connected component of the cycle
+}
+
+// cycleNode is a node of dependency cycle.
+type cycleNode struct {
+	k        key
+	desc     string
+	location string
+}
+
+// label returns node label with source location.
+func (n cycleNode) label(separator string) string {
+	if n.location == "" {
+		return n.desc
+	}
+	return n.desc + separator + n.location
+}
+
+func (e ErrDependencyCycle) Error() string {
+	chain := make([]string, 0, len(e.cycle)+1)
+	for _, node := range e.cycle {
+		chain = append(chain, node.desc)
+	}
+	chain = append(chain, e.cycle[0].desc)
+	return fmt.Sprintf("the graph cannot be cyclic: %s", strings.Join(chain, " -> "))
+}
+
+// Component returns all types of the strongly connected component that contains the cycle. Each of them depends on
+// all the others directly or transitively.
+func (e ErrDependencyCycle) Component() []string {
+	component := make([]string, 0, len(e.component))
+	for _, node := range e.component {
+		component = append(component, node.desc)
+	}
+	return component
+}
+
+// DOT returns the cycle in DOT format. Edges are directed from dependency to dependent as in the container graph.
+func (e ErrDependencyCycle) DOT() string {
+	graph := dot.NewGraph(dot.Directed)
+	nodes := make([]dot.Node, 0, len(e.cycle))
+	for _, node := range e.cycle {
+		item := graph.Node(node.desc)
+		node.k.Visualize(&item)
+		item.Label(node.label("\n"))
+		nodes = append(nodes, item)
+	}
+	for i := range nodes {
+		graph.Edge(nodes[(i+1)%len(nodes)], nodes[i]).Attr("color", "#E54B4B")
+	}
+	return graph.String()
+}
+
+// Mermaid returns the cycle as Mermaid flowchart. Edges are directed from dependency to dependent as in the
+// container graph.
+func (e ErrDependencyCycle) Mermaid() string {
+	var b strings.Builder
+	b.WriteString("graph TD\n")
+	for i, node := range e.cycle {
+		fmt.Fprintf(&b, "    n%d[\"%s\"]\n", i, strings.ReplaceAll(node.label("<br/>"), `"`, "#quot;"))
+	}
+	for i := range e.cycle {
+		fmt.Fprintf(&b, "    n%d --> n%d\n", (i+1)%len(e.cycle), i)
+	}
+	return b.String()
+}
+
+// DependencyPath returns the chain of types from the resolved type to the failed one. It returns nil if error was
+// not caused by the resolving.
+//
+//   err := container.Extract(&server)
+//   di.DependencyPath(err) // [*http.Server http.Handler *http.ServeMux *log.Logger]
+func DependencyPath(err error) []string {
+	var keys []key
+	var provideFailed ErrParameterProvideFailed
+	var notFound ErrParameterProviderNotFound
+	var tooDeep ErrResolutionTooDeep
+	switch {
+	case errors.As(err, &provideFailed):
+		keys = append(provideFailed.path, provideFailed.k)
+	case errors.As(err, &notFound):
+		keys = append(notFound.path, key{name: notFound.param.name, res: notFound.param.res, tags: notFound.param.tags.String()})
+	case errors.As(err, &tooDeep):
+		for i := len(tooDeep.path) - 1; i >= 0; i-- {
+			keys = append(keys, tooDeep.path[i])
+		}
+	default:
+		return nil
+	}
+	path := make([]string, 0, len(keys))
+	for _, k := range keys {
+		path = append(path, k.String())
+	}
+	return path
+}
+
+// withDependent adds dependent type at the beginning of the error dependency path.
+func withDependent(err error, dependent key) error {
+	switch e := err.(type) {
+	case ErrParameterProvideFailed:
+		e.path = append([]key{dependent}, e.path...)
+		return e
+	case ErrParameterProviderNotFound:
+		e.path = append([]key{dependent}, e.path...)
+		return e
+	case ErrResolutionTooDeep:
+		// too deep chain is long, so dependents are appended
+		e.path = append(e.path, dependent)
+		return e
+	}
+	return err
 }
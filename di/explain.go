@@ -0,0 +1,133 @@
+package di
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/defval/inject/v2/di/internal/reflection"
+)
+
+// Explain writes how the target type resolves: the used definition and its dependencies as indented tree. Each
+// definition is annotated with lifetime and built marker, interfaces with the reason of the implementation choice.
+//
+//   *http.Server [singleton, not built]
+//     http.Handler [interface] resolved as *http.ServeMux: the only implementation
+//       *http.ServeMux [singleton, built]
+//         *log.Logger (not exists)
+//
+// If the resolution would fail, Explain writes the tree down to the failed type and returns the same error as
+// Extract(). Explain does not create instances.
+func (c *Container) Explain(target interface{}, w io.Writer, options ...ExtractOption) error {
+	params := ExtractParams{}
+	for _, opt := range options {
+		opt.apply(&params)
+	}
+	if !c.isCompiled() {
+		return fmt.Errorf("container not compiled")
+	}
+	if target == nil {
+		return fmt.Errorf("explain target must be a pointer, got `nil`")
+	}
+	if !reflection.IsPtr(target) {
+		return fmt.Errorf("explain target must be a pointer, got `%s`", reflect.TypeOf(target))
+	}
+	typ := reflect.TypeOf(target)
+	param := parameter{
+		name:  params.Name,
+		res:   typ.Elem(),
+		tags:  params.Tags,
+		embed: isEmbedParameter(typ),
+		group: params.Group,
+	}
+	c.storage.RLock()
+	defer c.storage.RUnlock()
+	b := bufio.NewWriter(w)
+	expanded := map[key]bool{}
+	var failure error
+	var write func(param parameter, depth int, path []key)
+	write = func(param parameter, depth int, path []key) {
+		indent := strings.Repeat("  ", depth)
+		provider, exists := param.ResolveProvider(c)
+		if !exists {
+			if param.optional {
+				fmt.Fprintf(b, "%s%s (not exists, optional)\n", indent, param)
+				return
+			}
+			fmt.Fprintf(b, "%s%s (not exists)\n", indent, param)
+			if failure == nil {
+				notFound := c.notFound(param)
+				notFound.path = path
+				failure = notFound
+			}
+			return
+		}
+		k := provider.Key()
+		fmt.Fprintf(b, "%s%s%s", indent, c.dumpNode(k, true), c.explainReason(param, provider))
+		if err := ambiguity(provider); err != nil && failure == nil {
+			failure = ErrParameterProvideFailed{k: k, desc: c.describe(k), err: err, path: path}
+		}
+		plist := provider.ParameterList()
+		if expanded[k] && len(plist) != 0 {
+			fmt.Fprintln(b, " (shown above)")
+			return
+		}
+		fmt.Fprintln(b)
+		expanded[k] = true
+		for _, dependency := range plist {
+			write(dependency, depth+1, append(path[:len(path):len(path)], k))
+		}
+		writeSkippedFields(b, provider, depth+1)
+	}
+	write(param, 0, nil)
+	if err := b.Flush(); err != nil {
+		return err
+	}
+	return failure
+}
+
+// ambiguity returns error of interface or conversion that could not choose the definition.
+func ambiguity(provider internalProvider) error {
+	switch p := provider.(type) {
+	case *providerInterface:
+		_, err := p.Implementation()
+		return err
+	case *providerConversion:
+		_, err := p.Source()
+		return err
+	}
+	return nil
+}
+
+// explainReason describes why provider was chosen for parameter.
+func (c *Container) explainReason(param parameter, provider internalProvider) string {
+	k := provider.Key()
+	switch p := provider.(type) {
+	case *providerInterface:
+		def, err := p.Implementation()
+		switch {
+		case err != nil:
+			return fmt.Sprintf(": %s", err)
+		case len(p.impls) == 1:
+			return fmt.Sprintf(" resolved as %s: the only implementation", def)
+		default:
+			return fmt.Sprintf(" resolved as %s: primary of %d implementations", def, len(p.impls))
+		}
+	case *providerGroup:
+		return fmt.Sprintf(" of %d definitions", len(p.members))
+	case *providerFactory:
+		return fmt.Sprintf(" creates %s on each call", p.target)
+	case *providerConversion:
+		def, err := p.Source()
+		if err != nil {
+			return fmt.Sprintf(": %s", err)
+		}
+		return fmt.Sprintf(" converted from %s", def)
+	}
+	if k.tags != param.tags.String() {
+		return fmt.Sprintf(" matched by tags {%s}", param.tags)
+	}
+	return ""
+}
@@ -0,0 +1,80 @@
+package di
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Fingerprint returns a hash of the resolved graph of compiled container. Containers assembled from the same options
+// in different order have equal fingerprints unless the order changes the wiring. The hash covers:
+//
+//   - keys of definitions with lifetimes, constructor names and setters in call order;
+//   - dependency keys of each node, so implementations chosen for interfaces are included;
+//   - members of groups in resolution order if definitions depend on the groups.
+//
+// Order of group members is the only order dependent input: members without order markers go in provide order, so
+// reordering of modules that provide members of a group that is a dependency changes the fingerprint. Use
+// inject.Order() to fix the order. Groups that are not dependencies, for example ones that are created for each
+// interface, contribute their members only.
+// Source locations are not included. Fingerprint of not compiled container is empty.
+//
+//   require.Equal(t, first.Fingerprint(), second.Fingerprint())
+func (c *Container) Fingerprint() string {
+	if !c.isCompiled() {
+		return ""
+	}
+	c.storage.RLock()
+	defer c.storage.RUnlock()
+	dependencies := map[int32]bool{}
+	for _, node := range c.index.nodes {
+		for _, dep := range node.deps {
+			dependencies[dep] = true
+		}
+	}
+	lines := make([]string, 0, len(c.index.nodes))
+	for id, node := range c.index.nodes {
+		line := []string{fingerprintKey(node.provider.Key())}
+		if def := node.def; def != nil {
+			lifetime := Singleton
+			if def.prototype {
+				lifetime = Prototype
+			}
+			line = append(line, lifetime.String())
+			if ctor, ok := unwrapped(def.provider).(*providerConstructor); ok {
+				line = append(line, "ctor="+ctor.ctor.Name)
+			}
+			for _, s := range def.setters {
+				line = append(line, "setter="+s.method.Name)
+			}
+		}
+		var deps []string
+		for i, dep := range node.deps {
+			if dep < 0 {
+				param := node.params[i]
+				deps = append(deps, "missing="+fingerprintKey(key{name: param.name, res: param.res, tags: param.tags.String()}))
+				continue
+			}
+			deps = append(deps, "dep="+fingerprintKey(c.index.nodes[dep].provider.Key()))
+		}
+		if k := node.provider.Key(); k.typ == ptGroup && !dependencies[int32(id)] {
+			sort.Strings(deps)
+		}
+		lines = append(lines, strings.Join(append(line, deps...), " "))
+	}
+	sort.Strings(lines)
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+// fingerprintKey returns key with package paths of named types, so types with the same name from different packages
+// have different fingerprints.
+func fingerprintKey(k key) string {
+	kind, ok := dumpKinds[k.typ]
+	if !ok {
+		kind = "definition"
+	}
+	return fmt.Sprintf("%s/%s[%s]{%s}", kind, qualifiedTypeString(k.res), k.name, k.tags)
+}
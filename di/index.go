@@ -0,0 +1,138 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"sync/atomic"
+)
+
+// nodeIndex is a dense index of graph nodes built at compile. The graph is not changed after compile, so parameters
+// of each node are resolved once and resolution walks node identifiers instead of looking up keys. Keys are looked
+// up only for entry points: extract targets and invoke parameters.
+type nodeIndex struct {
+	ids   map[key]int32
+	nodes []indexedNode
+}
+
+// indexedNode is a provider with identifiers of its dependencies. Identifier is -1 if the parameter provider does
+// not exist.
+type indexedNode struct {
+	provider internalProvider
+	params   parameterList
+	deps     []int32
+	def      *definition // nil for interfaces, groups and parameter structs
+}
+
+// buildIndex indexes graph nodes and providers of their parameters.
+func (c *Container) buildIndex() {
+	c.index = nodeIndex{ids: map[key]int32{}}
+	for _, node := range c.graph.Nodes() {
+		c.indexProvider(node.Value.(internalProvider))
+	}
+	for _, def := range c.definitions {
+		if id, indexed := c.index.ids[def.key]; indexed {
+			c.index.nodes[id].def = def
+		}
+	}
+}
+
+// indexProvider adds provider into index and returns its identifier.
+func (c *Container) indexProvider(provider internalProvider) int32 {
+	k := provider.Key()
+	if id, indexed := c.index.ids[k]; indexed {
+		return id
+	}
+	id := int32(len(c.index.nodes))
+	c.index.ids[k] = id
+	params := provider.ParameterList()
+	c.index.nodes = append(c.index.nodes, indexedNode{provider: provider, params: params})
+	deps := make([]int32, len(params))
+	for i, param := range params {
+		registerTypeNames(param.res)
+		dependency, exists := param.ResolveProvider(c)
+		if !exists {
+			deps[i] = -1
+			continue
+		}
+		deps[i] = c.indexProvider(dependency)
+	}
+	c.index.nodes[id].deps = deps
+	return id
+}
+
+// indexed returns identifier of provider if it is indexed.
+func (c *Container) indexed(provider internalProvider) (int32, bool) {
+	id, indexed := c.index.ids[provider.Key()]
+	if !indexed || c.index.nodes[id].provider != provider {
+		return 0, false
+	}
+	return id, true
+}
+
+// resolveNode resolves value of indexed node. Parameters without providers are resolved as usual to get zero value
+// of optional parameter or not found error. Depth is a number of types in the resolution chain, dependent is the
+// nearest definition that requires the node or -1.
+func (c *Container) resolveNode(id int32, depth int, dependent int32) (reflect.Value, error) {
+	node := &c.index.nodes[id]
+	if err := c.deprecated(node.def, dependent); err != nil {
+		return reflect.Value{}, err
+	}
+	if value, cached := cachedValue(node.provider); cached {
+		c.dev.check(node.def, value)
+		return value, nil
+	}
+	if depth > c.maxDepth {
+		return reflect.Value{}, ErrResolutionTooDeep{limit: c.maxDepth, path: []key{node.provider.Key()}}
+	}
+	if node.def != nil {
+		dependent = id
+	}
+	if len(node.deps) == 0 {
+		return c.construct(node.def, node.provider, nil, false)
+	}
+	args := newArguments(len(node.deps))
+	for i, dep := range node.deps {
+		var value reflect.Value
+		var err error
+		if dep < 0 {
+			value, err = node.params[i].ResolveValue(c)
+		} else {
+			value, err = c.resolveNode(dep, depth+1, dependent)
+		}
+		if err != nil {
+			args.release()
+			return reflect.Value{}, withDependent(err, node.provider.Key())
+		}
+		args.values = append(args.values, value)
+	}
+	value, err := c.construct(node.def, node.provider, args.values, false)
+	args.release()
+	return value, err
+}
+
+// deprecated writes warning of the first resolution of deprecated definition or returns ErrDeprecated in strict mode.
+// It is called by indexed and not indexed resolutions of definitions, definitions that are not deprecated and nil
+// definition are skipped.
+func (c *Container) deprecated(def *definition, dependent int32) error {
+	if def == nil || def.deprecated == "" {
+		return nil
+	}
+	if c.strict {
+		return c.provideFailed(def.key, ErrDeprecated{message: def.deprecated})
+	}
+	if c.deprecation == nil || !atomic.CompareAndSwapUint32(&def.warned, 0, 1) {
+		return nil
+	}
+	by := "resolved directly"
+	if dependent >= 0 {
+		by = fmt.Sprintf("required by %s", c.index.nodes[dependent].def)
+	}
+	var location string
+	if def.location.File != "" {
+		location = def.location.String() + ": "
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(c.deprecation, "%s%s is deprecated: %s, %s\n", location, def, def.deprecated, by)
+	return nil
+}
@@ -0,0 +1,6 @@
+package ditest
+
+// Box is a generic type.
+type Box[T any] struct {
+	Value T
+}
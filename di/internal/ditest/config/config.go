@@ -0,0 +1,6 @@
+package config
+
+// Config has the same name as other/config.Config.
+type Config struct {
+	Name string
+}
@@ -0,0 +1,45 @@
+package ditest
+
+import "errors"
+
+// Orders refers to notifier with setter
+type Orders struct {
+	notifier *Notifier
+}
+
+// NewOrders
+func NewOrders() *Orders {
+	return &Orders{}
+}
+
+// SetNotifier
+func (o *Orders) SetNotifier(notifier *Notifier) {
+	o.notifier = notifier
+}
+
+// Notifier
+func (o *Orders) Notifier() *Notifier { return o.notifier }
+
+// Notifier refers to orders with setter
+type Notifier struct {
+	orders *Orders
+}
+
+// NewNotifier
+func NewNotifier() *Notifier {
+	return &Notifier{}
+}
+
+// SetOrders
+func (n *Notifier) SetOrders(orders *Orders) error {
+	n.orders = orders
+	return nil
+}
+
+// SetFoo always fails
+func (n *Notifier) SetFoo(foo *Foo) error {
+	return errors.New("foo is not supported")
+}
+
+// Orders
+func (n *Notifier) Orders() *Orders { return n.orders }
@@ -0,0 +1,6 @@
+package config
+
+// Config has the same name as ditest/config.Config.
+type Config struct {
+	Name string
+}
@@ -13,3 +13,17 @@ func NewQux(foo Fooer) *Qux {
 }
 
 func (q *Qux) Fooer() Fooer { return q.fooer }
+
+// CycleFooer
+type CycleFooer struct {
+	qux *Qux
+}
+
+// NewCycleFooer
+func NewCycleFooer(qux *Qux) *CycleFooer {
+	return &CycleFooer{
+		qux: qux,
+	}
+}
+
+func (f *CycleFooer) Foo() *Foo { return nil }
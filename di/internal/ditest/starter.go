@@ -0,0 +1,57 @@
+package ditest
+
+import (
+	"context"
+	"errors"
+)
+
+// Recorder records started components
+type Recorder struct {
+	Started []string
+}
+
+// Engine is started before car
+type Engine struct {
+	recorder *Recorder
+}
+
+// NewEngine
+func NewEngine(recorder *Recorder) *Engine {
+	return &Engine{recorder: recorder}
+}
+
+// Start
+func (e *Engine) Start(ctx context.Context) error {
+	e.recorder.Started = append(e.recorder.Started, "engine")
+	return nil
+}
+
+// Car depends on engine
+type Car struct {
+	engine   *Engine
+	recorder *Recorder
+}
+
+// NewCar
+func NewCar(engine *Engine, recorder *Recorder) *Car {
+	return &Car{engine: engine, recorder: recorder}
+}
+
+// Start
+func (c *Car) Start(ctx context.Context) error {
+	c.recorder.Started = append(c.recorder.Started, "car")
+	return nil
+}
+
+// Broken fails to start
+type Broken struct{}
+
+// NewBroken
+func NewBroken() *Broken {
+	return &Broken{}
+}
+
+// Start
+func (b *Broken) Start(ctx context.Context) error {
+	return errors.New("port is busy")
+}
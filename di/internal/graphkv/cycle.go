@@ -0,0 +1,106 @@
+package graphkv
+
+// ShortestCycle returns nodes of the shortest cycle and nodes of the strongly connected component that contains the
+// cycle. Each cycle node has an edge to the next one and the last node has an edge to the first. Returns nil if the
+// graph is acyclic.
+func (g *directedGraph) ShortestCycle() (cycle []Key, component []Key) {
+	for _, scc := range g.StronglyConnectedComponents() {
+		if len(scc) == 1 && !g.EdgeExists(scc[0], scc[0]) {
+			continue
+		}
+		members := make(map[Key]bool, len(scc))
+		for _, node := range scc {
+			members[node] = true
+		}
+		for _, node := range scc {
+			if found := g.shortestCycleFrom(node, members); cycle == nil || len(found) < len(cycle) {
+				cycle, component = found, scc
+			}
+		}
+	}
+	return cycle, component
+}
+
+// shortestCycleFrom finds the shortest cycle that starts from the node using the Breadth-first search. Search is
+// limited by the members of the strongly connected component of the node.
+func (g *directedGraph) shortestCycleFrom(start Key, members map[Key]bool) []Key {
+	parents := map[Key]Key{}
+	queue := []Key{start}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, outgoing := range g.OutgoingEdges(node) {
+			if !members[outgoing] {
+				continue
+			}
+			if outgoing == start {
+				cycle := []Key{node}
+				for cycle[0] != start {
+					cycle = append([]Key{parents[cycle[0]]}, cycle...)
+				}
+				return cycle
+			}
+			if _, visited := parents[outgoing]; visited {
+				continue
+			}
+			parents[outgoing] = node
+			queue = append(queue, outgoing)
+		}
+	}
+	return nil
+}
+
+// StronglyConnectedComponents returns strongly connected components of the graph using the Tarjan's algorithm.
+// Nodes of a component are in the discovery order.
+// See https://en.wikipedia.org/wiki/Tarjan%27s_strongly_connected_components_algorithm
+func (g *directedGraph) StronglyConnectedComponents() [][]Key {
+	var (
+		index      int
+		indices    = make(map[Key]int, g.NodeCount())
+		lowlinks   = make(map[Key]int, g.NodeCount())
+		onStack    = make(map[Key]bool)
+		stack      []Key
+		components [][]Key
+	)
+	var connect func(node Key)
+	connect = func(node Key) {
+		indices[node] = index
+		lowlinks[node] = index
+		index++
+		stack = append(stack, node)
+		onStack[node] = true
+
+		for _, outgoing := range g.OutgoingEdges(node) {
+			if _, visited := indices[outgoing]; !visited {
+				connect(outgoing)
+				if lowlinks[outgoing] < lowlinks[node] {
+					lowlinks[node] = lowlinks[outgoing]
+				}
+			} else if onStack[outgoing] && indices[outgoing] < lowlinks[node] {
+				lowlinks[node] = indices[outgoing]
+			}
+		}
+
+		// node is a root of the component
+		if lowlinks[node] != indices[node] {
+			return
+		}
+		var component []Key
+		for {
+			last := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[last] = false
+			component = append([]Key{last}, component...)
+			if last == node {
+				break
+			}
+		}
+		components = append(components, component)
+	}
+	for _, node := range g.Nodes() {
+		if _, visited := indices[node]; !visited {
+			connect(node)
+		}
+	}
+	return components
+}
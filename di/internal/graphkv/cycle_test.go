@@ -0,0 +1,63 @@
+package graphkv
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShortestCycle(t *testing.T) {
+	graph := newDirectedGraph()
+	graph.AddNodes(0, 1, 2, 3, 4, 5)
+	graph.AddEdge(0, 1)
+	graph.AddEdge(1, 2)
+	graph.AddEdge(2, 3)
+	graph.AddEdge(3, 4)
+	graph.AddEdge(4, 1)
+	graph.AddEdge(3, 2)
+	graph.AddEdge(4, 5)
+
+	cycle, component := graph.ShortestCycle()
+
+	assert.Equal(t, []Key{2, 3}, cycle, "graph.ShortestCycle() cycle should equal [2, 3]")
+	assert.Equal(t, []Key{1, 2, 3, 4}, component, "graph.ShortestCycle() component should equal [1, 2, 3, 4]")
+}
+
+func TestShortestCycleSelfLoop(t *testing.T) {
+	graph := newDirectedGraph()
+	graph.AddNodes(0, 1, 2)
+	graph.AddEdge(0, 1)
+	graph.AddEdge(1, 0)
+	graph.AddEdge(2, 2)
+
+	cycle, component := graph.ShortestCycle()
+
+	assert.Equal(t, []Key{2}, cycle, "graph.ShortestCycle() cycle should equal [2]")
+	assert.Equal(t, []Key{2}, component, "graph.ShortestCycle() component should equal [2]")
+}
+
+func TestShortestCycleAcyclic(t *testing.T) {
+	graph := newDirectedGraph()
+	graph.AddNodes(0, 1, 2)
+	graph.AddEdge(0, 1)
+	graph.AddEdge(0, 2)
+	graph.AddEdge(1, 2)
+
+	cycle, component := graph.ShortestCycle()
+
+	assert.Nil(t, cycle, "graph.ShortestCycle() cycle should be nil")
+	assert.Nil(t, component, "graph.ShortestCycle() component should be nil")
+}
+
+func TestStronglyConnectedComponents(t *testing.T) {
+	graph := newDirectedGraph()
+	graph.AddNodes(0, 1, 2, 3)
+	graph.AddEdge(0, 1)
+	graph.AddEdge(1, 0)
+	graph.AddEdge(1, 2)
+	graph.AddEdge(2, 3)
+
+	components := graph.StronglyConnectedComponents()
+
+	assert.Equal(t, [][]Key{{3}, {2}, {0, 1}}, components, "graph.StronglyConnectedComponents() should equal [[3] [2] [0 1]]")
+}
@@ -3,22 +3,35 @@ package graphkv
 // directedGraph is a graph supporting directed edges between nodes.
 type directedGraph struct {
 	*graph
-	edges *directedEdgeList
+	edges    *directedEdgeList
+	optional map[edge]bool // optional edges
+}
+
+// edge is a directed edge between nodes.
+type edge struct {
+	from Key
+	to   Key
 }
 
 // newDirectedGraph creates a graph of nodes with directed edges.
 func newDirectedGraph() *directedGraph {
 	return &directedGraph{
-		graph: newGraph(),
-		edges: newDirectedEdgeList(),
+		graph:    newGraph(),
+		edges:    newDirectedEdgeList(),
+		optional: map[edge]bool{},
 	}
 }
 
 // Copy returns a clone of the directed graph.
 func (g *directedGraph) Copy() *directedGraph {
+	optional := make(map[edge]bool, len(g.optional))
+	for e := range g.optional {
+		optional[e] = true
+	}
 	return &directedGraph{
-		graph: g.graph.Copy(),
-		edges: g.edges.Copy(),
+		graph:    g.graph.Copy(),
+		edges:    g.edges.Copy(),
+		optional: optional,
 	}
 }
 
@@ -38,11 +51,27 @@ func (g *directedGraph) AddEdge(from Key, to Key) {
 	}
 
 	g.edges.Add(from, to)
+	delete(g.optional, edge{from, to})
+}
+
+// AddOptionalEdge adds the edge that is marked as optional. Marker is removed if the edge is added as required.
+func (g *directedGraph) AddOptionalEdge(from Key, to Key) {
+	if g.EdgeExists(from, to) {
+		return
+	}
+	g.AddEdge(from, to)
+	g.optional[edge{from, to}] = true
+}
+
+// IsOptionalEdge checks whether the edge is optional.
+func (g *directedGraph) IsOptionalEdge(from Key, to Key) bool {
+	return g.optional[edge{from, to}]
 }
 
 // RemoveEdge removes the edge from the graph.
 func (g *directedGraph) RemoveEdge(from Key, to Key) {
 	g.edges.Remove(from, to)
+	delete(g.optional, edge{from, to})
 }
 
 // HasEdges determines whether the graph contains any edges to or from the node.
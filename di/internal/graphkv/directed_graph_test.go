@@ -43,6 +43,22 @@ func TestDirectedGraphAddEdgeDuplicate(t *testing.T) {
 	assert.True(t, graph.EdgeExists("B", "C"), "graph.EdgeExists(B, C) should equal true")
 }
 
+func TestDirectedGraphAddOptionalEdge(t *testing.T) {
+	graph := newTestDirectedGraph()
+	graph.AddOptionalEdge("A", "B")
+	graph.AddOptionalEdge("B", "C")
+	graph.AddEdge("B", "C")
+	graph.AddEdge("C", "D")
+	graph.AddOptionalEdge("C", "D")
+
+	assert.Equal(t, 3, graph.EdgeCount(), "graph.EdgeCount() should equal 3")
+	assert.True(t, graph.IsOptionalEdge("A", "B"), "graph.IsOptionalEdge(A, B) should equal true")
+	assert.False(t, graph.IsOptionalEdge("B", "C"), "graph.IsOptionalEdge(B, C) should equal false")
+	assert.False(t, graph.IsOptionalEdge("C", "D"), "graph.IsOptionalEdge(C, D) should equal false")
+	graph.RemoveEdge("A", "B")
+	assert.False(t, graph.IsOptionalEdge("A", "B"), "graph.IsOptionalEdge(A, B) should equal false")
+}
+
 func TestDirectedGraphAddEdgeMissingNodes(t *testing.T) {
 	graph := newDirectedGraph()
 	graph.AddEdge("A", "B")
@@ -44,11 +44,28 @@ func (g *Graph) Add(key Key, value interface{}) {
 	g.values[key] = value
 }
 
+// Remove removes node with its edges.
+func (g *Graph) Remove(key Key) {
+	for _, to := range g.dag.OutgoingEdges(key) {
+		g.dag.RemoveEdge(key, to)
+	}
+	for _, from := range g.dag.IncomingEdges(key) {
+		g.dag.RemoveEdge(from, key)
+	}
+	g.dag.RemoveNode(key)
+	delete(g.values, key)
+}
+
 // Edge
 func (g *Graph) Edge(from Key, to Key) {
 	g.dag.AddEdge(from, to)
 }
 
+// OptionalEdge adds edge of optional dependency. Optional edges are dashed in DOT graph.
+func (g *Graph) OptionalEdge(from Key, to Key) {
+	g.dag.AddOptionalEdge(from, to)
+}
+
 // Exists
 func (g *Graph) Exists(key Key) bool {
 	return g.dag.NodeExists(key)
@@ -63,13 +80,24 @@ func (g *Graph) Nodes() []Node {
 	return nodes
 }
 
-// CheckCycles
-func (g *Graph) CheckCycles() error {
-	_, err := g.dag.DFSSort()
-	return err // todo: errors
+// ShortestCycle returns nodes of the shortest cycle and its strongly connected component. See
+// directedGraph.ShortestCycle().
+func (g *Graph) ShortestCycle() (cycle []Key, component []Key) {
+	return g.dag.ShortestCycle()
+}
+
+// Sort returns keys in topological order: nodes go after nodes of their incoming edges. The order is stable for the
+// same sequence of added nodes and edges.
+func (g *Graph) Sort() ([]Key, error) {
+	return g.dag.DFSSort()
+}
+
+// DOTGraph returns graph in DOT format. Decorate changes attributes of visualized nodes, it could be nil.
+func (g *Graph) DOTGraph(decorate func(key Key, node *dot.Node)) *dot.Graph {
+	return g.dag.DOTGraph(decorate)
 }
 
-// DOTGraph
-func (g *Graph) DOTGraph() *dot.Graph {
-	return g.dag.DOTGraph()
+// OutgoingEdges returns keys of nodes that have incoming edge from the node.
+func (g *Graph) OutgoingEdges(key Key) []Key {
+	return g.dag.OutgoingEdges(key)
 }
@@ -1,21 +1,21 @@
 package graphkv
 
 import (
-	"fmt"
-
 	"github.com/emicklei/dot"
 )
 
 // NodeVisualizer
 type NodeVisualizer interface {
 	Visualize(node *dot.Node)
+	ID() string
 	SubGraph() string
 	IsAlwaysVisible() bool
 }
 
 // DOTGraph returns a textual representation of the graph in the DOT graph
-// description language.
-func (g *directedGraph) DOTGraph() *dot.Graph {
+// description language. Decorate is called for each visible node after it
+// is visualized, it could be nil.
+func (g *directedGraph) DOTGraph(decorate func(node Key, item *dot.Node)) *dot.Graph {
 	root := dot.NewGraph(dot.Directed)
 	root.Attr("splines", "ortho")
 
@@ -28,7 +28,7 @@ func (g *directedGraph) DOTGraph() *dot.Graph {
 			continue
 		}
 
-		name := fmt.Sprintf("%s", node)
+		name := nv.ID()
 		subgraph, ok := subgraphs[nv.SubGraph()]
 		if !ok {
 			subgraph = root.Subgraph(nv.SubGraph(), dot.ClusterOption{})
@@ -37,6 +37,9 @@ func (g *directedGraph) DOTGraph() *dot.Graph {
 		}
 		item := subgraph.Node(name)
 		nv.Visualize(&item)
+		if decorate != nil {
+			decorate(node, &item)
+		}
 		itemsByNode[node] = item
 
 	}
@@ -44,7 +47,10 @@ func (g *directedGraph) DOTGraph() *dot.Graph {
 	for fromNode, fromItem := range itemsByNode {
 		for _, toNode := range g.OutgoingEdges(fromNode) {
 			if toItem, ok := itemsByNode[toNode]; ok {
-				root.Edge(fromItem, toItem).Attr("color", "#949494")
+				e := root.Edge(fromItem, toItem).Attr("color", "#949494")
+				if g.IsOptionalEdge(fromNode, toNode) {
+					e.Attr("style", "dashed")
+				}
 			}
 		}
 	}
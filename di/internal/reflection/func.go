@@ -13,11 +13,24 @@ func IsFunc(value interface{}) bool {
 
 // Func
 type Func struct {
-	Name string
+	Name     string
+	Location Location
 	reflect.Type
 	reflect.Value
 }
 
+// Location is a source code position.
+type Location struct {
+	File     string
+	Line     int
+	Function string
+}
+
+// String
+func (l Location) String() string {
+	return fmt.Sprintf("%s:%d", l.File, l.Line)
+}
+
 // InspectFunction
 func InspectFunction(fn interface{}) *Func {
 	if !IsFunc(fn) {
@@ -26,10 +39,12 @@ func InspectFunction(fn interface{}) *Func {
 
 	val := reflect.ValueOf(fn)
 	fnpc := runtime.FuncForPC(val.Pointer())
+	file, line := fnpc.FileLine(fnpc.Entry())
 
 	return &Func{
-		Name:  fnpc.Name(),
-		Type:  val.Type(),
-		Value: val,
+		Name:     fnpc.Name(),
+		Location: Location{File: file, Line: line, Function: fnpc.Name()},
+		Type:     val.Type(),
+		Value:    val,
 	}
 }
@@ -6,16 +6,24 @@ import (
 )
 
 // InspectInterfacePtr
-func InspectInterfacePtr(iface interface{}) *Interface {
+func InspectInterfacePtr(iface interface{}) (*Interface, error) {
 	typ := reflect.TypeOf(iface)
-	if typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Interface {
-		panic(fmt.Sprintf("%s: not a pointer to interface", typ)) // todo: improve message
+	if typ == nil {
+		return nil, fmt.Errorf("got nil, pass a pointer to interface like new(Interface) instead of interface value")
+	}
+	if typ.Kind() != reflect.Ptr {
+		return nil, fmt.Errorf("got %s %s", typ.Kind(), typ)
+	}
+	if typ.Elem().Kind() != reflect.Interface {
+		return nil, fmt.Errorf("got pointer to %s %s", typ.Elem().Kind(), typ)
+	}
+	if reflect.ValueOf(iface).IsNil() {
+		return nil, fmt.Errorf("got nil %s, use new(%s)", typ, typ.Elem())
 	}
-
 	return &Interface{
 		Name: typ.Elem().Name(),
 		Type: typ.Elem(),
-	}
+	}, nil
 }
 
 // Interface
@@ -1,9 +1,14 @@
 package reflection
 
-import "reflect"
+import (
+	"reflect"
+	"sync"
+)
 
 var errorInterface = reflect.TypeOf(new(error)).Elem()
 
+var lockerInterface = reflect.TypeOf(new(sync.Locker)).Elem()
+
 // IsError
 func IsError(typ reflect.Type) bool {
 	return typ.Implements(errorInterface)
@@ -18,3 +23,37 @@ func IsCleanup(typ reflect.Type) bool {
 func IsPtr(value interface{}) bool {
 	return reflect.ValueOf(value).Kind() == reflect.Ptr
 }
+
+// ContainedLock returns type of the first lock that the type contains by value: types of sync and sync/atomic packages
+// and types with pointer Lock() method like noCopy markers. Copy of such type copies the lock.
+func ContainedLock(typ reflect.Type) (reflect.Type, bool) {
+	switch typ.Kind() {
+	case reflect.Array:
+		return ContainedLock(typ.Elem())
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i).Type
+			if isSyncType(field) {
+				return field, true
+			}
+			if lock, ok := ContainedLock(field); ok {
+				return lock, true
+			}
+			if isLocker(field) {
+				return field, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// isSyncType checks that type is declared in sync or sync/atomic package.
+func isSyncType(typ reflect.Type) bool {
+	pkg := typ.PkgPath()
+	return pkg == "sync" || pkg == "sync/atomic"
+}
+
+// isLocker checks that struct has pointer Lock() and Unlock() methods.
+func isLocker(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && reflect.PtrTo(typ).Implements(lockerInterface) && !typ.Implements(lockerInterface)
+}
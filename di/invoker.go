@@ -50,11 +50,19 @@ func newInvoker(fn interface{}) (*invoker, error) {
 
 func (i *invoker) Invoke(c *Container) error {
 	plist := i.parameters()
-	values, err := plist.Resolve(c)
+	args, err := plist.Resolve(c)
+	c.stats.resolved(err)
 	if err != nil {
-		return fmt.Errorf("could not resolve invoke parameters: %s", err)
+		return fmt.Errorf("could not resolve invoke parameters: %w", err)
+	}
+	var results []reflect.Value
+	err = c.recovered(func() {
+		results = i.fn.Call(args.values)
+	})
+	args.release()
+	if err != nil {
+		return err
 	}
-	results := i.fn.Call(values)
 	if len(results) == 0 {
 		return nil
 	}
@@ -12,14 +12,75 @@ type key struct {
 	name string
 	res  reflect.Type
 	typ  providerType
+	tags string // canonical tags representation
+}
+
+// Key identifies a definition by type, name and tags.
+type Key struct {
+	typ   reflect.Type
+	name  string
+	tags  string
+	alias bool
+}
+
+// Type returns definition type.
+func (k Key) Type() reflect.Type {
+	return k.typ
+}
+
+// Name returns definition name.
+func (k Key) Name() string {
+	return k.name
+}
+
+// IsAlias reports that key is an interface that definitions are bound to.
+func (k Key) IsAlias() bool {
+	return k.alias
+}
+
+func (k Key) String() string {
+	return key{name: k.name, res: k.typ, tags: k.tags}.String()
+}
+
+// export returns public representation of key.
+func (k key) export() Key {
+	return Key{typ: k.res, name: k.name, tags: k.tags}
 }
 
 // String represent resultKey as string.
 func (k key) String() string {
-	if k.name == "" {
-		return fmt.Sprintf("%s", k.res)
+	res := renderType(k.res)
+	if isAnonymousStruct(k.res) && k.res.Kind() == reflect.Ptr {
+		res = "*anonymous struct"
+	} else if isAnonymousStruct(k.res) {
+		res = "anonymous struct"
+	}
+	return k.format(res)
+}
+
+// ID returns unique identifier of the key node in DOT graph. Types are rendered with package paths, so types with
+// the same name from different packages are different nodes.
+func (k key) ID() string {
+	return k.format(qualifiedTypeString(k.res))
+}
+
+// format renders the key with rendered result type.
+func (k key) format(res string) string {
+	if k.name != "" {
+		res = fmt.Sprintf("%s[%s]", res, k.name)
+	}
+	if k.tags != "" {
+		res = fmt.Sprintf("%s{%s}", res, k.tags)
+	}
+	return res
+}
+
+// isAnonymousStruct checks that type is a struct or a pointer to struct without type name.
+func isAnonymousStruct(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
 	}
-	return fmt.Sprintf("%s[%s]", k.res, k.name)
+	return typ.Kind() == reflect.Struct && typ.Name() == ""
 }
 
 // IsAlwaysVisible
@@ -50,10 +111,10 @@ func (k key) Visualize(node *dot.Node) {
 	case ptConstructor:
 		node.Attr("shape", "box")
 		node.Attr("color", "#46494C")
-	case ptGroup:
+	case ptGroup, ptRegistry:
 		node.Attr("shape", "doubleoctagon")
 		node.Attr("color", "#E54B4B")
-	case ptInterface:
+	case ptInterface, ptConversion, ptFactory, ptAlias, ptFallback:
 		node.Attr("color", "#2589BD")
 	case ptEmbedParameter:
 		node.Attr("shape", "box")
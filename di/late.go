@@ -0,0 +1,41 @@
+package di
+
+// checkLate checks that ordinary definitions do not depend on late ones. Dependencies through interfaces, groups and
+// aliases are checked too, so a late definition could not be reached by ordinary definition in any way. It must be
+// called after the index is built.
+func (c *Container) checkLate() {
+	for _, def := range c.definitions {
+		if def.late {
+			continue
+		}
+		id, indexed := c.index.ids[def.key]
+		if !indexed {
+			continue
+		}
+		c.step = step{stage: StageConnect, key: def.key}
+		if late := c.lateDependency(id, map[int32]bool{}); late != nil {
+			panicf("%s: %s depends on %s provided late at %s, only late definitions could depend on it",
+				def.location, def, late, late.location)
+		}
+	}
+}
+
+// lateDependency returns late definition that node depends on directly or through nodes that are not definitions.
+func (c *Container) lateDependency(id int32, visited map[int32]bool) *definition {
+	for _, dep := range c.index.nodes[id].deps {
+		if dep < 0 || visited[dep] {
+			continue
+		}
+		visited[dep] = true
+		if def := c.index.nodes[dep].def; def != nil {
+			if def.late {
+				return def
+			}
+			continue
+		}
+		if late := c.lateDependency(dep, visited); late != nil {
+			return late
+		}
+	}
+	return nil
+}
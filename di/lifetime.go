@@ -0,0 +1,54 @@
+package di
+
+import "github.com/defval/inject/v2/di/internal/reflection"
+
+// LifetimeSource is a source of the effective lifetime of definition. Lifetime is resolved in order: provide option,
+// default lifetime of module, default lifetime of container.
+type LifetimeSource string
+
+const (
+	// LifetimeFromProvide is a lifetime set by provide option or required by the provider, for example values are
+	// singletons.
+	LifetimeFromProvide LifetimeSource = "provide"
+	// LifetimeFromModule is a default lifetime of the module that provides the definition.
+	LifetimeFromModule LifetimeSource = "module"
+	// LifetimeFromContainer is a default lifetime of container, singleton unless SetDefaultLifetime() is called.
+	LifetimeFromContainer LifetimeSource = "container"
+)
+
+// SetDefaultLifetime sets lifetime of definitions that have no lifetime set by provide option or module, for example
+// prototype for command line tools that resolve each type once. Definitions that must be shared, like connection
+// pools, pin their lifetime with IsSingleton.
+func (c *Container) SetDefaultLifetime(lifetime Lifetime) {
+	c.storage.Lock()
+	defer c.storage.Unlock()
+	c.mustNotCompiled()
+	c.lifetime = lifetime
+}
+
+// effectiveLifetime resolves lifetime of provider and its source.
+func (c *Container) effectiveLifetime(provider internalProvider, location reflection.Location, params ProvideParams) (Lifetime, LifetimeSource) {
+	if params.IsPrototype && params.IsSingleton {
+		panicf("%s: %s could not be singleton and prototype", location, provider.Key())
+	}
+	switch {
+	case params.IsPrototype:
+		return Prototype, LifetimeFromProvide
+	case params.IsSingleton:
+		return Singleton, LifetimeFromProvide
+	}
+	switch p := provider.(type) {
+	case *providerValue:
+		return Singleton, LifetimeFromProvide
+	case *providerStruct:
+		if p.instance {
+			return Singleton, LifetimeFromProvide
+		}
+	}
+	switch {
+	case params.ModuleLifetime != nil:
+		return *params.ModuleLifetime, LifetimeFromModule
+	default:
+		return c.lifetime, LifetimeFromContainer
+	}
+}
@@ -1,5 +1,10 @@
 package di
 
+import (
+	"reflect"
+	"time"
+)
+
 // ExtractOption
 type ProvideOption interface {
 	apply(params *ProvideParams)
@@ -13,11 +18,105 @@ func (o provideOption) apply(params *ProvideParams) {
 
 // ProvideParams is a `Provide()` method options. Name is a unique identifier of type instance. Provider is a constructor
 // function. Interfaces is a interface that implements a provider result type.
+//
+// Tags are definition attributes. Definitions of the same type can differ by tags only.
+//
+// IsReplacement replaces the existing definition with the same key instead of duplicate error. The replacement
+// takes the place of the replaced definition: its position and markers are kept. If the key does not exist, the
+// replacement replaces the binding of its interface result type or of its Interfaces: it becomes the only
+// implementation of the interface and the only member of the interface group.
+//
+// Order and IsPrimary are markers that make resolution independent of registration order. Order sorts definitions
+// inside groups, definitions with equal order keep registration order. IsPrimary picks the implementation of interface
+// that has several implementations.
+//
+// Singleton caches the construction error by default: later resolutions return it without calling the constructor.
+// IsRetryOnError calls the constructor again on the next resolution instead.
+//
+// RetryAttempts is a number of constructor calls inside one resolution. The calls are separated by RetryBackoff delay,
+// IsExponentialBackoff doubles the delay after each failed call.
+//
+// IsExclusive makes the definition reachable only through its Interfaces, the definition type itself does not resolve.
+//
+// IsAppend appends slice or map value to the value definition of the same type instead of duplicate error. Map keys
+// of appended values must be disjoint. Location is a source location of the value, it is used in errors.
+//
+// Groups are names of collections the definition joins. The definition is a member of the slice of its type and of
+// each of its Interfaces. Consumers resolve the collection by the group name.
+//
+// Deprecated is a deprecation message of the definition. The first resolution of deprecated definition writes a
+// warning with the message and the dependent definition, see SetDeprecationLog() and SetStrictDeprecation().
+//
+// Description is a human readable purpose of the definition. It is shown by diagnostics: dumps, graph, Explain() and
+// ambiguity errors.
+//
+// IsDedup treats registration of the same constructor with the same markers, interfaces and groups as the existing
+// definition of the type as that definition instead of duplicate error. Modules that declare their own dependencies
+// could provide shared constructors. Different constructor of the type still causes panic with both locations.
+//
+// Condition is a function like `func([value1, value2, ...]) bool` that is called on provide. Its parameters are
+// values and struct instances provided before. The definition is registered only if the condition returns true,
+// skipped definitions are findings of Report().
+//
+// IsAllowNil allows providing nil channel value. Operations on nil channel block forever, so it is an error by
+// default.
+//
+// Names are additional names of the definition. The definition resolves by each of them as well as by Name, all names
+// share the same instance.
+//
+// Setters are names of methods of the definition type that are called with resolved arguments after the instance is
+// created. Setter arguments are not dependencies of the definition, so definitions could refer to each other.
+//
+// IsRegistry adds the definition to registries of its type and of each of its Interfaces. Registry of type T is
+// a map of definition names: map[string]T resolves to all instances, map[string]func() (T, error) resolves to
+// factories that create instances on call. Registry definition requires a name, definitions with the same name in one
+// registry cause panic with both locations.
+//
+// IsSingleton pins singleton lifetime regardless of default lifetimes. ModuleLifetime is a default lifetime of the
+// module that provides the definition, nil if the module has none. Lifetime is resolved in order: IsPrototype or
+// IsSingleton, ModuleLifetime, default lifetime of container, see SetDefaultLifetime().
+//
+// Cleanup releases provided value. Values with cleanup are resolved like constructors without parameters: the cleanup
+// runs on container cleanup only if the value was resolved, in order of creation with cleanups of constructors.
+// Constructors return their cleanups instead.
+//
+// IsAllowCopy allows definition type that contains sync or sync/atomic types by value. Each consumer gets a copy of
+// the instance with its own copy of the lock, so such types must be provided by pointer unless copying is fine.
+//
+// IsLate marks constructor that computes its result from connected definitions, for example, route table from
+// the group of handlers. Late definitions are built after ordinary ones by BuildAll(), only late definitions could
+// depend on them, Extract() and Invoke() resolve them as usual. Compile panics if ordinary definition depends on late
+// one.
 type ProvideParams struct {
-	Name        string
-	Interfaces  []interface{}
-	Parameters  ParameterBag
-	IsPrototype bool
+	Name                 string
+	Names                []string
+	Interfaces           []interface{}
+	Parameters           ParameterBag
+	IsPrototype          bool
+	IsSingleton          bool
+	ModuleLifetime       *Lifetime
+	Order                int
+	IsPrimary            bool
+	IsReplacement        bool
+	IsRetryOnError       bool
+	RetryAttempts        int
+	RetryBackoff         time.Duration
+	IsExponentialBackoff bool
+	IsExclusive          bool
+	IsAppend             bool
+	Location             Location
+	Groups               []string
+	Tags                 Tags
+	Deprecated           string
+	Description          string
+	IsDedup              bool
+	Condition            interface{}
+	IsAllowNil           bool
+	IsAllowCopy          bool
+	Setters              []string
+	IsRegistry           bool
+	Cleanup              func()
+	IsLate               bool
 }
 
 func (p ProvideParams) apply(params *ProvideParams) {
@@ -31,6 +130,21 @@ func As(interfaces ...interface{}) ProvideOption {
 	})
 }
 
+// BindParams is a `Bind()` method options. Name is a name of the interface key, the definition keeps its own name, so
+// one interface could name several roles of unnamed definitions.
+type BindParams struct {
+	Name string
+}
+
+func (p BindParams) apply(params *BindParams) {
+	*params = p
+}
+
+// BindOption
+type BindOption interface {
+	apply(params *BindParams)
+}
+
 // InvokeParams is a invoke parameters.
 type InvokeParams struct{}
 
@@ -43,9 +157,16 @@ type InvokeOption interface {
 	apply(params *InvokeParams)
 }
 
-// ExtractParams
+// ExtractParams is a `Extract()` method options. Name is a definition name. Tags select definition that contains
+// all of them. IsFresh creates a new instance of the definition bypassing its singleton cache. Group is a name of
+// the collection to extract into slice or map target, group without members extracts as empty collection. Type is
+// a type to extract if it is known at runtime only, the target must be a pointer to a type it is assignable to.
 type ExtractParams struct {
-	Name string
+	Name    string
+	Tags    Tags
+	IsFresh bool
+	Group   string
+	Type    reflect.Type
 }
 
 func (p ExtractParams) apply(params *ExtractParams) {
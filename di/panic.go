@@ -5,3 +5,28 @@ import "fmt"
 func panicf(format string, a ...interface{}) {
 	panic(fmt.Sprintf(format, a...))
 }
+
+// RecoverMode is a policy of panics in constructors and invoked functions.
+type RecoverMode int
+
+const (
+	// Propagate does not recover panics, they crash with the original stack.
+	Propagate RecoverMode = iota
+	// WrapError recovers panics and returns them as ErrPanic.
+	WrapError
+)
+
+// recovered calls fn and returns its panic as ErrPanic if the container wraps panics.
+func (c *Container) recovered(fn func()) (err error) {
+	if c.recoverMode != WrapError {
+		fn()
+		return nil
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			err = newErrPanic(r)
+		}
+	}()
+	fn()
+	return nil
+}
@@ -13,50 +13,146 @@ type Parameter struct {
 type parameter struct {
 	name     string
 	res      reflect.Type
+	tags     Tags
 	optional bool
 	embed    bool
+	fresh    bool   // bypass singleton cache of the parameter definition
+	impl     bool   // implementation of interface or group member, resolves exclusive definitions
+	group    string // name of collection, resolves as empty slice if group has no members
 }
 
 func (p parameter) String() string {
-	return key{name: p.name, res: p.res}.String()
+	return key{name: p.name, res: p.res, tags: p.tags.String()}.String()
 }
 
 // ResolveProvider resolves parameter provider
 func (p parameter) ResolveProvider(c *Container) (internalProvider, bool) {
+	if p.group != "" {
+		k := key{name: p.group, res: p.res, typ: ptGroup}
+		if c.graph.Exists(k) {
+			return c.graph.Get(k).Value.(internalProvider), true
+		}
+		if p.res.Kind() == reflect.Map {
+			var group *providerGroup
+			if members := (key{name: p.group, res: reflect.SliceOf(p.res.Elem()), typ: ptGroup}); c.graph.Exists(members) {
+				group = c.graph.Get(members).Value.(*providerGroup)
+			}
+			return newProviderMapGroup(k, group), true
+		}
+		return &providerGroup{result: k}, true
+	}
 	for _, pt := range providerLookupSequence {
 		k := key{
 			name: p.name,
 			res:  p.res,
 			typ:  pt,
+			tags: p.tags.String(),
 		}
 		if !c.graph.Exists(k) {
 			continue
 		}
+		if pt == ptConstructor && !p.impl && c.isExclusive(k) {
+			continue
+		}
 		node := c.graph.Get(k)
 		return node.Value.(internalProvider), true
 	}
-	return nil, false
+	if p.embed {
+		// parameter struct of invoke function or extract target is not registered by constructors
+		return newProviderEmbed(p, c.unexported), true
+	}
+	if len(p.tags) == 0 && !c.explicit {
+		if iface, ok := c.embeddedInterface(p.name, p.res); ok {
+			return iface, true
+		}
+	}
+	if provider, exists := c.selectedProvider(p); exists {
+		return provider, true
+	}
+	if provider, exists := c.conversionProvider(p); exists {
+		return provider, true
+	}
+	if provider, exists := c.registryFactoryProvider(p); exists {
+		return provider, true
+	}
+	if provider, exists := c.factoryProvider(p); exists {
+		return provider, true
+	}
+	return c.fallbackProvider(p)
 }
 
+// ResolveValue resolves parameter value. The storage read lock is held only during provider lookup, because
+// providers could extract types from the container.
 func (p parameter) ResolveValue(c *Container) (reflect.Value, error) {
-	provider, exists := p.ResolveProvider(c)
+	var provider internalProvider
+	var exists bool
+	var notFound ErrParameterProviderNotFound
+	c.read(func() {
+		provider, exists = p.ResolveProvider(c)
+		if !exists && !p.optional {
+			notFound = c.notFound(p)
+		}
+	})
 	if !exists && p.optional {
 		return reflect.New(p.res).Elem(), nil
 	}
 	if !exists {
-		return reflect.Value{}, ErrParameterProviderNotFound{param: p}
+		return reflect.Value{}, notFound
+	}
+	if p.fresh {
+		fresh, err := freshProvider(provider)
+		if err != nil {
+			return reflect.Value{}, c.provideFailed(provider.Key(), err)
+		}
+		provider = fresh
+	}
+	if id, indexed := c.indexed(provider); indexed {
+		return c.resolveNode(id, 1, -1)
+	}
+	var def *definition
+	c.read(func() {
+		def = c.definition(provider.Key())
+	})
+	if err := c.deprecated(def, -1); err != nil {
+		return reflect.Value{}, err
+	}
+	if value, cached := cachedValue(provider); cached {
+		return value, nil
 	}
 	pl := provider.ParameterList()
-	values, err := pl.Resolve(c)
+	if len(pl) == 0 {
+		return c.construct(def, provider, nil, p.fresh)
+	}
+	args, err := pl.Resolve(c)
 	if err != nil {
-		return reflect.Value{}, err
+		return reflect.Value{}, withDependent(err, provider.Key())
+	}
+	value, err := c.construct(def, provider, args.values, p.fresh)
+	args.release()
+	return value, err
+}
+
+// call calls provider with resolved arguments and registers its cleanup. Panic of the provider is returned as error
+// if the container wraps panics.
+func (c *Container) call(provider internalProvider, values []reflect.Value) (value reflect.Value, err error) {
+	if c.recoverMode == WrapError {
+		defer func() {
+			if r := recover(); r != nil {
+				value, err = reflect.Value{}, c.provideFailed(provider.Key(), newErrPanic(r))
+			}
+		}()
 	}
 	value, cleanup, err := provider.Provide(values...)
 	if err != nil {
-		return value, ErrParameterProvideFailed{k: provider.Key(), err: err}
+		return value, c.provideFailed(provider.Key(), err)
 	}
-	if cleanup != nil {
-		c.cleanups = append(c.cleanups, cleanup)
+	if cleanup == nil {
+		return value, nil
+	}
+	if inherited, ok := provider.(*providerInherited); ok {
+		inherited.owner.addCleanup(cleanup)
+	} else {
+		c.addCleanup(cleanup)
 	}
 	return value, nil
 }
@@ -66,6 +162,11 @@ func isEmbedParameter(typ reflect.Type) bool {
 	return typ.Kind() == reflect.Struct && typ.Implements(parameterInterface)
 }
 
+// isGroupType checks that type could be resolved as named group.
+func isGroupType(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Slice || typ.Kind() == reflect.Map && typ.Key().Kind() == reflect.String
+}
+
 // internalParameter
 type internalParameter interface {
 	isDependencyInjectionParameter()
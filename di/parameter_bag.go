@@ -7,7 +7,7 @@ import (
 
 // createParameterBugProvider
 func createParameterBugProvider(key key, parameters ParameterBag) internalProvider {
-	return newProviderConstructor(key.String(), func() ParameterBag { return parameters })
+	return newProviderConstructor(key.String(), nil, func() ParameterBag { return parameters })
 }
 
 // parameterBagType
@@ -1,20 +1,57 @@
 package di
 
-import "reflect"
+import (
+	"reflect"
+	"sync"
+)
 
 // parameterList
 type parameterList []parameter
 
-// ResolveValues loads all parameters presented in parameter list.
-func (pl parameterList) Resolve(c *Container) ([]reflect.Value, error) {
-	var values []reflect.Value
+// ResolveValues loads all parameters presented in parameter list. Returned arguments must be released after the
+// provider call.
+func (pl parameterList) Resolve(c *Container) (*arguments, error) {
+	args := newArguments(len(pl))
 	for _, p := range pl {
 		value, err := p.ResolveValue(c)
 		if err != nil {
+			args.release()
 			return nil, err
 		}
-		values = append(values, value)
+		args.values = append(args.values, value)
 	}
 
-	return values, nil
+	return args, nil
+}
+
+// argumentsPool reuses argument slices of provider calls, because resolving of prototypes calls providers on each
+// resolution.
+var argumentsPool = sync.Pool{
+	New: func() interface{} {
+		return &arguments{}
+	},
+}
+
+// arguments are resolved values of parameter list.
+type arguments struct {
+	values []reflect.Value
+}
+
+// newArguments returns arguments from pool with capacity of n values.
+func newArguments(n int) *arguments {
+	args := argumentsPool.Get().(*arguments)
+	if cap(args.values) < n {
+		args.values = make([]reflect.Value, 0, n)
+	}
+	return args
+}
+
+// release clears values and returns arguments into pool. Providers do not retain argument slices, so values can be
+// reused after the call.
+func (a *arguments) release() {
+	for i := range a.values {
+		a.values[i] = reflect.Value{}
+	}
+	a.values = a.values[:0]
+	argumentsPool.Put(a)
 }
@@ -0,0 +1,188 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// Starter is implemented by instances that start their work after the container is wired, see Start().
+type Starter interface {
+	Start(ctx context.Context) error
+}
+
+// phase is a stage of container initialization. Compiled container resolves lazily until BuildAll() is called.
+type phase int32
+
+const (
+	phaseLazy     phase = iota // instances are created and wired on resolution
+	phaseBuilding              // BuildAll() creates singletons
+	phaseBuilt                 // singletons are created, setters are not called
+	phaseWiring                // Wire() calls setters of singletons
+	phaseWired                 // setters of singletons are called
+	phaseStarted               // starters of singletons are called
+	phaseCanceled              // BuildContext() was canceled, created instances are cleaned up
+)
+
+// BuildAll is the first initialization phase. It creates all singletons in construction order: dependencies go first.
+// Setters of singletons are not called until Wire(), so constructors may use their dependencies, but not references
+// that are set by setters. Prototypes are not created, they are created and wired on resolution as usual. Repeated
+// calls do nothing.
+//
+// BuildAll returns the first construction error. Instances created before the error stay cached, Cleanup() runs their
+// cleanups. The next call builds the remaining singletons, for example, ones provided with RetryOnError.
+//
+//   if err := c.BuildAll(); err != nil {
+//     c.Cleanup()
+//   }
+func (c *Container) BuildAll() error {
+	return c.BuildContext(context.Background())
+}
+
+// BuildContext is BuildAll() that stops on ctx cancellation. The context is checked before each construction, so
+// a constructor in progress returns before the build stops. On cancellation BuildContext runs Cleanup() for created
+// instances and returns ctx.Err() wrapped with the number of built definitions:
+//
+//   build canceled after 3 of 8 definitions: context canceled
+//
+// Canceled container is cleaned up and must not be used, next calls of phases return error.
+func (c *Container) BuildContext(ctx context.Context) error {
+	if !c.isCompiled() {
+		return fmt.Errorf("container not compiled")
+	}
+	if !atomic.CompareAndSwapInt32(&c.phase, int32(phaseLazy), int32(phaseBuilding)) {
+		if phase(atomic.LoadInt32(&c.phase)) == phaseCanceled {
+			return errBuildCanceled
+		}
+		return nil
+	}
+	c.begin(step{stage: StageBuild})
+	c.setBuildContext(ctx)
+	defer c.setBuildContext(nil)
+	var order []*definition
+	c.read(func() {
+		order = c.constructionOrder()
+	})
+	var singletons []*definition
+	for _, def := range order {
+		if !def.prototype {
+			singletons = append(singletons, def)
+		}
+	}
+	for built, def := range singletons {
+		if err := ctx.Err(); err != nil {
+			atomic.StoreInt32(&c.phase, int32(phaseCanceled))
+			c.Cleanup()
+			return fmt.Errorf("build canceled after %d of %d definitions: %w", built, len(singletons), err)
+		}
+		if _, err := c.resolveNode(c.index.ids[def.key], 1, -1); err != nil {
+			atomic.StoreInt32(&c.phase, int32(phaseLazy))
+			return err
+		}
+	}
+	atomic.StoreInt32(&c.phase, int32(phaseBuilt))
+	return nil
+}
+
+// errBuildCanceled is returned by phases of the container after BuildContext() cancellation.
+var errBuildCanceled = errors.New("container build canceled, create a new container")
+
+// Wire is the second initialization phase. It calls setters of singletons created by BuildAll() in construction order.
+// All singletons exist, so setter arguments resolve to created instances. Types that are created later are wired on
+// creation. Repeated calls do nothing.
+func (c *Container) Wire() error {
+	switch phase(atomic.LoadInt32(&c.phase)) {
+	case phaseLazy, phaseBuilding:
+		return fmt.Errorf("container not built, call BuildAll() before Wire()")
+	case phaseCanceled:
+		return errBuildCanceled
+	case phaseBuilt:
+	default:
+		return nil
+	}
+	if !atomic.CompareAndSwapInt32(&c.phase, int32(phaseBuilt), int32(phaseWiring)) {
+		return nil
+	}
+	var order []*definition
+	c.read(func() {
+		order = c.constructionOrder()
+	})
+	for _, def := range order {
+		instance, created := cachedValue(def.provider)
+		if !created {
+			continue
+		}
+		if err := c.wire(def, instance, false); err != nil {
+			return err
+		}
+	}
+	atomic.StoreInt32(&c.phase, int32(phaseWired))
+	return nil
+}
+
+// Start is the third initialization phase. It calls Start() of singletons that implement Starter in construction
+// order, so dependencies are started first. All singletons are created and wired. Start stops on the first error.
+// Repeated calls do nothing.
+func (c *Container) Start(ctx context.Context) error {
+	switch phase(atomic.LoadInt32(&c.phase)) {
+	case phaseWired:
+	case phaseStarted:
+		return nil
+	case phaseCanceled:
+		return errBuildCanceled
+	default:
+		return fmt.Errorf("container not wired, call Wire() before Start()")
+	}
+	var order []*definition
+	c.read(func() {
+		order = c.constructionOrder()
+	})
+	for _, def := range order {
+		instance, created := cachedValue(def.provider)
+		if !created {
+			continue
+		}
+		starter, ok := instance.Interface().(Starter)
+		if !ok {
+			continue
+		}
+		var err error
+		if recovered := c.recovered(func() { err = starter.Start(ctx) }); recovered != nil {
+			err = recovered
+		}
+		if err != nil {
+			return c.provideFailed(def.key, fmt.Errorf("start: %w", err))
+		}
+	}
+	atomic.StoreInt32(&c.phase, int32(phaseStarted))
+	return nil
+}
+
+// isWiringDeferred checks that setters of singletons wait for Wire().
+func (c *Container) isWiringDeferred() bool {
+	p := phase(atomic.LoadInt32(&c.phase))
+	return p == phaseBuilding || p == phaseBuilt
+}
+
+// constructionOrder returns definitions sorted so that dependencies go first and late definitions go last. Container
+// specific definitions are omitted. It must be called under the storage lock.
+func (c *Container) constructionOrder() []*definition {
+	if c.order != nil {
+		return c.order
+	}
+	// the graph is checked for cycles on compile
+	sorted, _ := c.graph.Sort()
+	var order, late []*definition
+	for _, k := range sorted {
+		def := c.definition(k.(key))
+		switch {
+		case def == nil || def.isolated:
+		case def.late:
+			late = append(late, def)
+		default:
+			order = append(order, def)
+		}
+	}
+	return append(order, late...)
+}
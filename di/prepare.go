@@ -0,0 +1,33 @@
+package di
+
+import "fmt"
+
+// Prepare computes metadata that resolution otherwise computes on the first use: dependency fields of parameter
+// structs and struct providers with their parsed tags and construction order of initialization phases. Argument keys
+// and dependencies of definitions are computed by Compile(). Prepare does not call providers and does not create
+// instances, it only moves reflection work out of the first resolution. Repeated calls do nothing.
+//
+//   c.Compile()
+//   if err := c.Prepare(); err != nil {
+//     return err
+//   }
+func (c *Container) Prepare() error {
+	if !c.isCompiled() {
+		return fmt.Errorf("container not compiled")
+	}
+	c.storage.Lock()
+	defer c.storage.Unlock()
+	if c.order != nil {
+		return nil
+	}
+	for _, n := range c.index.nodes {
+		switch p := unwrapped(n.provider).(type) {
+		case *providerEmbed:
+			p.dependencies()
+		case *providerStruct:
+			p.fields.dependencies()
+		}
+	}
+	c.order = c.constructionOrder()
+	return nil
+}
@@ -3,7 +3,7 @@ package di
 import "reflect"
 
 // provider lookup sequence
-var providerLookupSequence = []providerType{ptConstructor, ptInterface, ptGroup, ptEmbedParameter}
+var providerLookupSequence = []providerType{ptConstructor, ptAlias, ptInterface, ptGroup, ptRegistry, ptEmbedParameter, ptConversion, ptFactory}
 
 // providerType
 type providerType int
@@ -14,6 +14,11 @@ const (
 	ptInterface
 	ptGroup
 	ptEmbedParameter
+	ptConversion
+	ptFactory
+	ptAlias
+	ptRegistry
+	ptFallback
 )
 
 // provider
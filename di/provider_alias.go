@@ -0,0 +1,47 @@
+package di
+
+import "reflect"
+
+// providerAlias resolves definition by its additional name, see ProvideParams.Names. The alias shares the definition
+// instance.
+type providerAlias struct {
+	res key
+	def *definition
+}
+
+// newProviderAlias creates alias of definition with the name.
+func newProviderAlias(def *definition, name string) *providerAlias {
+	return &providerAlias{
+		res: key{name: name, res: def.key.res, typ: ptAlias, tags: def.key.tags},
+		def: def,
+	}
+}
+
+func (p *providerAlias) Key() key {
+	return p.res
+}
+
+func (p *providerAlias) ParameterList() parameterList {
+	return parameterList{parameter{
+		name: p.def.key.name,
+		res:  p.def.key.res,
+		tags: p.def.tags,
+		impl: true,
+	}}
+}
+
+func (p *providerAlias) Provide(values ...reflect.Value) (reflect.Value, func(), error) {
+	return values[0], nil, nil
+}
+
+// aliasKey returns key of alias with the definition key name.
+func aliasKey(k key) key {
+	k.typ = ptAlias
+	return k
+}
+
+// constructorKey returns key of definition with the alias key name.
+func constructorKey(k key) key {
+	k.typ = ptConstructor
+	return k
+}
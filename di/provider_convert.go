@@ -0,0 +1,55 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// providerConversion converts instance of definition to the requested type, see SetAutoConvert(). If several
+// definitions are convertible, the conversion is ambiguous and resolution fails.
+type providerConversion struct {
+	res        key
+	candidates definitionList
+}
+
+// Source returns definition which instance is converted.
+func (p *providerConversion) Source() (*definition, error) {
+	if len(p.candidates) == 1 {
+		return p.candidates[0], nil
+	}
+	return nil, fmt.Errorf("have several convertible definitions: %s", p.candidates.described())
+}
+
+func (p *providerConversion) Key() key {
+	return p.res
+}
+
+func (p *providerConversion) ParameterList() parameterList {
+	def, err := p.Source()
+	if err != nil {
+		return parameterList{}
+	}
+	return parameterList{parameter{
+		name: def.key.name,
+		res:  def.key.res,
+		tags: def.tags,
+	}}
+}
+
+func (p *providerConversion) Provide(values ...reflect.Value) (reflect.Value, func(), error) {
+	if _, err := p.Source(); err != nil {
+		return reflect.Value{}, nil, err
+	}
+	return values[0].Convert(p.res.res), nil, nil
+}
+
+// isConvertible checks that value of type converts to the target type without changing its representation: types
+// have the same kind, for example, defined type and its underlying type.
+func isConvertible(typ reflect.Type, target reflect.Type) bool {
+	return typ != target && typ.Kind() == target.Kind() && typ.ConvertibleTo(target)
+}
+
+// isBidirectional checks that type is a bidirectional channel of the directional channel element type.
+func isBidirectional(typ reflect.Type, directional reflect.Type) bool {
+	return typ.Kind() == reflect.Chan && typ.ChanDir() == reflect.BothDir && typ.Elem() == directional.Elem()
+}
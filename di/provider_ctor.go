@@ -4,6 +4,8 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sync"
+	"time"
 
 	"github.com/defval/inject/v2/di/internal/reflection"
 )
@@ -18,8 +20,9 @@ const (
 	ctorCleanupError                 // (deps) (result, cleanup, error)
 )
 
-// newProviderConstructor
-func newProviderConstructor(name string, ctor interface{}) *providerConstructor {
+// newProviderConstructor creates constructor provider. Arguments are computed once, so the provider has no mutable
+// state read by compile and resolution.
+func newProviderConstructor(name string, tags Tags, ctor interface{}) *providerConstructor {
 	if ctor == nil {
 		panicf("The constructor must be a function like `func([dep1, dep2, ...]) (<result>, [cleanup, error])`, got `%s`", "nil")
 	}
@@ -27,51 +30,77 @@ func newProviderConstructor(name string, ctor interface{}) *providerConstructor
 		panicf("The constructor must be a function like `func([dep1, dep2, ...]) (<result>, [cleanup, error])`, got `%s`", reflect.ValueOf(ctor).Type())
 	}
 	fn := reflection.InspectFunction(ctor)
-	ctorType := determineCtorType(fn)
-	return &providerConstructor{
+	sig := signatures.Get(fn)
+	provider := &providerConstructor{
 		name:     name,
+		tags:     tags,
 		ctor:     fn,
-		ctorType: ctorType,
+		ctorType: sig.ctorType,
+		result:   sig.result,
+		args:     sig.params,
+		context:  sig.context,
 	}
+	if sig.bag {
+		// parameter bag is named by the constructor key, the signature parameters are shared and must not be changed
+		provider.args = make(parameterList, len(sig.params))
+		copy(provider.args, sig.params)
+		for i := range provider.args {
+			if provider.args[i].res == parameterBagType {
+				provider.args[i].name = provider.Key().String()
+			}
+		}
+	}
+	return provider
 }
 
 // providerConstructor
 type providerConstructor struct {
 	name     string
+	tags     Tags
 	ctor     *reflection.Func
 	ctorType ctorType
+	result   reflect.Type
+	args     parameterList // arguments computed on construction, must not be changed
+	context  bool          // context.Context is passed before arguments
 	clean    *reflection.Func
+	mu       sync.Mutex // guards creation
+	creation creation   // last successful call
 }
 
-func (c providerConstructor) Key() key {
+func (c *providerConstructor) Key() key {
 	return key{
 		name: c.name,
-		res:  c.ctor.Out(0),
+		res:  c.result,
 		typ:  ptConstructor,
+		tags: c.tags.String(),
 	}
 }
 
-func (c providerConstructor) ParameterList() parameterList {
-	var plist parameterList
-	for i := 0; i < c.ctor.NumIn(); i++ {
-		ptype := c.ctor.In(i)
-		var name string
-		if ptype == parameterBagType {
-			name = c.Key().String()
-		}
-		p := parameter{
-			name:     name,
-			res:      ptype,
-			optional: false,
-			embed:    isEmbedParameter(ptype),
-		}
-		plist = append(plist, p)
-	}
-	return plist
+func (c *providerConstructor) ParameterList() parameterList {
+	return c.args
 }
 
 // Provide
 func (c *providerConstructor) Provide(values ...reflect.Value) (reflect.Value, func(), error) {
+	start := time.Now()
+	value, cleanup, err := c.call(values)
+	if err == nil {
+		c.mu.Lock()
+		c.creation = creation{created: true, at: start, duration: time.Since(start)}
+		c.mu.Unlock()
+	}
+	return value, cleanup, err
+}
+
+// lastCreation returns last successful constructor call.
+func (c *providerConstructor) lastCreation() creation {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.creation
+}
+
+// call calls constructor.
+func (c *providerConstructor) call(values []reflect.Value) (reflect.Value, func(), error) {
 	out := callResult(c.ctor.Call(values))
 	switch c.ctorType {
 	case ctorStd:
@@ -87,23 +116,63 @@ func (c *providerConstructor) Provide(values ...reflect.Value) (reflect.Value, f
 		"this: https://github.com/defval/inject/issues/new")
 }
 
-// determineCtorType
+// creation describes instance creation.
+type creation struct {
+	created  bool
+	at       time.Time
+	duration time.Duration
+}
+
+// lastCreation returns last instance creation of provider.
+func lastCreation(provider internalProvider) creation {
+	switch p := provider.(type) {
+	case *providerConstructor:
+		return p.lastCreation()
+	case *providerStruct:
+		return p.lastCreation()
+	case *providerValue:
+		return creation{created: true}
+	case *singletonWrapper:
+		return lastCreation(p.internalProvider)
+	case *providerRetry:
+		return lastCreation(p.internalProvider)
+	case *providerInherited:
+		return lastCreation(p.internalProvider)
+	}
+	return creation{}
+}
+
+// determineCtorType returns constructor type. It panics with the constructor signature and the reason if the
+// signature is incorrect.
 func determineCtorType(fn *reflection.Func) ctorType {
-	if fn.NumOut() == 1 {
+	var reason string
+	switch fn.NumOut() {
+	case 0:
+		reason = "constructor has no result"
+	case 1:
 		return ctorStd
-	}
-	if fn.NumOut() == 2 {
+	case 2:
 		if reflection.IsError(fn.Out(1)) {
 			return ctorError
 		}
 		if reflection.IsCleanup(fn.Out(1)) {
 			return ctorCleanup
 		}
+		reason = "second result must be error or cleanup func()"
+	case 3:
+		if reflection.IsCleanup(fn.Out(1)) && reflection.IsError(fn.Out(2)) {
+			return ctorCleanupError
+		}
+		if !reflection.IsCleanup(fn.Out(1)) {
+			reason = "second result must be cleanup func()"
+		} else {
+			reason = "third result must be error"
+		}
+	default:
+		reason = fmt.Sprintf("constructor has %d results, at most 3 allowed", fn.NumOut())
 	}
-	if fn.NumOut() == 3 && reflection.IsCleanup(fn.Out(1)) && reflection.IsError(fn.Out(2)) {
-		return ctorCleanupError
-	}
-	panic(fmt.Sprintf("The constructor must be a function like `func([dep1, dep2, ...]) (<result>, [cleanup, error])`, got `%s`", fn.Name))
+	panic(fmt.Sprintf("%s: invalid constructor `%s`: %s, the constructor must be a function like `func([dep1, dep2, ...]) (<result>, [cleanup, error])`",
+		fn.Location, renderSignature(fn.Type), reason))
 }
 
 // callResult
@@ -1,12 +1,16 @@
 package di
 
 import (
+	"fmt"
 	"reflect"
 	"strings"
+	"sync"
+	"unsafe"
 )
 
-// createStructProvider
-func newProviderEmbed(p parameter) *providerEmbed {
+// newProviderEmbed creates provider of parameter struct. If unexported is set, tagged unexported fields are set
+// with unsafe access, otherwise they cause the error.
+func newProviderEmbed(p parameter, unexported bool) *providerEmbed {
 	var embedType reflect.Type
 	if p.res.Kind() == reflect.Ptr {
 		embedType = p.res.Elem()
@@ -14,7 +18,7 @@ func newProviderEmbed(p parameter) *providerEmbed {
 		embedType = p.res
 	}
 
-	return &providerEmbed{
+	embed := &providerEmbed{
 		key: key{
 			name: p.name,
 			res:  p.res,
@@ -22,13 +26,49 @@ func newProviderEmbed(p parameter) *providerEmbed {
 		},
 		embedType:  embedType,
 		embedValue: reflect.New(embedType).Elem(),
+		fields:     embedFields(embedType, nil),
+		unexported: unexported,
 	}
+	for _, field := range embed.fields {
+		if !unexported && field.PkgPath != "" && isTaggedField(field) {
+			embed.err = fmt.Errorf("field %s of %s is unexported, enable AllowUnexported() or export the field", field.Name, p.res)
+			break
+		}
+	}
+	return embed
+}
+
+// embedFields returns fields of parameter struct. Fields of embedded exported structs without tags are promoted,
+// their index is a path from the parameter struct.
+func embedFields(typ reflect.Type, index []int) []reflect.StructField {
+	var fields []reflect.StructField
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		field.Index = append(index[:len(index):len(index)], i)
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && field.PkgPath == "" && field.Tag == "" {
+			fields = append(fields, embedFields(field.Type, field.Index)...)
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
 }
 
 type providerEmbed struct {
 	key        key
 	embedType  reflect.Type
 	embedValue reflect.Value
+	fields     []reflect.StructField // fields with promoted fields of embedded structs
+	unexported bool                  // set unexported fields
+	err        error                 // unexported field error
+	mu         sync.Mutex            // guards plan
+	plan       []embedDependency     // dependency fields, see dependencies()
+}
+
+// embedDependency is a dependency field of parameter struct with its parsed tag.
+type embedDependency struct {
+	index []int
+	param parameter
 }
 
 func (p *providerEmbed) Key() key {
@@ -37,60 +77,139 @@ func (p *providerEmbed) Key() key {
 
 func (p *providerEmbed) ParameterList() parameterList {
 	var plist parameterList
-	for i := 0; i < p.embedType.NumField(); i++ {
-		name, optional, isDependency := p.inspectFieldTag(i)
-		if !isDependency {
-			continue
-		}
-		field := p.embedType.Field(i)
-		plist = append(plist, parameter{
-			name:     name,
-			res:      field.Type,
-			optional: optional,
-			embed:    isEmbedParameter(field.Type),
-		})
+	for _, dep := range p.dependencies() {
+		plist = append(plist, dep.param)
 	}
 	return plist
 }
 
-func (p *providerEmbed) Provide(values ...reflect.Value) (reflect.Value, func(), error) {
-	for i, offset := 0, 0; i < p.embedType.NumField(); i++ {
-		_, _, isDependency := p.inspectFieldTag(i)
+// dependencies returns dependency fields. Tags are parsed on the first call, next calls and fill() use the result.
+func (p *providerEmbed) dependencies() []embedDependency {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.plan != nil {
+		return p.plan
+	}
+	plan := make([]embedDependency, 0, len(p.fields))
+	for _, field := range p.fields {
+		name, group, optional, isDependency := p.inspectFieldTag(field)
 		if !isDependency {
-			offset++
 			continue
 		}
-
-		p.embedValue.Field(i).Set(values[i-offset])
+		plan = append(plan, embedDependency{
+			index: field.Index,
+			param: parameter{
+				name:     name,
+				res:      field.Type,
+				optional: optional,
+				embed:    isEmbedParameter(field.Type),
+				group:    group,
+			},
+		})
 	}
+	p.plan = plan
+	return plan
+}
 
+func (p *providerEmbed) Provide(values ...reflect.Value) (reflect.Value, func(), error) {
+	if p.err != nil {
+		return reflect.Value{}, nil, p.err
+	}
+	p.fill(p.embedValue, values)
 	return p.embedValue, nil, nil
 }
 
-func (p *providerEmbed) inspectFieldTag(num int) (name string, optional bool, isDependency bool) {
-	fieldType := p.embedType.Field(num)
-	fieldValue := p.embedValue.Field(num)
-	tag, tagExists := fieldType.Tag.Lookup("di")
-	if !tagExists || !fieldValue.CanSet() {
-		return "", false, false
+// fill sets tagged fields of addressable struct value to values of parameter list.
+func (p *providerEmbed) fill(target reflect.Value, values []reflect.Value) {
+	for i, dep := range p.dependencies() {
+		value := target.FieldByIndex(dep.index)
+		if !value.CanSet() {
+			// unexported field is allowed
+			value = reflect.NewAt(value.Type(), unsafe.Pointer(value.UnsafeAddr())).Elem()
+		}
+		value.Set(values[i])
 	}
-	name, optional = p.parseTag(tag)
-	return name, optional, true
 }
 
-func (p *providerEmbed) parseTag(tag string) (name string, optional bool) {
-	options := strings.Split(tag, ",")
-	if len(options) == 0 {
-		return "", false
+func (p *providerEmbed) inspectFieldTag(fieldType reflect.StructField) (name string, group string, optional bool, isDependency bool) {
+	if isSkippedField(fieldType) {
+		return "", "", false, false
+	}
+	if fieldType.PkgPath != "" && !p.unexported {
+		return "", "", false, false
+	}
+	if group, isGroup := fieldType.Tag.Lookup("group"); isGroup {
+		if !isGroupType(fieldType.Type) {
+			panicf("%s: group field %s must be a slice or a map with string keys, got `%s`", p.key, fieldType.Name, fieldType.Type)
+		}
+		return "", group, false, true
 	}
-	if len(options) == 1 && options[0] == "optional" {
-		return "", true
+	tag, tagExists := fieldType.Tag.Lookup("di")
+	if !tagExists {
+		return "", "", false, false
 	}
-	if len(options) == 1 {
-		return options[0], false
+	name, optional, err := parseTag(tag)
+	if err != nil {
+		panicf("%s: field %s has incorrect di tag `%s`: %s", p.key, fieldType.Name, tag, err)
 	}
-	if len(options) == 2 && options[1] == "optional" {
-		return options[0], true
+	return name, "", optional, true
+}
+
+// parseTag parses di tag of parameter struct field. The tag is a comma separated list of options: the name as the
+// first option or as name=<name> option and the optional marker.
+//
+//   `di:"primary,optional"`
+//   `di:"name=primary,optional"`
+func parseTag(tag string) (name string, optional bool, err error) {
+	var named bool
+	for i, option := range strings.Split(tag, ",") {
+		switch {
+		case option == "optional":
+			if optional {
+				return "", false, fmt.Errorf("duplicate option `optional`")
+			}
+			optional = true
+		case strings.HasPrefix(option, "name="):
+			if named {
+				return "", false, fmt.Errorf("duplicate name `%s`", option)
+			}
+			if name = strings.TrimPrefix(option, "name="); name == "" {
+				return "", false, fmt.Errorf("empty name")
+			}
+			named = true
+		case i == 0 && !strings.Contains(option, "="):
+			name, named = option, option != ""
+		case option == "":
+			return "", false, fmt.Errorf("empty option")
+		default:
+			return "", false, fmt.Errorf("unknown option `%s`", option)
+		}
+	}
+	return name, optional, nil
+}
+
+// skippedFields returns fields that are skipped by `di:"-"` tag.
+func (p *providerEmbed) skippedFields() []reflect.StructField {
+	var fields []reflect.StructField
+	for _, field := range p.fields {
+		if isSkippedField(field) {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// isSkippedField checks that struct field is never set by container.
+func isSkippedField(field reflect.StructField) bool {
+	return field.Tag.Get("di") == "-"
+}
+
+// isTaggedField checks that struct field is a dependency.
+func isTaggedField(field reflect.StructField) bool {
+	if isSkippedField(field) {
+		return false
 	}
-	panic("incorrect di tag")
+	_, isGroup := field.Tag.Lookup("group")
+	_, isDependency := field.Tag.Lookup("di")
+	return isGroup || isDependency
 }
@@ -0,0 +1,45 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTag(t *testing.T) {
+	for _, tt := range []struct {
+		tag      string
+		name     string
+		optional bool
+		err      string
+	}{
+		{tag: ""},
+		{tag: "primary", name: "primary"},
+		{tag: "optional", optional: true},
+		{tag: "primary,optional", name: "primary", optional: true},
+		{tag: ",optional", optional: true},
+		{tag: "name=primary", name: "primary"},
+		{tag: "name=primary,optional", name: "primary", optional: true},
+		{tag: "optional,name=primary", name: "primary", optional: true},
+		{tag: "name=optional", name: "optional"},
+		{tag: "name=", err: "empty name"},
+		{tag: "primary,name=replica", err: "duplicate name `name=replica`"},
+		{tag: "name=primary,name=replica", err: "duplicate name `name=replica`"},
+		{tag: "optional,optional", err: "duplicate option `optional`"},
+		{tag: "primary,", err: "empty option"},
+		{tag: "primary,replica", err: "unknown option `replica`"},
+		{tag: "primary, optional", err: "unknown option ` optional`"},
+		{tag: "nam=primary", err: "unknown option `nam=primary`"},
+	} {
+		t.Run(tt.tag, func(t *testing.T) {
+			name, optional, err := parseTag(tt.tag)
+			if tt.err != "" {
+				require.EqualError(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.name, name)
+			require.Equal(t, tt.optional, optional)
+		})
+	}
+}
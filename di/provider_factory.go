@@ -0,0 +1,60 @@
+package di
+
+import (
+	"context"
+	"reflect"
+)
+
+// contextType and errorType are types of factory parameter and error result.
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// providerFactory provides function that resolves the target type on each call. The target is resolved as usual:
+// prototype is created on each call, singleton is created once. A factory does not depend on the target in the
+// graph, so resolution of the factory does not create the target.
+type providerFactory struct {
+	res       key
+	target    parameter
+	container *Container
+}
+
+// newProviderFactory creates factory provider of parameter if parameter is a function like
+// `func([context.Context]) (<target>, error)`.
+func newProviderFactory(c *Container, p parameter) (*providerFactory, bool) {
+	typ := p.res
+	if typ.Kind() != reflect.Func || typ.NumIn() > 1 || typ.NumIn() == 1 && typ.In(0) != contextType ||
+		typ.NumOut() != 2 || typ.Out(1) != errorType {
+		return nil, false
+	}
+	return &providerFactory{
+		res:       key{name: p.name, res: typ, typ: ptFactory, tags: p.tags.String()},
+		target:    parameter{name: p.name, res: typ.Out(0), tags: p.tags},
+		container: c,
+	}, true
+}
+
+func (p *providerFactory) Key() key {
+	return p.res
+}
+
+func (p *providerFactory) ParameterList() parameterList {
+	return parameterList{}
+}
+
+func (p *providerFactory) Provide(values ...reflect.Value) (reflect.Value, func(), error) {
+	fn := reflect.MakeFunc(p.res.res, func(args []reflect.Value) []reflect.Value {
+		if len(args) == 1 && !args[0].IsNil() {
+			if err := args[0].Interface().(context.Context).Err(); err != nil {
+				return []reflect.Value{reflect.Zero(p.target.res), reflect.ValueOf(&err).Elem()}
+			}
+		}
+		value, err := p.target.ResolveValue(p.container)
+		if err != nil {
+			return []reflect.Value{reflect.Zero(p.target.res), reflect.ValueOf(&err).Elem()}
+		}
+		return []reflect.Value{value, reflect.Zero(errorType)}
+	})
+	return fn, nil, nil
+}
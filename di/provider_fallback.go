@@ -0,0 +1,64 @@
+package di
+
+import (
+	"reflect"
+)
+
+// SetFallbacks sets containers that resolve types missing in the container. Fallbacks are consulted in order after
+// all lookups of the container fail. Instances come from the container that owns the definition, so singletons of
+// fallbacks are shared. Dependencies of fallback definitions are resolved in the fallback container and are not part
+// of the container graph. Fallbacks must be compiled, so chains of fallbacks could not be cyclic.
+//
+//   plugin.SetFallbacks(host, platform)
+//   plugin.Compile()
+func (c *Container) SetFallbacks(fallbacks ...*Container) {
+	for _, fallback := range fallbacks {
+		if !fallback.isCompiled() {
+			panicf("fallback container not compiled")
+		}
+	}
+	c.storage.Lock()
+	defer c.storage.Unlock()
+	c.mustNotCompiled()
+	c.fallbacks = append(c.fallbacks, fallbacks...)
+}
+
+// fallbackProvider returns provider of the first fallback container that resolves parameter.
+func (c *Container) fallbackProvider(p parameter) (internalProvider, bool) {
+	for _, fallback := range c.fallbacks {
+		var exists bool
+		fallback.read(func() {
+			_, exists = p.ResolveProvider(fallback)
+		})
+		if !exists {
+			continue
+		}
+		return &providerFallback{
+			res:    key{name: p.name, res: p.res, typ: ptFallback, tags: p.tags.String()},
+			target: parameter{name: p.name, res: p.res, tags: p.tags, impl: p.impl},
+			owner:  fallback,
+		}, true
+	}
+	return nil, false
+}
+
+// providerFallback resolves type in fallback container. It has no parameters in the container graph: dependencies
+// are resolved by the owner, so edges of other containers do not take part in cycle detection.
+type providerFallback struct {
+	res    key
+	target parameter
+	owner  *Container
+}
+
+func (p *providerFallback) Key() key {
+	return p.res
+}
+
+func (p *providerFallback) ParameterList() parameterList {
+	return parameterList{}
+}
+
+func (p *providerFallback) Provide(values ...reflect.Value) (reflect.Value, func(), error) {
+	value, err := p.target.ResolveValue(p.owner)
+	return value, nil, err
+}
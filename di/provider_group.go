@@ -1,36 +1,46 @@
 package di
 
 import (
+	"fmt"
 	"reflect"
 )
 
 // newProviderGroup creates new group from provided resultKey.
 func newProviderGroup(k key) *providerGroup {
 	ifaceKey := key{
-		res: reflect.SliceOf(k.res),
-		typ: ptGroup,
+		name: k.name,
+		res:  reflect.SliceOf(k.res),
+		typ:  ptGroup,
 	}
 
 	return &providerGroup{
 		result: ifaceKey,
-		pl:     parameterList{},
 	}
 }
 
 // providerGroup
 type providerGroup struct {
-	result key
-	pl     parameterList
+	result  key
+	members definitionList
 }
 
-// Add
-func (i *providerGroup) Add(k key) {
-	i.pl = append(i.pl, parameter{
-		name:     k.name,
-		res:      k.res,
-		optional: false,
-		embed:    false,
-	})
+// Add adds definition into group. Group members sorted by order marker and registration sequence.
+func (i *providerGroup) Add(def *definition) {
+	if i.members.Get(def.key) != nil {
+		return
+	}
+	i.members = append(i.members, def)
+	i.members.Sort()
+}
+
+// remove removes definition from group.
+func (i *providerGroup) remove(def *definition) {
+	for j, member := range i.members {
+		if member == def {
+			i.members = append(i.members[:j:j], i.members[j+1:]...)
+			return
+		}
+	}
 }
 
 // resultKey
@@ -40,11 +50,80 @@ func (i providerGroup) Key() key {
 
 // parameters
 func (i providerGroup) ParameterList() parameterList {
-	return i.pl
+	plist := parameterList{}
+	for _, def := range i.members {
+		plist = append(plist, parameter{
+			name:     def.key.name,
+			res:      def.key.res,
+			tags:     def.tags,
+			optional: false,
+			embed:    false,
+			impl:     true,
+		})
+	}
+	return plist
 }
 
 // Provide
 func (i providerGroup) Provide(values ...reflect.Value) (reflect.Value, func(), error) {
-	group := reflect.New(i.result.res).Elem()
+	group := reflect.MakeSlice(i.result.res, 0, len(values))
 	return reflect.Append(group, values...), nil, nil
 }
+
+// newProviderMapGroup creates map of named group members. Members without name are not included. The error of several
+// members with the same name is returned on provide.
+func newProviderMapGroup(k key, group *providerGroup) *providerMapGroup {
+	provider := &providerMapGroup{result: k}
+	if group == nil {
+		return provider
+	}
+	seen := map[string]*definition{}
+	for _, def := range group.members {
+		if def.key.name == "" {
+			continue
+		}
+		if first, exists := seen[def.key.name]; exists && provider.err == nil {
+			provider.err = fmt.Errorf("name `%s` provided by %s at %s and %s at %s", def.key.name, first.key.res,
+				first.location, def.key.res, def.location)
+		}
+		seen[def.key.name] = def
+		provider.members = append(provider.members, def)
+	}
+	return provider
+}
+
+// providerMapGroup provides named group members as map where key is a member name.
+type providerMapGroup struct {
+	result  key
+	members definitionList
+	err     error // duplicate member names
+}
+
+func (i *providerMapGroup) Key() key {
+	return i.result
+}
+
+func (i *providerMapGroup) ParameterList() parameterList {
+	plist := parameterList{}
+	for _, def := range i.members {
+		plist = append(plist, parameter{
+			name: def.key.name,
+			res:  def.key.res,
+			tags: def.tags,
+			impl: true,
+		})
+	}
+	return plist
+}
+
+func (i *providerMapGroup) Provide(values ...reflect.Value) (reflect.Value, func(), error) {
+	if i.err != nil {
+		return reflect.Value{}, nil, i.err
+	}
+	group := reflect.MakeMapWithSize(i.result.res, len(values))
+	for j, value := range values {
+		name := reflect.ValueOf(i.members[j].key.name).Convert(i.result.res.Key())
+		group.SetMapIndex(name, value)
+	}
+	return group, nil, nil
+}
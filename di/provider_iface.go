@@ -1,31 +1,66 @@
 package di
 
 import (
+	"fmt"
 	"reflect"
 
 	"github.com/defval/inject/v2/di/internal/reflection"
 )
 
 // newProviderInterface
-func newProviderInterface(provider internalProvider, as interface{}) *providerInterface {
-	iface := reflection.InspectInterfacePtr(as)
-	if !provider.Key().res.Implements(iface.Type) {
-		panicf("%s not implement %s", provider.Key(), iface.Type)
+func newProviderInterface(def *definition, as interface{}) *providerInterface {
+	iface, err := reflection.InspectInterfacePtr(as)
+	if err != nil {
+		panicf("%s: %s: As() argument must be a pointer to interface, %s", def.location, def.key, err)
+	}
+	if !def.key.res.Implements(iface.Type) {
+		panicf("%s not implement %s", def, iface.Type)
 	}
 	return &providerInterface{
 		res: key{
-			name: provider.Key().name,
+			name: def.key.name,
 			res:  iface.Type,
 			typ:  ptInterface,
 		},
-		provider: provider,
 	}
 }
 
 // providerInterface
 type providerInterface struct {
-	res      key
-	provider internalProvider
+	res         key
+	impls       definitionList
+	replacement *definition // the only implementation of replaced interface
+}
+
+// Add adds implementation of interface. Replaced interface keeps the replacement as the only implementation.
+func (i *providerInterface) Add(def *definition) {
+	if i.impls.Get(def.key) != nil || i.replacement != nil {
+		return
+	}
+	i.impls = append(i.impls, def)
+	i.impls.Sort()
+}
+
+// Implementation returns definition that will be used as interface implementation. If interface has several
+// implementations, the only primary one is used.
+func (i *providerInterface) Implementation() (*definition, error) {
+	if len(i.impls) == 1 {
+		return i.impls[0], nil
+	}
+	var primary definitionList
+	for _, def := range i.impls {
+		if def.primary {
+			primary = append(primary, def)
+		}
+	}
+	switch len(primary) {
+	case 0:
+		return nil, fmt.Errorf("have several implementations: %s", i.impls.described())
+	case 1:
+		return primary[0], nil
+	default:
+		return nil, fmt.Errorf("have several primary implementations: %s", primary.described())
+	}
 }
 
 func (i *providerInterface) Key() key {
@@ -33,16 +68,25 @@ func (i *providerInterface) Key() key {
 }
 
 func (i *providerInterface) ParameterList() parameterList {
+	def, err := i.Implementation()
+	if err != nil {
+		return parameterList{}
+	}
 	var plist parameterList
 	plist = append(plist, parameter{
-		name:     i.provider.Key().name,
-		res:      i.provider.Key().res,
+		name:     def.key.name,
+		res:      def.key.res,
+		tags:     def.tags,
 		optional: false,
 		embed:    false,
+		impl:     true,
 	})
 	return plist
 }
 
 func (i *providerInterface) Provide(values ...reflect.Value) (reflect.Value, func(), error) {
+	if _, err := i.Implementation(); err != nil {
+		return reflect.Value{}, nil, err
+	}
 	return values[0], nil, nil
 }
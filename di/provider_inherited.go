@@ -0,0 +1,7 @@
+package di
+
+// providerInherited is a singleton provider of parent container shared with derived container.
+type providerInherited struct {
+	internalProvider
+	owner *Container
+}
@@ -0,0 +1,108 @@
+package di
+
+import (
+	"context"
+	"reflect"
+)
+
+// stringType is a key type of registries.
+var stringType = reflect.TypeOf("")
+
+// registryKey returns key of the registry of type.
+func registryKey(elem reflect.Type) key {
+	return key{res: reflect.MapOf(stringType, elem), typ: ptRegistry}
+}
+
+// processProviderRegistry adds definition to the registry of type. Registry is a map of definition names, two
+// definitions with the same name in one registry cause panic with both locations.
+func (c *Container) processProviderRegistry(def *definition, elem reflect.Type) {
+	k := registryKey(elem)
+	var registry *providerMapGroup
+	if c.graph.Exists(k) {
+		registry = c.graph.Get(k).Value.(*providerMapGroup)
+	} else {
+		registry = &providerMapGroup{result: k}
+		c.graph.Add(k, registry)
+	}
+	for _, member := range registry.members {
+		if member == def {
+			return
+		}
+		if member.key.name == def.key.name {
+			panicf("%s: %s key `%s` already registered by %s at %s", def.location, k.res, def.key.name, member.key.res,
+				member.location)
+		}
+	}
+	registry.members = append(registry.members, def)
+}
+
+// registryFactoryProvider creates provider of registry factories if parameter is a map like
+// `map[string]func([context.Context]) (<type>, error)` and the registry of type exists.
+func (c *Container) registryFactoryProvider(p parameter) (internalProvider, bool) {
+	typ := p.res
+	if p.name != "" || typ.Kind() != reflect.Map || typ.Key() != stringType {
+		return nil, false
+	}
+	fn := typ.Elem()
+	if fn.Kind() != reflect.Func || fn.NumIn() > 1 || fn.NumIn() == 1 && fn.In(0) != contextType ||
+		fn.NumOut() != 2 || fn.Out(1) != errorType {
+		return nil, false
+	}
+	k := registryKey(fn.Out(0))
+	if !c.graph.Exists(k) {
+		return nil, false
+	}
+	factory := &providerRegistryFactory{
+		res:       key{res: typ, typ: ptFactory},
+		container: c,
+	}
+	for _, def := range c.graph.Get(k).Value.(*providerMapGroup).members {
+		factory.members = append(factory.members, parameter{
+			name: def.key.name,
+			res:  def.key.res,
+			tags: def.tags,
+			impl: true,
+		})
+	}
+	return factory, true
+}
+
+// providerRegistryFactory provides registry as map of factories. Factories resolve members on call, so resolution of
+// the registry does not create its members.
+type providerRegistryFactory struct {
+	res       key
+	members   []parameter
+	container *Container
+}
+
+func (p *providerRegistryFactory) Key() key {
+	return p.res
+}
+
+func (p *providerRegistryFactory) ParameterList() parameterList {
+	return parameterList{}
+}
+
+func (p *providerRegistryFactory) Provide(values ...reflect.Value) (reflect.Value, func(), error) {
+	fnType := p.res.res.Elem()
+	registry := reflect.MakeMapWithSize(p.res.res, len(p.members))
+	for _, member := range p.members {
+		member := member
+		fn := reflect.MakeFunc(fnType, func(args []reflect.Value) []reflect.Value {
+			if len(args) == 1 && !args[0].IsNil() {
+				if err := args[0].Interface().(context.Context).Err(); err != nil {
+					return []reflect.Value{reflect.Zero(fnType.Out(0)), reflect.ValueOf(&err).Elem()}
+				}
+			}
+			value, err := member.ResolveValue(p.container)
+			if err != nil {
+				return []reflect.Value{reflect.Zero(fnType.Out(0)), reflect.ValueOf(&err).Elem()}
+			}
+			result := reflect.New(fnType.Out(0)).Elem()
+			result.Set(value)
+			return []reflect.Value{result, reflect.Zero(errorType)}
+		})
+		registry.SetMapIndex(reflect.ValueOf(member.name), fn)
+	}
+	return registry, nil, nil
+}
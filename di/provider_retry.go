@@ -0,0 +1,74 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// withRetry creates a provider that calls source provider again on error.
+func (c *Container) withRetry(provider internalProvider, attempts int, backoff time.Duration, exponential bool) *providerRetry {
+	if attempts < 1 {
+		panicf("%s: retry attempts must be positive, got %d", provider.Key(), attempts)
+	}
+	return &providerRetry{
+		internalProvider: provider,
+		container:        c,
+		attempts:         attempts,
+		backoff:          backoff,
+		exponential:      exponential,
+	}
+}
+
+// providerRetry calls source provider until it succeeds or attempts are exhausted.
+type providerRetry struct {
+	internalProvider               // source provider
+	container        *Container    // source of build context and retry log
+	attempts         int           // number of calls
+	backoff          time.Duration // delay between calls
+	exponential      bool          // double delay after each call
+}
+
+// Provide returns the first successful result. If all attempts failed, the error wraps the last attempt error. Each
+// failed attempt is written to the retry log. Cancellation of the build context aborts waiting between attempts.
+func (r *providerRetry) Provide(values ...reflect.Value) (reflect.Value, func(), error) {
+	ctx := r.container.buildContext()
+	backoff := r.backoff
+	var err error
+	for attempt := 1; attempt <= r.attempts; attempt++ {
+		var value reflect.Value
+		var cleanup func()
+		value, cleanup, err = r.internalProvider.Provide(values...)
+		if err == nil {
+			return value, cleanup, nil
+		}
+		r.container.retried(r.Key(), attempt, r.attempts, err)
+		if attempt == r.attempts {
+			break
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return reflect.Value{}, nil, fmt.Errorf("retry canceled after %d of %d attempts: %w", attempt, r.attempts, ctx.Err())
+		case <-timer.C:
+		}
+		if r.exponential {
+			backoff *= 2
+		}
+	}
+	if r.attempts == 1 {
+		return reflect.Value{}, nil, err
+	}
+	return reflect.Value{}, nil, fmt.Errorf("failed after %d attempts: %w", r.attempts, err)
+}
+
+// retried writes failed attempt of retry provider to the retry log.
+func (c *Container) retried(k key, attempt, attempts int, err error) {
+	if c.retryLog == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	fmt.Fprintf(c.retryLog, "%s: attempt %d of %d failed: %s\n", k, attempt, attempts, err)
+}
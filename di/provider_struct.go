@@ -0,0 +1,95 @@
+package di
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// newProviderStruct creates provider of struct or pointer to struct. If instance is set, the provider sets tagged
+// fields of the value and returns it, otherwise it allocates a copy of the value on each call.
+func newProviderStruct(name string, value interface{}, instance bool, unexported bool) *providerStruct {
+	typ := reflect.TypeOf(value)
+	if typ == nil || !isStructType(typ) || (instance && typ.Kind() != reflect.Ptr) {
+		expected := "a struct or a pointer to struct"
+		if instance {
+			expected = "a pointer to struct"
+		}
+		panicf("The value must be %s, got `%s`", expected, typ)
+	}
+	if instance && reflect.ValueOf(value).IsNil() {
+		panicf("The value must not be nil, got `%s`", typ)
+	}
+	structType := typ
+	if typ.Kind() == reflect.Ptr {
+		structType = typ.Elem()
+	}
+	return &providerStruct{
+		name:     name,
+		result:   typ,
+		value:    reflect.ValueOf(value),
+		instance: instance,
+		fields:   newProviderEmbed(parameter{res: structType}, unexported),
+	}
+}
+
+// providerStruct provides struct with tagged fields set to dependencies. Fields are tagged like fields of parameter
+// structs.
+type providerStruct struct {
+	name     string
+	tags     Tags
+	result   reflect.Type   // struct or pointer to struct
+	value    reflect.Value  // instance or template
+	instance bool           // value is shared instance
+	fields   *providerEmbed // tagged fields
+	mu       sync.Mutex     // guards creation
+	creation creation       // last successful call
+}
+
+func (p *providerStruct) Key() key {
+	return key{
+		name: p.name,
+		res:  p.result,
+		typ:  ptConstructor,
+		tags: p.tags.String(),
+	}
+}
+
+func (p *providerStruct) ParameterList() parameterList {
+	return p.fields.ParameterList()
+}
+
+// Provide sets fields of the instance or of the template copy.
+func (p *providerStruct) Provide(values ...reflect.Value) (reflect.Value, func(), error) {
+	start := time.Now()
+	target := p.value
+	if !p.instance {
+		target = reflect.New(p.fields.embedType)
+		if template := reflect.Indirect(p.value); template.IsValid() {
+			target.Elem().Set(template)
+		}
+	}
+	p.fields.fill(target.Elem(), values)
+	p.mu.Lock()
+	p.creation = creation{created: true, at: start, duration: time.Since(start)}
+	p.mu.Unlock()
+	if p.result.Kind() != reflect.Ptr {
+		return target.Elem(), nil, nil
+	}
+	return target, nil, nil
+}
+
+// lastCreation returns last successful call.
+func (p *providerStruct) lastCreation() creation {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.creation
+}
+
+// isStructType checks that type is a struct or a pointer to struct.
+func isStructType(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ.Kind() == reflect.Struct
+}
@@ -0,0 +1,61 @@
+package di
+
+import (
+	"reflect"
+
+	"github.com/defval/inject/v2/di/internal/reflection"
+)
+
+// newProviderValue
+func newProviderValue(name string, value interface{}) *providerValue {
+	if value == nil {
+		panicf("The value must not be nil, use typed value instead")
+	}
+	return &providerValue{
+		name:  name,
+		value: reflect.ValueOf(value),
+	}
+}
+
+// providerValue provides already created value.
+type providerValue struct {
+	name       string
+	tags       Tags
+	value      reflect.Value
+	cleanup    func()                              // runs on container cleanup if the value was resolved
+	appendable bool                                // value accumulates appended slices or maps
+	sources    map[interface{}]reflection.Location // locations of appended map keys
+}
+
+// append appends slice or map to the value. Keys of appended map must not exist in the value.
+func (v *providerValue) append(value reflect.Value, location reflection.Location) {
+	if value.Kind() == reflect.Slice {
+		v.value = reflect.AppendSlice(v.value, value)
+		return
+	}
+	iter := value.MapRange()
+	for iter.Next() {
+		if v.value.MapIndex(iter.Key()).IsValid() {
+			panicf("%s: %s key `%v` already provided at %s", location, value.Type(), iter.Key(), v.sources[iter.Key().Interface()])
+		}
+		v.value.SetMapIndex(iter.Key(), iter.Value())
+		v.sources[iter.Key().Interface()] = location
+	}
+}
+
+func (v *providerValue) Key() key {
+	return key{
+		name: v.name,
+		res:  v.value.Type(),
+		typ:  ptConstructor,
+		tags: v.tags.String(),
+	}
+}
+
+func (v *providerValue) ParameterList() parameterList {
+	return nil
+}
+
+func (v *providerValue) Provide(values ...reflect.Value) (reflect.Value, func(), error) {
+	return v.value, v.cleanup, nil
+}
@@ -0,0 +1,70 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+)
+
+// ErrReleased is an error of resolution that creates instance after ReleaseInstances() with IsRejectResolution.
+var ErrReleased = errors.New("container instances released")
+
+// ReleaseParams is a `ReleaseInstances()` method options. IsRejectResolution makes resolutions that create instances
+// fail with ErrReleased, otherwise instances are created again on resolution.
+type ReleaseParams struct {
+	IsRejectResolution bool
+}
+
+// ReleaseInstances drops references to created singletons, cached errors and cleanups after Cleanup(), so the heap
+// could be reclaimed while the container is kept for diagnostics. Definitions stay registered: Definitions(),
+// Report(), DebugDump() and other introspection work as before. Provided values are not released.
+//
+// Next resolution creates instances again and the container returns to the lazy phase, call Cleanup() and
+// ReleaseInstances() again to release them. Set IsRejectResolution to fail such resolutions with ErrReleased.
+//
+//   c.Cleanup()
+//   err := c.ReleaseInstances(di.ReleaseParams{IsRejectResolution: true})
+func (c *Container) ReleaseInstances(params ReleaseParams) error {
+	if !c.isCompiled() {
+		return fmt.Errorf("container not compiled")
+	}
+	c.mu.Lock()
+	if c.cleaned != len(c.cleanups) {
+		c.mu.Unlock()
+		return fmt.Errorf("instances could not be released before Cleanup()")
+	}
+	c.cleanups, c.cleaned = nil, 0
+	c.mu.Unlock()
+	c.storage.Lock()
+	defer c.storage.Unlock()
+	for _, def := range c.definitions {
+		release(def.provider)
+		atomic.StoreUint32(&def.wired, 0)
+	}
+	var rejected int32
+	if params.IsRejectResolution {
+		rejected = 1
+	}
+	atomic.StoreInt32(&c.released, rejected)
+	atomic.StoreInt32(&c.phase, int32(phaseLazy))
+	return nil
+}
+
+// release drops cached instance of provider and its reflection scratch. Singletons inherited from parent container
+// belong to the parent.
+func release(provider internalProvider) {
+	switch p := provider.(type) {
+	case *singletonWrapper:
+		p.mu.Lock()
+		p.value, p.err = reflect.Value{}, nil
+		p.mu.Unlock()
+		release(p.internalProvider)
+	case *providerRetry:
+		release(p.internalProvider)
+	case *providerEmbed:
+		p.mu.Lock()
+		p.plan = nil
+		p.mu.Unlock()
+	}
+}
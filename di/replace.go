@@ -0,0 +1,50 @@
+package di
+
+import (
+	"reflect"
+
+	"github.com/defval/inject/v2/di/internal/reflection"
+)
+
+// replacedInterfaces returns interfaces replaced by definition of the key that does not exist: the interface result
+// type of the replacement or interfaces of As() provide options. Replacement of a concrete type with a fake of
+// another type is bound to the interface of the replaced type.
+func (c *Container) replacedInterfaces(k key, interfaces []interface{}) []*providerInterface {
+	var types []key
+	if k.res.Kind() == reflect.Interface {
+		types = append(types, key{name: k.name, res: k.res, typ: ptInterface})
+	}
+	for _, as := range interfaces {
+		if iface, err := reflection.InspectInterfacePtr(as); err == nil {
+			types = append(types, key{name: k.name, res: iface.Type, typ: ptInterface})
+		}
+	}
+	var replaced []*providerInterface
+	for _, ik := range types {
+		if !c.graph.Exists(ik) {
+			continue
+		}
+		if iface, ok := c.graph.Get(ik).Value.(*providerInterface); ok {
+			replaced = append(replaced, iface)
+		}
+	}
+	return replaced
+}
+
+// rebind makes replacement the only implementation of interface. Replaced implementations keep their definitions,
+// but they are not bound to the interface and leave its group. Later bindings of the interface are ignored.
+func (c *Container) rebind(iface *providerInterface, replacement *definition) {
+	group := newProviderGroup(key{res: iface.res.res}).Key()
+	for _, impl := range iface.impls {
+		impl.unbind(iface.res.res)
+		if c.graph.Exists(group) {
+			c.graph.Get(group).Value.(*providerGroup).remove(impl)
+		}
+	}
+	iface.impls = definitionList{replacement}
+	iface.replacement = replacement
+	if replacement.key.res != iface.res.res {
+		replacement.bind(iface.res.res)
+	}
+	c.processProviderGroup(replacement, "", iface.res.res)
+}
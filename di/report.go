@@ -0,0 +1,226 @@
+package di
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// reportWidestNodes is a number of nodes with the widest fan-in and fan-out in report.
+const reportWidestNodes = 5
+
+// CompileReport is a summary of the compiled container. Container specific definitions are omitted. Nodes and
+// findings are sorted, so reports of the same definitions are equal except of the compile duration and could be
+// compared across releases.
+type CompileReport struct {
+	// Definitions is a number of definitions.
+	Definitions int `json:"definitions"`
+	// Interfaces is a number of interfaces that definitions are bound to.
+	Interfaces int `json:"interfaces"`
+	// Groups is a number of groups: slices of interface implementations and named groups.
+	Groups int `json:"groups"`
+	// MaxDepth is a number of types in the longest resolution chain, it is compared with SetMaxDepth() limit.
+	MaxDepth int `json:"maxDepth"`
+	// FanIn are nodes with the most dependents.
+	FanIn []NodeDegree `json:"fanIn"`
+	// FanOut are nodes with the most dependencies.
+	FanOut []NodeDegree `json:"fanOut"`
+	// Findings are issues that do not fail compile.
+	Findings []Finding `json:"findings"`
+	// Duration is a duration of compile.
+	Duration time.Duration `json:"duration"`
+}
+
+// NodeDegree is a number of edges of a graph node.
+type NodeDegree struct {
+	Key    string `json:"key"`
+	Degree int    `json:"degree"`
+}
+
+// FindingKind is a kind of compile report finding.
+type FindingKind string
+
+const (
+	// FindingPrimary is an interface with several implementations that resolves to the primary one.
+	FindingPrimary FindingKind = "primary"
+	// FindingAmbiguous is an interface with several implementations that could not be resolved.
+	FindingAmbiguous FindingKind = "ambiguous"
+	// FindingOptional is an optional parameter that is not provided, the zero value is used.
+	FindingOptional FindingKind = "optional"
+	// FindingDeprecated is a deprecated definition.
+	FindingDeprecated FindingKind = "deprecated"
+	// FindingShadowed is a struct definition of the type that also has a constructor, fields are set without it.
+	FindingShadowed FindingKind = "shadowed"
+	// FindingSkipped is a definition that is not registered because its condition returned false.
+	FindingSkipped FindingKind = "skipped"
+	// FindingInvalid is an incorrect provider or binding.
+	FindingInvalid FindingKind = "invalid"
+	// FindingUnresolved is a dependency or a setter argument that could not be connected.
+	FindingUnresolved FindingKind = "unresolved"
+	// FindingCycle is a dependency cycle.
+	FindingCycle FindingKind = "cycle"
+	// FindingFailed is a constructor that failed to create a singleton.
+	FindingFailed FindingKind = "failed"
+)
+
+// Finding is an issue of a graph node. Kind is a machine-readable code of the issue. Location is a source location of
+// the definition, it is nil if the location is unknown.
+type Finding struct {
+	Kind     FindingKind `json:"kind"`
+	Severity Severity    `json:"severity"`
+	Key      string      `json:"key"`
+	Message  string      `json:"message"`
+	Location *Location   `json:"location,omitempty"`
+}
+
+// Report returns summary of the compiled container: counts of definitions, the longest resolution chain, nodes with
+// the widest fan-in and fan-out and issues tolerated by compile. Report does not create instances.
+//
+//   report := c.Report()
+//   json.NewEncoder(os.Stdout).Encode(report)
+func (c *Container) Report() CompileReport {
+	c.storage.RLock()
+	defer c.storage.RUnlock()
+	if !c.compiled {
+		panicf("Report() must be called after Compile()")
+	}
+	report := CompileReport{
+		Findings: []Finding{},
+		Duration: c.duration,
+	}
+	fanIn := make([]int, len(c.index.nodes))
+	for _, node := range c.index.nodes {
+		for _, dep := range node.deps {
+			if dep >= 0 {
+				fanIn[dep]++
+			}
+		}
+	}
+	depths := make([]int, len(c.index.nodes))
+	var fanInNodes, fanOutNodes []NodeDegree
+	for id := range c.index.nodes {
+		node := &c.index.nodes[id]
+		if node.def != nil && node.def.isolated {
+			continue
+		}
+		k := c.describe(node.provider.Key())
+		switch provider := node.provider.(type) {
+		case *providerInterface:
+			report.Interfaces++
+			report.Findings = append(report.Findings, interfaceFindings(provider)...)
+		case *providerGroup:
+			report.Groups++
+		}
+		if node.def != nil {
+			report.Definitions++
+			if node.def.deprecated != "" {
+				report.Findings = append(report.Findings, Finding{
+					Kind:     FindingDeprecated,
+					Severity: SeverityWarning,
+					Key:      k,
+					Message:  node.def.deprecated,
+					Location: findingLocation(node.def.location),
+				})
+			}
+			if warning := node.def.shadowWarning(); warning != "" {
+				report.Findings = append(report.Findings, Finding{
+					Kind:     FindingShadowed,
+					Severity: SeverityWarning,
+					Key:      k,
+					Message:  warning,
+					Location: findingLocation(node.def.location),
+				})
+			}
+		}
+		for i, dep := range node.deps {
+			if dep < 0 && node.params[i].optional {
+				report.Findings = append(report.Findings, Finding{
+					Kind:     FindingOptional,
+					Severity: SeverityInfo,
+					Key:      k,
+					Message:  fmt.Sprintf("%s is not provided", node.params[i]),
+				})
+			}
+		}
+		if depth := c.nodeDepth(int32(id), depths); depth > report.MaxDepth {
+			report.MaxDepth = depth
+		}
+		if fanIn[id] != 0 {
+			fanInNodes = append(fanInNodes, NodeDegree{Key: k, Degree: fanIn[id]})
+		}
+		if len(node.params) != 0 {
+			fanOutNodes = append(fanOutNodes, NodeDegree{Key: k, Degree: len(node.params)})
+		}
+	}
+	for _, skipped := range c.skipped {
+		report.Findings = append(report.Findings, Finding{
+			Kind:     FindingSkipped,
+			Severity: SeverityInfo,
+			Key:      skipped.key.String(),
+			Message:  fmt.Sprintf("skipped by condition at %s", skipped.condition),
+			Location: findingLocation(skipped.condition),
+		})
+	}
+	report.FanIn = widestNodes(fanInNodes)
+	report.FanOut = widestNodes(fanOutNodes)
+	sort.Slice(report.Findings, func(i, j int) bool {
+		if report.Findings[i].Kind != report.Findings[j].Kind {
+			return report.Findings[i].Kind < report.Findings[j].Kind
+		}
+		if report.Findings[i].Key != report.Findings[j].Key {
+			return report.Findings[i].Key < report.Findings[j].Key
+		}
+		return report.Findings[i].Message < report.Findings[j].Message
+	})
+	return report
+}
+
+// nodeDepth returns number of types in the longest resolution chain of indexed node. Depths are memoized, the graph
+// is checked for cycles at compile.
+func (c *Container) nodeDepth(id int32, depths []int) int {
+	if depths[id] != 0 {
+		return depths[id]
+	}
+	depth := 1
+	for _, dep := range c.index.nodes[id].deps {
+		if dep < 0 {
+			continue
+		}
+		if d := c.nodeDepth(dep, depths) + 1; d > depth {
+			depth = d
+		}
+	}
+	depths[id] = depth
+	return depth
+}
+
+// interfaceFindings returns finding of interface with several implementations.
+func interfaceFindings(iface *providerInterface) []Finding {
+	if len(iface.impls) < 2 {
+		return nil
+	}
+	def, err := iface.Implementation()
+	if err != nil {
+		return []Finding{{Kind: FindingAmbiguous, Severity: SeverityWarning, Key: iface.res.String(), Message: err.Error()}}
+	}
+	return []Finding{{
+		Kind:     FindingPrimary,
+		Severity: SeverityInfo,
+		Key:      iface.res.String(),
+		Message:  fmt.Sprintf("%s is primary of %d implementations", def.key, len(iface.impls)),
+	}}
+}
+
+// widestNodes sorts nodes by degree and key and returns the widest ones.
+func widestNodes(nodes []NodeDegree) []NodeDegree {
+	sort.Slice(nodes, func(i, j int) bool {
+		if nodes[i].Degree != nodes[j].Degree {
+			return nodes[i].Degree > nodes[j].Degree
+		}
+		return nodes[i].Key < nodes[j].Key
+	})
+	if len(nodes) > reportWidestNodes {
+		nodes = nodes[:reportWidestNodes]
+	}
+	return append([]NodeDegree{}, nodes...)
+}
@@ -0,0 +1,95 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"sync/atomic"
+
+	"github.com/defval/inject/v2/di/internal/reflection"
+)
+
+// setter is a method of definition type that is called with resolved arguments after the instance is created.
+// Arguments of setters are not dependencies of the definition, so types could refer to each other with setters.
+type setter struct {
+	method reflect.Method
+	params parameterList
+}
+
+// newSetter inspects setter method of the definition type. The method takes one or more arguments and returns nothing
+// or an error.
+func newSetter(k key, location reflection.Location, name string) setter {
+	method, ok := k.res.MethodByName(name)
+	if !ok || k.res.Kind() == reflect.Interface {
+		panicf("%s: %s has no setter method %s", location, k, name)
+	}
+	typ := method.Type
+	if typ.NumIn() < 2 || typ.NumOut() > 1 || typ.NumOut() == 1 && !reflection.IsError(typ.Out(0)) {
+		panicf("%s: setter %s of %s must be a method like `func(dep1, [dep2, ...]) [error]`, got `%s`", location,
+			name, k, typ)
+	}
+	s := setter{method: method}
+	for i := 1; i < typ.NumIn(); i++ {
+		s.params = append(s.params, parameter{res: typ.In(i), embed: isEmbedParameter(typ.In(i))})
+	}
+	return s
+}
+
+// call resolves setter arguments and calls the setter of the instance.
+func (s setter) call(c *Container, instance reflect.Value) error {
+	args, err := s.params.Resolve(c)
+	if err != nil {
+		return err
+	}
+	var results []reflect.Value
+	err = c.recovered(func() {
+		results = s.method.Func.Call(append([]reflect.Value{instance}, args.values...))
+	})
+	args.release()
+	if err != nil || len(results) == 0 || results[0].IsNil() {
+		return err
+	}
+	return results[0].Interface().(error)
+}
+
+// wire calls setters of the created instance. Singleton is wired once, prototypes and fresh instances are wired on
+// each creation. Setters of singletons created by BuildAll() wait for Wire(). The instance is created before, so setters could resolve types that depend on it.
+func (c *Container) wire(def *definition, instance reflect.Value, fresh bool) error {
+	if len(def.setters) == 0 {
+		return nil
+	}
+	if !def.prototype && !fresh && (c.isWiringDeferred() || !atomic.CompareAndSwapUint32(&def.wired, 0, 1)) {
+		return nil
+	}
+	for _, s := range def.setters {
+		if err := s.call(c, instance); err != nil {
+			return c.provideFailed(def.key, fmt.Errorf("setter %s: %w", s.method.Name, err))
+		}
+	}
+	return nil
+}
+
+// created wires instance of definition created by provider call. Nil definition is not wired.
+func (c *Container) created(def *definition, instance reflect.Value, err error, fresh bool) (reflect.Value, error) {
+	if err != nil || def == nil {
+		return instance, err
+	}
+	if err := c.wire(def, instance, fresh); err != nil {
+		return reflect.Value{}, err
+	}
+	return instance, nil
+}
+
+// checkSetters checks that arguments of setters exist in container.
+func (c *Container) checkSetters() {
+	for _, def := range c.definitions {
+		c.step = step{stage: StageConnect, key: def.key}
+		for _, s := range def.setters {
+			for _, param := range s.params {
+				if _, exists := param.ResolveProvider(c); !exists {
+					panicf("%s: setter %s: dependency %s not exists in container", c.describe(def.key), s.method.Name,
+						param)
+				}
+			}
+		}
+	}
+}
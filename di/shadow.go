@@ -0,0 +1,61 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/defval/inject/v2/di/internal/reflection"
+)
+
+// isStructDefinition checks that provider sets fields of a struct instead of calling a constructor.
+func isStructDefinition(provider internalProvider) bool {
+	_, ok := unwrapped(provider).(*providerStruct)
+	return ok
+}
+
+// isConstructorDefinition checks that provider calls a constructor.
+func isConstructorDefinition(provider internalProvider) bool {
+	_, ok := unwrapped(provider).(*providerConstructor)
+	return ok
+}
+
+// checkShadows marks struct definitions of types that also have a constructor definition under any name or tags.
+// Fields of such struct are set without the constructor, so its invariants are bypassed. Replacements of constructors
+// by struct providers are marked on provide.
+func (c *Container) checkShadows() {
+	constructors := map[reflect.Type]*definition{}
+	for _, def := range c.definitions {
+		if def.isolated || !isConstructorDefinition(def.provider) {
+			continue
+		}
+		if _, ok := constructors[def.key.res]; !ok {
+			constructors[def.key.res] = def
+		}
+	}
+	for _, def := range c.definitions {
+		if def.shadows != nil || !isStructDefinition(def.provider) {
+			continue
+		}
+		if constructor, ok := constructors[def.key.res]; ok {
+			location := constructor.location
+			def.shadows = &location
+		}
+	}
+}
+
+// shadowWarning returns warning of struct definition that shadows a constructor, empty if it does not.
+func (d *definition) shadowWarning() string {
+	if d.shadows == nil {
+		return ""
+	}
+	return fmt.Sprintf("struct provider shadows constructor at %s, fields are set without calling it", d.shadows)
+}
+
+// shadowedLocation returns location of replaced constructor if struct provider replaces it, nil otherwise.
+func shadowedLocation(replaced *definition, provider internalProvider) *reflection.Location {
+	if !isStructDefinition(provider) || !isConstructorDefinition(replaced.provider) {
+		return nil
+	}
+	location := replaced.location
+	return &location
+}
@@ -0,0 +1,110 @@
+package di
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/defval/inject/v2/di/internal/reflection"
+)
+
+// signatureCacheSize is a maximum number of cached constructor signatures.
+const signatureCacheSize = 1024
+
+// signatures caches constructor signatures by function type. Containers are often created from the same
+// constructors, for example in tests, so repeated provide of constructor skips signature inspection.
+var signatures = newSignatureCache(signatureCacheSize)
+
+// SetSignatureCacheSize clears the cache of constructor signatures and sets its size. Zero size disables the cache.
+func SetSignatureCacheSize(size int) {
+	signatures.Resize(size)
+}
+
+// signature is a constructor metadata that depends only on constructor type.
+type signature struct {
+	ctorType ctorType
+	result   reflect.Type
+	params   parameterList // names of parameter bags are set by provider
+	bag      bool          // has parameter bag
+	context  bool          // the first parameter is context.Context, it is not a dependency
+}
+
+// inspectSignature inspects constructor type. It panics if the constructor signature is incorrect.
+func inspectSignature(fn *reflection.Func) *signature {
+	ctorType := determineCtorType(fn)
+	sig := &signature{
+		ctorType: ctorType,
+		result:   fn.Out(0),
+	}
+	for i := 0; i < fn.NumIn(); i++ {
+		ptype := fn.In(i)
+		if ptype == contextType {
+			if i != 0 {
+				panicf("%s: invalid constructor `%s`: context.Context is parameter %d, the context must be the first "+
+					"parameter like `func(ctx context.Context, [dep1, dep2, ...]) (<result>, [cleanup, error])`",
+					fn.Location, renderSignature(fn.Type), i+1)
+			}
+			sig.context = true
+			continue
+		}
+		sig.params = append(sig.params, parameter{
+			res:   ptype,
+			embed: isEmbedParameter(ptype),
+		})
+		if ptype == parameterBagType {
+			sig.bag = true
+		}
+	}
+	return sig
+}
+
+// newSignatureCache creates signature cache that holds up to size signatures.
+func newSignatureCache(size int) *signatureCache {
+	return &signatureCache{
+		size:    size,
+		entries: map[reflect.Type]*signature{},
+	}
+}
+
+// signatureCache is a concurrent safe cache of constructor signatures. The cache is cleared when it is full.
+type signatureCache struct {
+	mu      sync.RWMutex
+	size    int
+	entries map[reflect.Type]*signature
+}
+
+// Get returns signature of constructor from cache or inspects it.
+func (c *signatureCache) Get(fn *reflection.Func) *signature {
+	c.mu.RLock()
+	sig, cached := c.entries[fn.Type]
+	size := c.size
+	c.mu.RUnlock()
+	if cached {
+		return sig
+	}
+	sig = inspectSignature(fn)
+	if size == 0 {
+		return sig
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= c.size {
+		c.entries = map[reflect.Type]*signature{}
+	}
+	c.entries[fn.Type] = sig
+	return sig
+}
+
+// Resize clears the cache and sets its size. Zero size disables the cache.
+func (c *signatureCache) Resize(size int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.size = size
+	c.entries = map[reflect.Type]*signature{}
+}
+
+// Len returns number of cached signatures.
+func (c *signatureCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.entries)
+}
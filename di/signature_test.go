@@ -0,0 +1,72 @@
+package di
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/defval/inject/v2/di/internal/reflection"
+)
+
+func TestSignatureCache(t *testing.T) {
+	newMux := func() *http.ServeMux { return &http.ServeMux{} }
+	newServer := func(mux *http.ServeMux) *http.Server { return &http.Server{Handler: mux} }
+
+	t.Run("signature of the same constructor type is cached", func(t *testing.T) {
+		cache := newSignatureCache(2)
+		first := cache.Get(reflection.InspectFunction(newServer))
+		second := cache.Get(reflection.InspectFunction(func(*http.ServeMux) *http.Server { return nil }))
+		require.True(t, first == second)
+		require.Equal(t, ctorStd, first.ctorType)
+		require.Len(t, first.params, 1)
+	})
+
+	t.Run("full cache is cleared", func(t *testing.T) {
+		cache := newSignatureCache(1)
+		cache.Get(reflection.InspectFunction(newMux))
+		cache.Get(reflection.InspectFunction(newServer))
+		require.Equal(t, 1, cache.Len())
+	})
+
+	t.Run("zero size disables cache", func(t *testing.T) {
+		cache := newSignatureCache(2)
+		cache.Get(reflection.InspectFunction(newMux))
+		cache.Resize(0)
+		require.Equal(t, 0, cache.Len())
+		first := cache.Get(reflection.InspectFunction(newMux))
+		second := cache.Get(reflection.InspectFunction(newMux))
+		require.True(t, first != second)
+		require.Equal(t, 0, cache.Len())
+	})
+
+	t.Run("incorrect signature is not cached", func(t *testing.T) {
+		cache := newSignatureCache(2)
+		require.Panics(t, func() {
+			cache.Get(reflection.InspectFunction(func() {}))
+		})
+		require.Equal(t, 0, cache.Len())
+	})
+
+	t.Run("global cache could be disabled", func(t *testing.T) {
+		defer SetSignatureCacheSize(signatureCacheSize)
+		SetSignatureCacheSize(0)
+		newProviderConstructor("", nil, newMux)
+		require.Equal(t, 0, signatures.Len())
+	})
+
+	t.Run("parameter bag name depends on provider", func(t *testing.T) {
+		newFoo := func(ParameterBag) *http.ServeMux { return nil }
+		first := newProviderConstructor("first", nil, newFoo)
+		second := newProviderConstructor("second", nil, newFoo)
+		require.Equal(t, "*http.ServeMux[first]", first.ParameterList()[0].name)
+		require.Equal(t, "*http.ServeMux[second]", second.ParameterList()[0].name)
+	})
+
+	t.Run("arguments are computed once", func(t *testing.T) {
+		newFoo := func(ParameterBag) *http.ServeMux { return nil }
+		provider := newProviderConstructor("", nil, newFoo)
+		first, second := provider.ParameterList(), provider.ParameterList()
+		require.Same(t, &first[0], &second[0])
+	})
+}
@@ -2,6 +2,7 @@ package di
 
 import (
 	"reflect"
+	"sync"
 )
 
 // asSingleton creates a singleton wrapper.
@@ -12,16 +13,71 @@ func asSingleton(provider internalProvider) *singletonWrapper {
 // singletonWrapper is a embedParamProvider wrapper. Stores provided value for prevent reinitialization.
 type singletonWrapper struct {
 	internalProvider               // source provider
+	retryOnError     bool          // do not cache error
+	mu               sync.Mutex    // guards value and error cache shared between containers
 	value            reflect.Value // value cache
+	err              error         // error cache
 }
 
-// Provide
+// Provide returns cached value or error. Concurrent calls wait for the first one, so the constructor is called once.
+// If retry on error is enabled, the failed call does not affect the next one.
 func (s *singletonWrapper) Provide(values ...reflect.Value) (reflect.Value, func(), error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	if s.value.IsValid() {
 		return s.value, nil, nil
 	}
+	if s.err != nil {
+		return reflect.Value{}, nil, s.err
+	}
 	value, cleanup, err := s.internalProvider.Provide(values...)
+	if err != nil {
+		if !s.retryOnError {
+			s.err = err
+		}
+		return value, cleanup, err
+	}
 	s.value = value
 
-	return value, cleanup, err
+	return value, cleanup, nil
+}
+
+// cached returns created value.
+func (s *singletonWrapper) cached() (reflect.Value, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.value, s.value.IsValid()
+}
+
+// cachedValue returns value of provider that does not need parameters: provided value or created singleton.
+func cachedValue(provider internalProvider) (reflect.Value, bool) {
+	switch p := provider.(type) {
+	case *providerValue:
+		return p.value, true
+	case *singletonWrapper:
+		return p.cached()
+	case *providerInherited:
+		return cachedValue(p.internalProvider)
+	default:
+		return reflect.Value{}, false
+	}
+}
+
+// freshProvider returns provider that bypasses singleton cache of the definition. Dependencies of the definition are
+// resolved as usual. Interfaces are unwrapped to their implementation.
+func freshProvider(provider internalProvider) (internalProvider, error) {
+	switch p := provider.(type) {
+	case *singletonWrapper:
+		return p.internalProvider, nil
+	case *providerInherited:
+		return freshProvider(p.internalProvider)
+	case *providerInterface:
+		def, err := p.Implementation()
+		if err != nil {
+			return nil, err
+		}
+		return freshProvider(def.provider)
+	default:
+		return provider, nil
+	}
 }
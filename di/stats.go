@@ -0,0 +1,127 @@
+package di
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats is a snapshot of container statistics. Counters of resolutions and built instances are read at once, so they
+// are consistent with each other.
+type Stats struct {
+	// Definitions is a number of definitions.
+	Definitions int `json:"definitions"`
+	// Singletons is a number of singleton definitions.
+	Singletons int `json:"singletons"`
+	// Prototypes is a number of prototype definitions.
+	Prototypes int `json:"prototypes"`
+	// Built is a number of instances created by constructors. Prototypes are counted on each creation, provided values
+	// are not counted.
+	Built uint64 `json:"built"`
+	// Resolutions is a number of resolutions of Extract(), Invoke() and WarmUp() targets.
+	Resolutions uint64 `json:"resolutions"`
+	// Failures is a number of resolutions that returned errors.
+	Failures uint64 `json:"failures"`
+	// ConstructionTime is a cumulative duration of constructor calls of built instances.
+	ConstructionTime time.Duration `json:"constructionTime"`
+	// MaxConstructionTime is the longest constructor call of built instances.
+	MaxConstructionTime time.Duration `json:"maxConstructionTime"`
+}
+
+// statsCounters are counters of container statistics. Updates are atomic and do not allocate. Updates hold the read
+// lock, so they do not wait for each other, and a snapshot holds the write lock. A pending snapshot blocks new updates,
+// so it is not starved by steady resolution traffic.
+type statsCounters struct {
+	mu          sync.RWMutex
+	built       uint64
+	resolutions uint64
+	failures    uint64
+	total       int64
+	max         int64
+}
+
+// resolved counts resolution of target.
+func (s *statsCounters) resolved(err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	atomic.AddUint64(&s.resolutions, 1)
+	if err != nil {
+		atomic.AddUint64(&s.failures, 1)
+	}
+}
+
+// created counts created instance.
+func (s *statsCounters) created(duration time.Duration) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	atomic.AddUint64(&s.built, 1)
+	atomic.AddInt64(&s.total, int64(duration))
+	for {
+		max := atomic.LoadInt64(&s.max)
+		if int64(duration) <= max || atomic.CompareAndSwapInt64(&s.max, max, int64(duration)) {
+			break
+		}
+	}
+}
+
+// snapshot reads counters without concurrent updates.
+func (s *statsCounters) snapshot(stats *Stats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats.Built = atomic.LoadUint64(&s.built)
+	stats.Resolutions = atomic.LoadUint64(&s.resolutions)
+	stats.Failures = atomic.LoadUint64(&s.failures)
+	stats.ConstructionTime = time.Duration(atomic.LoadInt64(&s.total))
+	stats.MaxConstructionTime = time.Duration(atomic.LoadInt64(&s.max))
+}
+
+// Stats returns statistics of the container: numbers of definitions by lifetime, built instances, resolutions and
+// durations of constructor calls. Counters are collected on resolution, export them as gauges to spot wiring
+// regressions like unexpected prototypes.
+//
+//   stats := c.Stats()
+//   builtGauge.Set(float64(stats.Built))
+func (c *Container) Stats() Stats {
+	var stats Stats
+	c.storage.RLock()
+	for _, def := range c.definitions {
+		if def.isolated {
+			continue
+		}
+		stats.Definitions++
+		if def.prototype {
+			stats.Prototypes++
+		} else {
+			stats.Singletons++
+		}
+	}
+	c.storage.RUnlock()
+	c.stats.snapshot(&stats)
+	return stats
+}
+
+// construct calls provider of definition and counts built instance. Instances are not created after
+// ReleaseInstances() that rejects resolution.
+func (c *Container) construct(def *definition, provider internalProvider, values []reflect.Value, fresh bool) (reflect.Value, error) {
+	var built bool
+	if def != nil {
+		_, isValue := unwrapped(def.provider).(*providerValue)
+		built = !isValue
+	}
+	if built && atomic.LoadInt32(&c.released) != 0 {
+		return reflect.Value{}, c.provideFailed(def.key, ErrReleased)
+	}
+	start := time.Now()
+	c.dev.begin(provider.Key())
+	value, err := c.call(provider, c.withContext(provider, values))
+	c.dev.end()
+	if err == nil && built {
+		c.stats.created(time.Since(start))
+	}
+	value, err = c.created(def, value, err, fresh)
+	if err == nil && !fresh {
+		c.dev.cached(def, value)
+	}
+	return value, err
+}
@@ -0,0 +1,44 @@
+package di
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Tags is a set of arbitrary key/value definition attributes. Tags allow providing several definitions of the same
+// type and selecting them on resolving.
+type Tags map[string]string
+
+// String returns canonical representation of tags sorted by key. Keys and values that contain separators, quotes or
+// backslashes are quoted, so different tags never have the same representation.
+func (t Tags) String() string {
+	if len(t) == 0 {
+		return ""
+	}
+	pairs := make([]string, 0, len(t))
+	for k, v := range t {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", quoteTag(k), quoteTag(v)))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+// quoteTag quotes tag key or value if it contains characters of tags representation.
+func quoteTag(s string) string {
+	if !strings.ContainsAny(s, `,="\`) {
+		return s
+	}
+	return strconv.Quote(s)
+}
+
+// Contains checks that tags contain all other tags.
+func (t Tags) Contains(other Tags) bool {
+	for k, v := range other {
+		if value, ok := t[k]; !ok || value != v {
+			return false
+		}
+	}
+	return true
+}
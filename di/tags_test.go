@@ -0,0 +1,24 @@
+package di
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTags_String(t *testing.T) {
+	t.Run("pairs sorted by key", func(t *testing.T) {
+		require.Equal(t, "region=eu,tier=premium", Tags{"tier": "premium", "region": "eu"}.String())
+	})
+
+	t.Run("separators are quoted", func(t *testing.T) {
+		require.Equal(t, `a="1,b=2"`, Tags{"a": "1,b=2"}.String())
+		require.Equal(t, "a=1,b=2", Tags{"a": "1", "b": "2"}.String())
+		require.Equal(t, `"a=1"=2`, Tags{"a=1": "2"}.String())
+		require.Equal(t, `a="say \"hi\""`, Tags{"a": `say "hi"`}.String())
+	})
+
+	t.Run("empty tags", func(t *testing.T) {
+		require.Equal(t, "", Tags{}.String())
+	})
+}
@@ -0,0 +1,150 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// qualifiedTypeNames is set if all named types are rendered with package paths.
+var qualifiedTypeNames int32
+
+// SetQualifiedTypeNames sets rendering of types in errors, dumps and graphs. By default types are rendered with
+// package names like `*config.Config` and named types are rendered with package paths like
+// `*github.com/acme/billing/config.Config` only if a type with the same name from another package is used by
+// a container. If enabled, all named types are rendered with package paths.
+func SetQualifiedTypeNames(enabled bool) {
+	var value int32
+	if enabled {
+		value = 1
+	}
+	atomic.StoreInt32(&qualifiedTypeNames, value)
+}
+
+// typeNames are package paths of named types by their short names like `config.Config`. Types with the same short
+// name from different packages are ambiguous. Types are registered by containers of the process, so ambiguous types
+// are rendered with package paths in all containers.
+var typeNames = struct {
+	sync.RWMutex
+	paths map[string]map[string]bool
+}{paths: map[string]map[string]bool{}}
+
+// registerTypeNames registers named types that the type consists of.
+func registerTypeNames(typ reflect.Type) {
+	walkNamedTypes(typ, func(named reflect.Type) {
+		short := named.String()
+		typeNames.RLock()
+		registered := typeNames.paths[short][named.PkgPath()]
+		typeNames.RUnlock()
+		if registered {
+			return
+		}
+		typeNames.Lock()
+		defer typeNames.Unlock()
+		if typeNames.paths[short] == nil {
+			typeNames.paths[short] = map[string]bool{}
+		}
+		typeNames.paths[short][named.PkgPath()] = true
+	})
+}
+
+// isAmbiguousType checks that the type consists of named types with the same short names as types from other packages.
+func isAmbiguousType(typ reflect.Type) bool {
+	var ambiguous bool
+	walkNamedTypes(typ, func(named reflect.Type) {
+		typeNames.RLock()
+		defer typeNames.RUnlock()
+		ambiguous = ambiguous || len(typeNames.paths[named.String()]) > 1
+	})
+	return ambiguous
+}
+
+// walkNamedTypes calls fn with named types of packages that the type consists of: the type itself, elements of
+// pointers and collections and keys of maps.
+func walkNamedTypes(typ reflect.Type, fn func(named reflect.Type)) {
+	if typ.Name() != "" {
+		if typ.PkgPath() != "" {
+			fn(typ)
+		}
+		return
+	}
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Chan:
+		walkNamedTypes(typ.Elem(), fn)
+	case reflect.Map:
+		walkNamedTypes(typ.Key(), fn)
+		walkNamedTypes(typ.Elem(), fn)
+	}
+}
+
+// renderType renders type for messages: with package paths if the type is ambiguous or qualified names are enabled.
+func renderType(typ reflect.Type) string {
+	if atomic.LoadInt32(&qualifiedTypeNames) == 1 || isAmbiguousType(typ) {
+		return qualifiedTypeString(typ)
+	}
+	return shortTypeArguments(typ.String())
+}
+
+// typeArgumentPath matches package path of type argument of generic type instantiation.
+var typeArgumentPath = regexp.MustCompile(`(?:[\w.~-]+/)+([\w~-]+)\.`)
+
+// shortTypeArguments renders type arguments of generic types with package names instead of package paths, like
+// `cache.Cache[model.User]` instead of `cache.Cache[github.com/acme/app/model.User]`.
+func shortTypeArguments(typ string) string {
+	if !strings.Contains(typ, "[") {
+		return typ
+	}
+	return typeArgumentPath.ReplaceAllString(typ, "$1.")
+}
+
+// renderSignature renders function type with rendered types of parameters and results, for example
+// `func(*config.Config, ...log.Option) (*http.Server, string)`.
+func renderSignature(fn reflect.Type) string {
+	in := make([]string, fn.NumIn())
+	for i := range in {
+		if fn.IsVariadic() && i == len(in)-1 {
+			in[i] = "..." + renderType(fn.In(i).Elem())
+			continue
+		}
+		in[i] = renderType(fn.In(i))
+	}
+	out := make([]string, fn.NumOut())
+	for i := range out {
+		out[i] = renderType(fn.Out(i))
+	}
+	signature := "func(" + strings.Join(in, ", ") + ")"
+	switch len(out) {
+	case 0:
+		return signature
+	case 1:
+		return signature + " " + out[0]
+	default:
+		return signature + " (" + strings.Join(out, ", ") + ")"
+	}
+}
+
+// qualifiedTypeString renders type with package paths of named types.
+func qualifiedTypeString(typ reflect.Type) string {
+	if typ.Name() != "" {
+		if typ.PkgPath() != "" {
+			return typ.PkgPath() + "." + typ.Name()
+		}
+		return typ.String()
+	}
+	switch typ.Kind() {
+	case reflect.Ptr:
+		return "*" + qualifiedTypeString(typ.Elem())
+	case reflect.Slice:
+		return "[]" + qualifiedTypeString(typ.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", typ.Len(), qualifiedTypeString(typ.Elem()))
+	case reflect.Chan:
+		return typ.ChanDir().String() + " " + qualifiedTypeString(typ.Elem())
+	case reflect.Map:
+		return fmt.Sprintf("map[%s]%s", qualifiedTypeString(typ.Key()), qualifiedTypeString(typ.Elem()))
+	}
+	return typ.String()
+}
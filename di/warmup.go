@@ -0,0 +1,96 @@
+package di
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// ErrWarmUp is an error of WarmUp() with errors of failed roots in order of targets. Use errors.As() with the error
+// to get errors of roots and DependencyPath() with errors of roots to get their dependency paths.
+type ErrWarmUp struct {
+	roots []string
+	errs  []error
+	total int
+}
+
+func (e ErrWarmUp) Error() string {
+	failures := make([]string, 0, len(e.errs))
+	for i, err := range e.errs {
+		failure := err.Error()
+		if !strings.HasPrefix(failure, e.roots[i]+":") {
+			failure = fmt.Sprintf("%s: %s", e.roots[i], failure)
+		}
+		if path := DependencyPath(err); len(path) > 1 {
+			failure += fmt.Sprintf(" (path: %s)", strings.Join(path, " -> "))
+		}
+		failures = append(failures, failure)
+	}
+	return fmt.Sprintf("warm up failed for %d of %d roots: %s", len(e.errs), e.total, strings.Join(failures, "; "))
+}
+
+// Roots returns types of failed roots.
+func (e ErrWarmUp) Roots() []string {
+	return append([]string(nil), e.roots...)
+}
+
+// Unwrap returns errors of failed roots.
+func (e ErrWarmUp) Unwrap() []error {
+	return append([]error(nil), e.errs...)
+}
+
+// WarmUp creates instances of targets and their dependencies without extracting them. Targets are pointers like
+// Extract() targets. Singletons stay cached, so the first resolution of warmed up types does not call constructors.
+// Types that are not reachable from targets stay lazy. WarmUp resolves all targets and returns ErrWarmUp with errors
+// of failed ones.
+//
+//   err := c.WarmUp(new(*http.Server), new(*sql.DB))
+func (c *Container) WarmUp(targets ...interface{}) error {
+	return c.warmUp(targets, false)
+}
+
+// WarmUpParallel is WarmUp() that resolves each target in its own goroutine. Shared dependencies are created once.
+func (c *Container) WarmUpParallel(targets ...interface{}) error {
+	return c.warmUp(targets, true)
+}
+
+// warmUp resolves targets and collects their errors.
+func (c *Container) warmUp(targets []interface{}, parallel bool) error {
+	roots := make([]string, len(targets))
+	errs := make([]error, len(targets))
+	resolve := func(i int) {
+		param, err := c.extractParameter(targets[i], nil)
+		if err != nil {
+			roots[i], errs[i] = fmt.Sprintf("%T", targets[i]), err
+			return
+		}
+		roots[i] = param.String()
+		c.dev.enter(roots[i])
+		_, errs[i] = param.ResolveValue(c)
+		c.stats.resolved(errs[i])
+	}
+	var wg sync.WaitGroup
+	for i := range targets {
+		if !parallel {
+			resolve(i)
+			continue
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resolve(i)
+		}(i)
+	}
+	wg.Wait()
+	failed := ErrWarmUp{total: len(targets)}
+	for i, err := range errs {
+		if err != nil {
+			failed.roots = append(failed.roots, roots[i])
+			failed.errs = append(failed.errs, err)
+		}
+	}
+	if len(failed.errs) == 0 {
+		return nil
+	}
+	return failed
+}
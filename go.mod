@@ -1,10 +1,19 @@
 module github.com/defval/inject/v2
 
+go 1.19
+
 require (
-	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/emicklei/dot v0.10.1
-	github.com/kr/pretty v0.1.0 // indirect
 	github.com/stretchr/testify v1.4.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/kr/pretty v0.1.0 // indirect
+	github.com/kr/pty v1.1.1 // indirect
+	github.com/kr/text v0.1.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/stretchr/objx v0.1.0 // indirect
 	gopkg.in/check.v1 v1.0.0-20190902080502-41f04d3bba15 // indirect
 	gopkg.in/yaml.v2 v2.2.8 // indirect
 )
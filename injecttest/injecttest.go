@@ -0,0 +1,94 @@
+// Package injecttest provides helpers for testing container wiring.
+//
+//   func TestWiring(t *testing.T) {
+//     c := injecttest.NewWithOverrides(t, app.Options(), inject.Replace(NewFakeClock))
+//
+//     var server *http.Server
+//     injecttest.RequireResolves(t, c, &server)
+//     injecttest.RequireImplements(t, c, (*http.Handler)(nil), &http.ServeMux{})
+//   }
+package injecttest
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/defval/inject/v2"
+	"github.com/defval/inject/v2/di"
+)
+
+// NewWithOverrides creates a container from base options and overrides. Overrides applied after base options, so
+// inject.Replace() options can substitute base definitions. Container cleanup registered via t.Cleanup().
+func NewWithOverrides(t testing.TB, base []inject.Option, overrides ...inject.Option) (c *inject.Container) {
+	t.Helper()
+	options := make([]inject.Option, 0, len(base)+len(overrides))
+	options = append(options, base...)
+	options = append(options, overrides...)
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				t.Fatalf("container compilation failed: %v", r)
+			}
+		}()
+		c = inject.New(options...)
+	}()
+	t.Cleanup(c.Cleanup)
+	return c
+}
+
+// RequireResolves checks that target type resolves from the container and fills the target.
+func RequireResolves(t testing.TB, c *inject.Container, target interface{}, options ...inject.ExtractOption) {
+	t.Helper()
+	if err := c.Extract(target, options...); err != nil {
+		t.Fatalf("%s does not resolve: %s%s", targetType(target), err, formatPath(err))
+	}
+}
+
+// RequireNotProvided checks that target type does not exist in the container. It does not create instances.
+func RequireNotProvided(t testing.TB, c *inject.Container, target interface{}, options ...inject.ExtractOption) {
+	t.Helper()
+	if c.Has(target, options...) {
+		t.Fatalf("%s provided, but should not", targetType(target))
+	}
+}
+
+// RequireImplements checks that interface resolves from the container as instance of the expected type. The iface
+// argument is a nil pointer to interface.
+//
+//   injecttest.RequireImplements(t, c, (*http.Handler)(nil), &http.ServeMux{})
+func RequireImplements(t testing.TB, c *inject.Container, iface interface{}, expected interface{}, options ...inject.ExtractOption) {
+	t.Helper()
+	typ := reflect.TypeOf(iface)
+	if typ == nil || typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Interface {
+		t.Fatalf("iface must be a pointer to interface, got %v", typ)
+	}
+	target := reflect.New(typ.Elem())
+	RequireResolves(t, c, target.Interface(), options...)
+	actual := reflect.TypeOf(target.Elem().Interface())
+	if actual != reflect.TypeOf(expected) {
+		t.Fatalf("%s resolves as %s, expected %s", typ.Elem(), actual, reflect.TypeOf(expected))
+	}
+}
+
+// targetType
+func targetType(target interface{}) string {
+	typ := reflect.TypeOf(target)
+	if typ == nil {
+		return "nil"
+	}
+	if typ.Kind() == reflect.Ptr {
+		return typ.Elem().String()
+	}
+	return typ.String()
+}
+
+// formatPath
+func formatPath(err error) string {
+	path := di.DependencyPath(err)
+	if len(path) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\ndependency path: %s", strings.Join(path, " -> "))
+}
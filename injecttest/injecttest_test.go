@@ -0,0 +1,152 @@
+package injecttest_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/defval/inject/v2"
+	"github.com/defval/inject/v2/injecttest"
+)
+
+func TestHelpers(t *testing.T) {
+	base := []inject.Option{
+		inject.Provide(NewServer),
+		inject.Provide(NewMux, inject.As(new(http.Handler))),
+	}
+
+	t.Run("resolves", func(t *testing.T) {
+		c := injecttest.NewWithOverrides(t, base)
+		var server *http.Server
+		injecttest.RequireResolves(t, c, &server)
+		require.NotNil(t, server)
+	})
+
+	t.Run("not provided", func(t *testing.T) {
+		c := injecttest.NewWithOverrides(t, base)
+		var client *http.Client
+		injecttest.RequireNotProvided(t, c, &client)
+	})
+
+	t.Run("implements", func(t *testing.T) {
+		c := injecttest.NewWithOverrides(t, base)
+		injecttest.RequireImplements(t, c, (*http.Handler)(nil), &http.ServeMux{})
+	})
+
+	t.Run("overrides replace base definitions", func(t *testing.T) {
+		mux := &http.ServeMux{}
+		c := injecttest.NewWithOverrides(t, base, inject.Replace(func() *http.ServeMux { return mux }))
+		var handler http.Handler
+		injecttest.RequireResolves(t, c, &handler)
+		require.Equal(t, fmt.Sprintf("%p", mux), fmt.Sprintf("%p", handler))
+	})
+
+	t.Run("cleanup registered", func(t *testing.T) {
+		var cleaned bool
+		t.Run("container", func(t *testing.T) {
+			c := injecttest.NewWithOverrides(t, nil, inject.Provide(func() (*http.ServeMux, func()) {
+				return &http.ServeMux{}, func() { cleaned = true }
+			}))
+			var mux *http.ServeMux
+			injecttest.RequireResolves(t, c, &mux)
+		})
+		require.True(t, cleaned)
+	})
+}
+
+func TestHelpersFailures(t *testing.T) {
+	base := []inject.Option{
+		inject.Provide(NewServer),
+		inject.Provide(NewBrokenMux, inject.As(new(http.Handler))),
+	}
+
+	t.Run("resolve failure prints dependency path", func(t *testing.T) {
+		c := injecttest.NewWithOverrides(t, base)
+		msg := failure(t, func(ft testing.TB) {
+			var server *http.Server
+			injecttest.RequireResolves(ft, c, &server)
+		})
+		require.Equal(t, "*http.Server does not resolve: *http.ServeMux: mux broken\n"+
+			"dependency path: *http.Server -> http.Handler -> *http.ServeMux", msg)
+	})
+
+	t.Run("not provided fails on existing type", func(t *testing.T) {
+		c := injecttest.NewWithOverrides(t, base)
+		msg := failure(t, func(ft testing.TB) {
+			var server *http.Server
+			injecttest.RequireNotProvided(ft, c, &server)
+		})
+		require.Equal(t, "*http.Server provided, but should not", msg)
+	})
+
+	t.Run("not provided does not call constructors", func(t *testing.T) {
+		var calls int
+		c := injecttest.NewWithOverrides(t, nil, inject.Provide(func() *http.Server {
+			calls++
+			return &http.Server{}
+		}))
+		failure(t, func(ft testing.TB) {
+			var server *http.Server
+			injecttest.RequireNotProvided(ft, c, &server)
+		})
+		require.Zero(t, calls)
+	})
+
+	t.Run("implements fails on other type", func(t *testing.T) {
+		c := injecttest.NewWithOverrides(t, nil, inject.Provide(NewMux, inject.As(new(http.Handler))))
+		msg := failure(t, func(ft testing.TB) {
+			injecttest.RequireImplements(ft, c, (*http.Handler)(nil), http.NotFoundHandler())
+		})
+		require.Equal(t, "http.Handler resolves as *http.ServeMux, expected http.HandlerFunc", msg)
+	})
+
+	t.Run("compilation failure", func(t *testing.T) {
+		msg := failure(t, func(ft testing.TB) {
+			injecttest.NewWithOverrides(ft, nil, inject.Provide(NewServer))
+		})
+		require.Equal(t, "container compilation failed: *http.Server: dependency http.Handler not exists in container", msg)
+	})
+}
+
+// NewServer
+func NewServer(handler http.Handler) *http.Server {
+	return &http.Server{Handler: handler}
+}
+
+// NewMux
+func NewMux() *http.ServeMux {
+	return &http.ServeMux{}
+}
+
+// NewBrokenMux
+func NewBrokenMux() (*http.ServeMux, error) {
+	return nil, errors.New("mux broken")
+}
+
+// failure runs fn with fake testing.TB and returns its fatal message.
+func failure(t *testing.T, fn func(t testing.TB)) (msg string) {
+	ft := &fatalT{TB: t}
+	defer func() {
+		r := recover()
+		require.Equal(t, fatalT{}, r, "fatal expected")
+		msg = ft.msg
+	}()
+	fn(ft)
+	return ""
+}
+
+// fatalT
+type fatalT struct {
+	testing.TB
+	msg string
+}
+
+func (t *fatalT) Helper() {}
+
+func (t *fatalT) Fatalf(format string, args ...interface{}) {
+	t.msg = fmt.Sprintf(format, args...)
+	panic(fatalT{})
+}
@@ -1,6 +1,17 @@
 package inject
 
-import "github.com/defval/inject/v2/di"
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/defval/inject/v2/di"
+)
 
 // OPTIONS
 
@@ -38,12 +49,163 @@ type Option interface {
 //     return server, cleanup, nil
 //   }
 //
+// Constructor could take context.Context as the first parameter. The context is not a dependency: constructors built
+// by Container.BuildContext() receive its context, others receive context.Background(). Context in other positions
+// causes error.
+//
+//   func NewDB(ctx context.Context, config *Config) (*sql.DB, error) {
+//     return dial(ctx, config.DSN)
+//   }
+//
 // Other function signatures will cause error.
+//
+// The provider could be a pointer to struct instead of a constructor. Tagged fields of the struct are set to
+// dependencies on the first resolution and the instance is shared. Fields are tagged like fields of di.Parameter
+// structs. Use OfType() to create a new instance on each resolution.
+//
+//   type Handler struct {
+//     Logger *log.Logger `di:""`
+//   }
+//
+//   inject.Provide(&Handler{})
+//
+// A struct value provides the struct type, its copy with fields set to dependencies is created like a constructor
+// result. Values of other kinds cause panic, register them with Supply(). Struct provider of the type that also has
+// a constructor bypasses the constructor, it is a warning finding of Container.Report().
 func Provide(provider interface{}, options ...ProvideOption) Option {
+	return provideAt(callerLocation(provider), provider, options)
+}
+
+// ProvideIf returns container option that provides provider only if condition returns true. The condition is a
+// function like `func([value1, value2, ...]) bool`, its parameters are values and struct instances provided before, so
+// the decision could depend on provided configuration. Skipped providers are findings of Container.Report().
+//
+//   inject.New(
+//     inject.Provide(cfg),
+//     inject.ProvideIf(func(cfg *Config) bool { return cfg.TracingEnabled }, NewJaegerTracer),
+//   )
+func ProvideIf(condition interface{}, provider interface{}, options ...ProvideOption) Option {
+	options = append(options[:len(options):len(options)], provideOption(func(params *di.ProvideParams) {
+		params.Condition = condition
+	}))
+	return provideAt(callerLocation(provider), provider, options)
+}
+
+// ProvideFrom returns container option that provides late constructor. The constructor computes its result from
+// connected definitions, for example, route table from the group of handlers, and BuildAll() calls it after ordinary
+// constructors. Only other late constructors could depend on its result, extraction and invocation resolve it as
+// usual. Container panics on compile if ordinary constructor depends on late one, the error names both locations.
+//
+//   inject.New(
+//     inject.Provide(NewUsersHandler, inject.As(new(Handler))),
+//     inject.Provide(NewOrdersHandler, inject.As(new(Handler))),
+//     inject.ProvideFrom(func(handlers []Handler) *Router { return NewRouter(handlers...) }),
+//     inject.ProvideFrom(func(router *Router) *http.Server { return &http.Server{Handler: router} }),
+//   )
+func ProvideFrom(constructor interface{}, options ...ProvideOption) Option {
+	location := callerLocation(constructor)
+	if typ := reflect.TypeOf(constructor); typ == nil || typ.Kind() != reflect.Func {
+		panic(fmt.Sprintf("%s: ProvideFrom() requires a constructor function, got %v", location, typ))
+	}
+	options = append(options[:len(options):len(options)], provideOption(func(params *di.ProvideParams) {
+		params.IsLate = true
+	}))
+	return provideAt(location, constructor, options)
+}
+
+// Providers returns container option that provides each constructor like Provide(), so a module could keep its
+// constructors in a slice. Errors of invalid constructor name its index and function like
+// `inject.Providers[3] (func NewFoo)`.
+//
+//   var constructors = []interface{}{NewAccountController, NewAccountRepository}
+//
+//   inject.Providers(constructors...)
+func Providers(providers ...interface{}) Option {
+	var location di.Location
+	if _, file, line, ok := runtime.Caller(1); ok {
+		location = di.Location{File: file, Line: line}
+	}
+	return providersAt(location, "inject.Providers", providers, nil)
+}
+
+// ProvidersWith returns container option that provides each constructor like Provide() with the same options.
+//
+//   inject.ProvidersWith([]inject.ProvideOption{inject.Prototype()}, NewRequestLogger, NewRequestTracer)
+func ProvidersWith(options []ProvideOption, providers ...interface{}) Option {
+	var location di.Location
+	if _, file, line, ok := runtime.Caller(1); ok {
+		location = di.Location{File: file, Line: line}
+	}
+	return providersAt(location, "inject.ProvidersWith", providers, options)
+}
+
+// providersAt returns option that provides each provider with location of the bulk call. Constructors have their own
+// location, members are named by index in the bulk call.
+func providersAt(location di.Location, call string, providers []interface{}, options []ProvideOption) Option {
+	group := make(optionGroup, 0, len(providers))
+	for i, provider := range providers {
+		member := fmt.Sprintf("%s[%d] (%s)", call, i, memberString(provider))
+		if reason := invalidProvider(provider); reason != "" {
+			panic(fmt.Sprintf("%s: %s: %s", location, member, reason))
+		}
+		providerLocation := location
+		if typ := reflect.TypeOf(provider); typ != nil && typ.Kind() == reflect.Func {
+			providerLocation = di.Location{}
+		}
+		group = append(group, provideMember(providerLocation, member, provider, options))
+	}
+	return group
+}
+
+// memberString represents member of bulk call like `func NewFoo` or its type.
+func memberString(provider interface{}) string {
+	typ := reflect.TypeOf(provider)
+	if typ == nil {
+		return "nil"
+	}
+	if typ.Kind() != reflect.Func || reflect.ValueOf(provider).IsNil() {
+		return typ.String()
+	}
+	fn := runtime.FuncForPC(reflect.ValueOf(provider).Pointer())
+	if fn == nil {
+		return typ.String()
+	}
+	name := fn.Name()
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.Index(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return "func " + name
+}
+
+// callerLocation returns location of the caller of provide option if provider is not a constructor. Constructors have
+// their own location.
+func callerLocation(provider interface{}) di.Location {
+	if typ := reflect.TypeOf(provider); typ != nil && typ.Kind() == reflect.Func {
+		return di.Location{}
+	}
+	if _, file, line, ok := runtime.Caller(2); ok {
+		return di.Location{File: file, Line: line}
+	}
+	return di.Location{}
+}
+
+// provideAt returns option that provides provider with location.
+func provideAt(location di.Location, provider interface{}, options []ProvideOption) Option {
+	checkProvider(location, provider)
+	return provideMember(location, "", provider, options)
+}
+
+// provideMember returns option that provides provider with location. Member names provider of bulk call in panics of
+// compile.
+func provideMember(location di.Location, member string, provider interface{}, options []ProvideOption) Option {
 	return option(func(container *Container) {
-		// todo: add provider
 		var params = di.ProvideParams{
-			Parameters: map[string]interface{}{},
+			Parameters:     map[string]interface{}{},
+			Location:       location,
+			ModuleLifetime: container.module,
 		}
 
 		for _, opt := range options {
@@ -52,6 +214,484 @@ func Provide(provider interface{}, options ...ProvideOption) Option {
 		container.providers = append(container.providers, provide{
 			provider: provider,
 			params:   params,
+			profiles: container.profiles,
+			member:   member,
+		})
+	})
+}
+
+// checkProvider panics if provider is not a constructor, a struct, a pointer to struct or a type of OfType(). Values of
+// other kinds are provided by Supply().
+func checkProvider(location di.Location, provider interface{}) {
+	if reason := invalidProvider(provider); reason != "" {
+		panic(fmt.Sprintf("%s: %s", location, reason))
+	}
+}
+
+// invalidProvider returns reason why provider could not be provided or empty string.
+func invalidProvider(provider interface{}) string {
+	if _, ok := provider.(typeTemplate); ok {
+		return ""
+	}
+	typ := reflect.TypeOf(provider)
+	if typ == nil {
+		return "cannot provide nil; pass a constructor function"
+	}
+	switch typ.Kind() {
+	case reflect.Func, reflect.Struct:
+		return ""
+	case reflect.Ptr:
+		if typ.Elem().Kind() == reflect.Struct {
+			return ""
+		}
+	}
+	return fmt.Sprintf("cannot provide %s; wrap it with inject.Supply to register a value, or pass a constructor function", typ)
+}
+
+// Register returns container option that provides constructor under name into registry of its type and of its
+// interfaces. Consumers request registry of type T as map[string]T with all instances or as
+// map[string]func() (T, error) with factories that create instances on call. Names must be unique in a registry,
+// duplicates cause panic with both locations.
+//
+//   inject.Register("csv", NewCSVExporter, inject.As(new(Exporter)))
+//   inject.Register("json", NewJSONExporter, inject.As(new(Exporter)))
+//
+//   func NewExportHandler(exporters map[string]func() (Exporter, error)) *ExportHandler
+//
+// The definition also resolves by name like provided with inject.WithName().
+func Register(name string, provider interface{}, options ...ProvideOption) Option {
+	registry := provideOption(func(params *di.ProvideParams) {
+		params.Name = name
+		params.IsRegistry = true
+	})
+	return provideAt(callerLocation(provider), provider, append([]ProvideOption{registry}, options...))
+}
+
+// OfType returns provider of struct type for Provide(). Each resolution allocates a copy of the template and sets its
+// tagged fields to dependencies, so the definition is a prototype. The definition type is the template type: a struct
+// or a pointer to struct.
+//
+//   inject.Provide(inject.OfType(&Handler{}))
+func OfType(template interface{}) interface{} {
+	return typeTemplate{template: template}
+}
+
+// typeTemplate is a provider of struct type.
+type typeTemplate struct {
+	template interface{}
+}
+
+// DeferCompile returns container option that disables compilation in New(). Definitions could be added later with
+// Container.Apply() or Container.Provide(), the container must be compiled with Container.Compile() before use.
+// Extract() and Invoke() of not compiled container return the error.
+//
+//   container := inject.New(inject.DeferCompile())
+//   container.Provide(NewServer)
+//   container.Compile()
+func DeferCompile() Option {
+	return option(func(container *Container) {
+		container.deferCompile = true
+	})
+}
+
+// Eager returns container option that makes New() run all initialization phases: BuildAll(), Wire() and
+// Start() with background context. If a phase fails, New() runs cleanups of created instances and panics with the
+// error. Use phases explicitly to handle the errors or to pass a context. Eager is ignored with DeferCompile().
+//
+//   inject.New(
+//     inject.Provide(NewServer),
+//     inject.Eager(),
+//   )
+func Eager() Option {
+	return option(func(container *Container) {
+		container.eager = true
+	})
+}
+
+// Prune returns container option that removes definitions not reachable from entry points on compile. Entry points
+// are pointers to types like Extract() targets. Use it to skip optional definitions registered by libraries.
+//
+//   inject.New(
+//     library.Providers(),
+//     inject.Prune(new(*http.Server)),
+//   )
+//
+// Removed definitions are not checked for missing dependencies and cycles. Container panics if an entry point does not
+// exist.
+func Prune(entryPoints ...interface{}) Option {
+	return option(func(container *Container) {
+		container.entryPoints = append(container.entryPoints, entryPoints...)
+	})
+}
+
+// MaxDepth returns container option that sets the limit of resolution chain length. Resolution of longer chain returns
+// di.ErrResolutionTooDeep with the first and the last types of the chain. The default limit is 1000.
+//
+//   inject.New(
+//     generated.Providers(),
+//     inject.MaxDepth(5000),
+//   )
+func MaxDepth(depth int) Option {
+	return option(func(container *Container) {
+		container.maxDepth = depth
+	})
+}
+
+// CompileLog returns container option that writes compile summary: number of definitions, construction order and
+// chosen interface implementations with the reason of the choice.
+//
+//   inject.New(
+//     inject.Provide(NewServer),
+//     inject.CompileLog(os.Stderr),
+//   )
+func CompileLog(w io.Writer) Option {
+	return option(func(container *Container) {
+		container.compileLog = w
+	})
+}
+
+// LogGraph returns container option that writes the resolved graph after compile: one line per definition with its
+// key, lifetime, dependencies, interfaces that resolve to it and location. Lines are sorted, so graphs of two builds
+// could be compared by diff.
+//
+//   inject.New(
+//     inject.Provide(NewServer),
+//     inject.LogGraph(os.Stderr),
+//   )
+//
+//   key="*http.Server" lifetime=singleton dependencies="*log.Logger, http.Handler" implements="" location="main.go:20"
+func LogGraph(w io.Writer) Option {
+	return option(func(container *Container) {
+		container.graphLog = w
+	})
+}
+
+// DefaultLifetime returns container option that sets lifetime of providers without lifetime of provide option or
+// module, see inject.ModuleLifetime(). By default providers are singletons. Provided values are always singletons.
+//
+//   inject.New(
+//     inject.DefaultLifetime(inject.PrototypeLifetime),
+//     inject.Provide(NewCommand),
+//     inject.Provide(NewConnectionPool, inject.Singleton()),
+//   )
+//
+// Effective lifetime and its source are reported by Definitions().
+func DefaultLifetime(lifetime Lifetime) Option {
+	return option(func(container *Container) {
+		container.lifetime = lifetime
+	})
+}
+
+// DevMode returns container option that enables invariant checks for development and tests. Warnings are written to
+// stderr:
+//
+//   - extraction from a constructor on the same goroutine is reported with stacks of the constructor call and the
+//     extraction;
+//   - extraction during compile, for example from a goroutine started by a constructor, returns di.ErrCompiling
+//     instead of waiting for compile;
+//   - change of exported comparable fields of a singleton after it is cached is reported.
+//
+// Checks are slow, without DevMode() they cost nothing.
+//
+//   inject.New(
+//     inject.When(debug, inject.DevMode()),
+//     inject.Provide(NewServer),
+//   )
+func DevMode() Option {
+	return option(func(container *Container) {
+		container.dev = os.Stderr
+	})
+}
+
+// DeprecationLog returns container option that sets writer of deprecation warnings. Warnings are written to stderr by
+// default, nil writer disables them. See inject.Deprecated().
+func DeprecationLog(w io.Writer) Option {
+	return option(func(container *Container) {
+		container.deprecation = w
+	})
+}
+
+// RetryLog returns container option that sets writer of failed attempts of inject.Retry() providers. A line contains
+// the definition key, the attempt number and the error. Attempts are written to stderr by default, nil writer disables
+// them.
+func RetryLog(w io.Writer) Option {
+	return option(func(container *Container) {
+		container.retryLog = w
+	})
+}
+
+// StrictDeprecation returns container option that makes resolution of deprecated definitions fail with
+// di.ErrDeprecated instead of warning. Use it in tests to find dependents of deprecated definitions.
+func StrictDeprecation() Option {
+	return option(func(container *Container) {
+		container.strict = true
+	})
+}
+
+// AutoConvert returns container option that resolves types without definitions by conversion of a definition of
+// convertible type with the same name and tags, for example, time.Duration resolves as instance of
+// `type Timeout time.Duration`. Several convertible definitions fail the resolution. Conversions are written to the
+// compile log and explained by Explain().
+func AutoConvert() Option {
+	return option(func(container *Container) {
+		container.convert = true
+	})
+}
+
+// WithFallbacks returns container option that resolves types missing in the container from fallback containers.
+// Fallbacks are consulted in order, instances come from the container that owns the definition, so singletons are
+// shared. Fallbacks must be created before the container.
+//
+//   host := inject.New(inject.Provide(NewLogger))
+//   plugin := inject.New(
+//     inject.Provide(NewPluginHandler), // func NewPluginHandler(logger *log.Logger) *PluginHandler
+//     inject.WithFallbacks(host),
+//   )
+//
+// Not found error of the container counts consulted fallbacks.
+func WithFallbacks(containers ...*Container) Option {
+	return option(func(container *Container) {
+		container.fallbacks = append(container.fallbacks, containers...)
+	})
+}
+
+// ExplicitBindingsOnly returns container option that resolves interfaces only by definitions bound to exactly the
+// requested interface with inject.As() or inject.Bind(). Without the option, an interface also resolves through
+// provided interfaces that embed it, for example, io.Reader resolves as definition provided as io.ReadWriter.
+//
+//   inject.New(
+//     inject.Provide(NewFileStorage, inject.As(new(io.ReadWriter))),
+//     inject.ExplicitBindingsOnly(),
+//   )
+//
+//   var r io.Reader
+//   container.Extract(&r) // error: *FileStorage implements this interface but is not bound, add As(new(io.Reader))
+func ExplicitBindingsOnly() Option {
+	return option(func(container *Container) {
+		container.explicit = true
+	})
+}
+
+// WithRecover returns container option that sets policy of panics in constructors and invoked functions. By default
+// panics propagate with the original stack. With inject.WrapError they are recovered and returned from Extract() and
+// Invoke() as inject.ErrPanic with the panic value and stack.
+//
+//   inject.New(
+//     inject.Provide(NewServer),
+//     inject.WithRecover(inject.WrapError),
+//   )
+func WithRecover(mode RecoverMode) Option {
+	return option(func(container *Container) {
+		container.recoverMode = mode
+	})
+}
+
+// AllowUnexported returns container option that allows tagged unexported fields of di.Parameter structs. The fields
+// are set with unsafe access that bypasses Go visibility rules, so use it only for legacy types that could not be
+// changed. Without the option, tagged unexported field causes panic.
+//
+//   type ServiceParameters struct {
+//     di.Parameter
+//     repo *Repository `di:""`
+//   }
+//
+//   inject.New(
+//     inject.Provide(NewService),
+//     inject.AllowUnexported(),
+//   )
+func AllowUnexported() Option {
+	return option(func(container *Container) {
+		container.unexported = true
+	})
+}
+
+// Values returns container option that provides named values. Each map entry becomes a definition of value type
+// with name of the map key. Use it for configuration values.
+//
+//   inject.Values(map[string]interface{}{
+//     "listen-addr": ":8080",
+//     "timeout":     5 * time.Second,
+//   })
+//
+//   type ServerParameters struct {
+//     di.Parameter
+//     Addr    string        `di:"listen-addr"`
+//     Timeout time.Duration `di:"timeout"`
+//   }
+//
+// Values registered in order of sorted keys.
+func Values(values map[string]interface{}) Option {
+	var location di.Location
+	if _, file, line, ok := runtime.Caller(1); ok {
+		location = di.Location{File: file, Line: line}
+	}
+	return option(func(container *Container) {
+		names := make([]string, 0, len(values))
+		for name := range values {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			container.providers = append(container.providers, provide{
+				provider: values[name],
+				params:   di.ProvideParams{Name: name, Location: location},
+				value:    true,
+				profiles: container.profiles,
+			})
+		}
+	})
+}
+
+// Supply returns container option that provides already created value. The value type is the definition type, the
+// value is shared like a singleton instance. Provide options are supported, so the value could be named and bound
+// to interfaces at once:
+//
+//   inject.Supply(&Config{Addr: ":8080"})
+//   inject.Supply(":8080", inject.WithName("addr"))
+//   inject.Supply(pool, inject.WithName("primary"), inject.As(new(Querier)), inject.WithCleanup(pool.Close))
+//
+// Supply panics immediately if the value does not implement interfaces of inject.As() or is a nil pointer bound to
+// interfaces. The definition behaves like a constructor without parameters: it is a graph node and its cleanup set by
+// inject.WithCleanup() runs in order of creation with cleanups of constructors.
+func Supply(value interface{}, options ...ProvideOption) Option {
+	var location di.Location
+	if _, file, line, ok := runtime.Caller(1); ok {
+		location = di.Location{File: file, Line: line}
+	}
+	return supplyAt(location, value, options)
+}
+
+// supplyAt returns option that provides value with location.
+func supplyAt(location di.Location, value interface{}, options []ProvideOption) Option {
+	var params di.ProvideParams
+	for _, opt := range options {
+		opt.apply(&params)
+	}
+	checkSupplied(location, value, params.Interfaces)
+	return option(func(container *Container) {
+		var params = di.ProvideParams{}
+		for _, opt := range options {
+			opt.apply(&params)
+		}
+		params.Location = location
+		container.providers = append(container.providers, provide{
+			provider: value,
+			params:   params,
+			value:    true,
+			profiles: container.profiles,
+		})
+	})
+}
+
+// checkSupplied validates supplied value against interfaces of inject.As().
+func checkSupplied(location di.Location, value interface{}, interfaces []interface{}) {
+	typ := reflect.TypeOf(value)
+	if typ == nil {
+		panic(fmt.Sprintf("%s: supplied value must not be nil, use typed value instead", location))
+	}
+	for _, iface := range interfaces {
+		ifaceType := reflect.TypeOf(iface)
+		if ifaceType == nil || ifaceType.Kind() != reflect.Ptr || ifaceType.Elem().Kind() != reflect.Interface {
+			panic(fmt.Sprintf("%s: %s: As() argument must be a pointer to interface, got `%v`", location, typ, ifaceType))
+		}
+		if !typ.Implements(ifaceType.Elem()) {
+			panic(fmt.Sprintf("%s: supplied %s does not implement %s", location, typ, ifaceType.Elem()))
+		}
+		if typ.Kind() == reflect.Ptr && reflect.ValueOf(value).IsNil() {
+			panic(fmt.Sprintf("%s: supplied %s bound to %s is nil pointer", location, typ, ifaceType.Elem()))
+		}
+	}
+}
+
+// Append returns container option that provides slice or map value appendable by other modules. Appended values of
+// the same type and name accumulate: slices are concatenated in registration order, maps are merged.
+//
+//   inject.Append([]Migration{CreateUsers, CreateOrders}) // users module
+//   inject.Append([]Migration{CreatePayments})            // payments module
+//
+//   var migrations []Migration
+//   container.Extract(&migrations) // [CreateUsers CreateOrders CreatePayments]
+//
+// Container panics if appended maps have the same key or the type is provided without Append().
+func Append(value interface{}, options ...ProvideOption) Option {
+	var location di.Location
+	if _, file, line, ok := runtime.Caller(1); ok {
+		location = di.Location{File: file, Line: line}
+	}
+	return option(func(container *Container) {
+		var params = di.ProvideParams{}
+		for _, opt := range options {
+			opt.apply(&params)
+		}
+		params.IsAppend = true
+		params.Location = location
+		container.providers = append(container.providers, provide{
+			provider: value,
+			params:   params,
+			value:    true,
+			profiles: container.profiles,
+		})
+	})
+}
+
+// Replace returns container option that replaces the definition of the same type and name. Use it to substitute
+// dependencies in tests. The replacement keeps place of the replaced definition in groups.
+//
+//   container := inject.New(
+//     inject.Provide(NewClock),
+//     inject.Replace(NewFakeClock), // func NewFakeClock() *Clock
+//   )
+//
+// A replacement of other type replaces the binding of its interface, the result type or inject.As() interface
+// selects it. Types that depend on the interface receive the replacement.
+//
+//   inject.Replace(func() Clock { return &FakeClock{} })
+//   inject.Replace(NewFakeClock, inject.As(new(Clock))) // func NewFakeClock() *FakeClock
+//
+// Replace causes panic if neither the replaced definition nor the replaced interface binding exists.
+func Replace(provider interface{}, options ...ProvideOption) Option {
+	// the full slice expression copies options on append, so the slice of the caller is not changed
+	options = append(options[:len(options):len(options)], provideOption(func(provider *di.ProvideParams) {
+		provider.IsReplacement = true
+	}))
+	return Provide(provider, options...)
+}
+
+// Bind returns container option that binds interface to provided type. Use it if you can not add inject.As() to
+// provide option, for example, provider declared in other package. The first argument is a pointer to interface,
+// the second argument is a value of the provided type.
+//
+//   inject.New(
+//     inject.Provide(NewPostgresRepository), // func NewPostgresRepository() *PostgresRepository
+//     inject.Bind(new(UserRepository), new(PostgresRepository)),
+//   )
+//
+// The name of inject.Name() option names the interface key, so the role of unnamed definition is named by binding.
+// Named bindings resolve by inject.Name() extract option and by name of parameter field.
+//
+//   inject.New(
+//     inject.Provide(NewPostgresRepository),
+//     inject.Provide(NewS3Repository),
+//     inject.Bind(new(Repository), new(PostgresRepository), inject.Name("primary")),
+//     inject.Bind(new(Repository), new(S3Repository), inject.Name("audit")),
+//   )
+//
+// Binds processed after all providers, so the option order does not matter. Container panics if bound type not
+// provided or does not implement the interface. Bind supports only inject.Name() option, others cause panic.
+func Bind(iface interface{}, implementation interface{}, options ...ExtractOption) Option {
+	var params di.ExtractParams
+	for _, opt := range options {
+		opt.apply(&params)
+	}
+	if len(params.Tags) != 0 || params.IsFresh || params.Group != "" || params.Type != nil {
+		panic("inject.Bind() supports only inject.Name() option")
+	}
+	return option(func(container *Container) {
+		container.binds = append(container.binds, bind{
+			iface:          iface,
+			implementation: implementation,
+			name:           params.Name,
+			profiles:       container.profiles,
 		})
 	})
 }
@@ -73,10 +713,91 @@ func Provide(provider interface{}, options ...ProvideOption) Option {
 //     authBundle,
 //   )
 func Bundle(options ...Option) Option {
+	return Options(options...)
+}
+
+// Options returns container option that groups options into one, so a module could return a single option. Nested
+// groups are flattened: options are applied in order of registration and keep locations of their calls.
+//
+//   func Module() inject.Option {
+//     return inject.Options(
+//       inject.Provide(NewAccountController),
+//       inject.Provide(NewAccountRepository),
+//     )
+//   }
+func Options(options ...Option) Option {
+	return optionGroup(options)
+}
+
+// When returns container option that applies options only if condition is true.
+//
+//   inject.New(
+//     inject.Provide(NewServer),
+//     inject.When(cfg.Debug,
+//       inject.Provide(NewDebugHandler, inject.As(new(http.Handler))),
+//     ),
+//   )
+func When(condition bool, options ...Option) Option {
+	if !condition {
+		return optionGroup(nil)
+	}
+	return optionGroup(options)
+}
+
+// Profile returns container option that adds providers and binds of options only if the profile is active, see
+// inject.ActiveProfiles(). Providers outside of profiles are always added. Providers of inactive profiles are dropped
+// before compile, so their dependencies are not required. Providers of nested profiles are added if all of the
+// profiles are active.
+//
+//   inject.New(
+//     inject.Profile("dev", inject.Provide(NewInMemoryQueue, inject.As(new(Queue)))),
+//     inject.Profile("prod", inject.Provide(NewSQSQueue, inject.As(new(Queue)))),
+//     inject.ActiveProfiles(os.Getenv("PROFILE")),
+//   )
+//
+// If providers of the same type are added from different profiles, the container panics with both profiles.
+func Profile(name string, options ...Option) Option {
 	return option(func(container *Container) {
+		outer := container.profiles
+		container.profiles = append(outer[:len(outer):len(outer)], name)
 		for _, opt := range options {
 			opt.apply(container)
 		}
+		container.profiles = outer
+	})
+}
+
+// ModuleLifetime returns container option that sets default lifetime of providers of options. It overrides the
+// container default of inject.DefaultLifetime(), inject.Prototype() and inject.Singleton() provide options override
+// it. The innermost module lifetime is used for nested modules.
+//
+//   inject.New(
+//     inject.DefaultLifetime(inject.PrototypeLifetime),
+//     inject.ModuleLifetime(inject.SingletonLifetime,
+//       inject.Provide(NewConnectionPool),
+//       inject.Provide(NewQueryCache),
+//     ),
+//   )
+func ModuleLifetime(lifetime Lifetime, options ...Option) Option {
+	return option(func(container *Container) {
+		outer := container.module
+		container.module = &lifetime
+		for _, opt := range options {
+			opt.apply(container)
+		}
+		container.module = outer
+	})
+}
+
+// ActiveProfiles returns container option that activates profiles, see inject.Profile().
+func ActiveProfiles(names ...string) Option {
+	return option(func(container *Container) {
+		if container.active == nil {
+			container.active = map[string]bool{}
+		}
+		for _, name := range names {
+			container.active[name] = true
+		}
 	})
 }
 
@@ -91,9 +812,32 @@ type ProvideOption interface {
 //   inject.Provide(&http.Server{}, inject.WithName("second"))
 //
 //   container.Extract(&server, inject.Name("second"))
+//
+// Several names register the definition under each of them. The names share the same instance.
+//
+//   inject.Provide(NewClient, inject.WithName("internal"), inject.WithName("external"))
 func WithName(name string) ProvideOption {
 	return provideOption(func(provider *di.ProvideParams) {
-		provider.Name = name
+		if provider.Name == "" {
+			provider.Name = name
+			return
+		}
+		provider.Names = append(provider.Names, name)
+	})
+}
+
+// WithTag adds key/value tag to the provided definition. Definitions of the same type can differ by tags only.
+//
+//   inject.Provide(NewPremiumClient, inject.WithTag("tier", "premium"))
+//   inject.Provide(NewBasicClient, inject.WithTag("tier", "basic"))
+//
+//   container.Extract(&client, inject.Tag("tier", "premium"))
+func WithTag(key, value string) ProvideOption {
+	return provideOption(func(provider *di.ProvideParams) {
+		if provider.Tags == nil {
+			provider.Tags = di.Tags{}
+		}
+		provider.Tags[key] = value
 	})
 }
 
@@ -110,7 +854,62 @@ func WithName(name string) ProvideOption {
 func As(ifaces ...interface{}) ProvideOption {
 	return provideOption(func(provider *di.ProvideParams) {
 		provider.Interfaces = append(provider.Interfaces, ifaces...)
+	})
+}
 
+// IntoGroup adds the definition into named collection. The definition is a member of the collection of its type and
+// of each interface of As(). Members are created when the collection resolves and sorted like other groups.
+//
+//   inject.Provide(NewAuthMiddleware, inject.As(new(Middleware)), inject.IntoGroup("middleware"))
+//   inject.Provide(NewLogMiddleware, inject.As(new(Middleware)), inject.IntoGroup("middleware"))
+//
+//   type RouterParameters struct {
+//     di.Parameter
+//     Middlewares []Middleware `group:"middleware"`
+//   }
+//
+// Collection without members resolves as empty slice. Named members of collection also resolve as map where key is
+// a member name, members without name are not included into the map.
+//
+//   inject.Provide(NewJSONCodec, inject.As(new(Codec)), inject.WithName("json"), inject.IntoGroup("codecs"))
+//
+//   type RegistryParameters struct {
+//     di.Parameter
+//     Codecs map[string]Codec `group:"codecs"`
+//   }
+//
+// Container panics on compile if map members have the same name.
+func IntoGroup(name string) ProvideOption {
+	return provideOption(func(provider *di.ProvideParams) {
+		provider.Groups = append(provider.Groups, name)
+	})
+}
+
+// Exclusively makes the definition reachable only through interfaces of As(). The constructor result type itself does
+// not resolve, so dependents have to depend on abstractions.
+//
+//   inject.Provide(NewPostgresRepository, inject.As(new(UserRepository)), inject.Exclusively())
+//
+//   var repository UserRepository
+//   container.Extract(&repository) // ok
+//
+//   var postgres *PostgresRepository
+//   container.Extract(&postgres) // error: definition provided exclusively as UserRepository
+func Exclusively() ProvideOption {
+	return provideOption(func(provider *di.ProvideParams) {
+		provider.IsExclusive = true
+	})
+}
+
+// Dedup treats provide of the same constructor with the same options as the definition provided before instead of
+// duplicate error. Modules could provide shared dependencies without coordination. Different constructor of the type
+// still causes panic with locations of both constructors.
+//
+//   accountModule := inject.Bundle(inject.Provide(NewLogger, inject.Dedup()), inject.Provide(NewAccountService))
+//   authModule := inject.Bundle(inject.Provide(NewLogger, inject.Dedup()), inject.Provide(NewAuthService))
+func Dedup() ProvideOption {
+	return provideOption(func(provider *di.ProvideParams) {
+		provider.IsDedup = true
 	})
 }
 
@@ -128,6 +927,145 @@ func Prototype() ProvideOption {
 	})
 }
 
+// WithCleanup sets cleanup of value provided by inject.Supply(). The cleanup runs on container cleanup if the value was
+// resolved, in order of creation like cleanups of constructors.
+//
+//   inject.Supply(pool, inject.WithCleanup(pool.Close))
+func WithCleanup(cleanup func()) ProvideOption {
+	return provideOption(func(provider *di.ProvideParams) {
+		provider.Cleanup = cleanup
+	})
+}
+
+// Singleton pins singleton lifetime of the provider regardless of inject.DefaultLifetime() and
+// inject.ModuleLifetime(). Use it for types that must be shared, like connection pools.
+//
+//   inject.Provide(NewConnectionPool, inject.Singleton())
+func Singleton() ProvideOption {
+	return provideOption(func(provider *di.ProvideParams) {
+		provider.IsSingleton = true
+	})
+}
+
+// RetryOnError calls the constructor again on the next resolution if it returned an error. By default, singleton
+// caches the error and returns it without calling the constructor. Use it for types that depend on external resources.
+//
+//   inject.Provide(NewDatabaseConnection, inject.RetryOnError()) // database could be temporarily unavailable
+//
+// Concurrent resolutions wait for the running constructor call.
+func RetryOnError() ProvideOption {
+	return provideOption(func(provider *di.ProvideParams) {
+		provider.IsRetryOnError = true
+	})
+}
+
+// Retry calls the constructor up to attempts times inside one resolution until it succeeds. Calls are separated by
+// backoff delay. If all attempts failed, the error wraps the last one. Use it for components that wait for a
+// dependency at startup. Failed attempts are written to the retry log, see inject.RetryLog(). Cancellation of the
+// context of Container.BuildContext() aborts waiting between attempts.
+//
+//   inject.Provide(NewDatabaseConnection, inject.Retry(5, 100*time.Millisecond), inject.ExponentialBackoff())
+//
+// The error of the last attempt is cached like any constructor error, see inject.RetryOnError().
+func Retry(attempts int, backoff time.Duration) ProvideOption {
+	return provideOption(func(provider *di.ProvideParams) {
+		provider.RetryAttempts = attempts
+		provider.RetryBackoff = backoff
+	})
+}
+
+// ExponentialBackoff doubles the delay of inject.Retry() after each failed attempt.
+func ExponentialBackoff() ProvideOption {
+	return provideOption(func(provider *di.ProvideParams) {
+		provider.IsExponentialBackoff = true
+	})
+}
+
+// Deprecated marks the definition as deprecated. Unused deprecated definitions are fine, the first resolution writes a
+// warning with the message and the dependent definition, so library authors could give a migration path.
+//
+//   inject.Provide(NewClient, inject.Deprecated("use NewClientV2, NewClient is removed in v3"))
+//   // client.go:12: *Client is deprecated: use NewClientV2, NewClient is removed in v3, required by *Service
+func Deprecated(message string) ProvideOption {
+	return provideOption(func(provider *di.ProvideParams) {
+		provider.Deprecated = message
+	})
+}
+
+// Setter calls the method of the provided type with resolved arguments after the instance is created. Setter arguments
+// are not dependencies of the constructor, so types could refer to each other without a dependency cycle.
+//
+//   inject.Provide(NewOrders, inject.Setter("SetNotifier"))  // func (o *Orders) SetNotifier(n *Notifier)
+//   inject.Provide(NewNotifier, inject.Setter("SetOrders"))  // func (n *Notifier) SetOrders(o *Orders)
+//
+// The setter could return an error. Container panics if the type has no such method or setter arguments are not
+// provided. The instance is available to other types before its setters are called, do not use setter dependencies
+// in constructors.
+func Setter(method string) ProvideOption {
+	return provideOption(func(provider *di.ProvideParams) {
+		provider.Setters = append(provider.Setters, method)
+	})
+}
+
+// AllowNil allows providing nil channel value with inject.Supply(). Operations on nil channel block forever, so it is
+// an error by default, nil channels of inject.Values() cause panic too.
+func AllowNil() ProvideOption {
+	return provideOption(func(provider *di.ProvideParams) {
+		provider.IsAllowNil = true
+	})
+}
+
+// AllowCopy allows provided type that contains sync or sync/atomic types by value, for example, a struct with
+// sync.Mutex. Each consumer gets a copy of the instance and of the lock, so such types cause panic by default. Use it
+// for types that are safe to copy, for example, types that do not use the lock before they are provided.
+//
+//   inject.Provide(NewCounter) // func NewCounter() Counter: provide *Counter instead of Counter: Counter contains sync.Mutex
+//   inject.Provide(NewCounter, inject.AllowCopy())
+func AllowCopy() ProvideOption {
+	return provideOption(func(provider *di.ProvideParams) {
+		provider.IsAllowCopy = true
+	})
+}
+
+// Description sets a human readable purpose of the definition. Types alone do not always explain intent, the
+// description is shown by dumps, graph, Explain() and ambiguity errors.
+//
+//   inject.Provide(NewPaymentsClient, inject.Description("payments API client, mTLS, 5s timeout"))
+//   // Doer: have several implementations: *PaymentsClient (payments API client, mTLS, 5s timeout), *SearchClient
+func Description(text string) ProvideOption {
+	return provideOption(func(provider *di.ProvideParams) {
+		provider.Description = text
+	})
+}
+
+// Primary marks the definition as a primary implementation of its interfaces. If an interface has several
+// implementations, the primary one is used for the interface resolution. Groups are not affected.
+//
+//   inject.Provide(NewPostgresRepository, inject.As(new(Repository)), inject.Primary())
+//   inject.Provide(NewMemoryRepository, inject.As(new(Repository)))
+//
+//   var repository Repository
+//   container.Extract(&repository) // *PostgresRepository
+func Primary() ProvideOption {
+	return provideOption(func(provider *di.ProvideParams) {
+		provider.IsPrimary = true
+	})
+}
+
+// Order sets the position of the definition in interface groups. Definitions with lower order go first, definitions
+// with equal order keep the registration order. Default order is 0.
+//
+//   inject.Provide(NewAuthMiddleware, inject.As(new(Middleware)), inject.Order(1))
+//   inject.Provide(NewLogMiddleware, inject.As(new(Middleware)), inject.Order(0))
+//
+//   var middlewares []Middleware
+//   container.Extract(&middlewares) // [*LogMiddleware, *AuthMiddleware]
+func Order(order int) ProvideOption {
+	return provideOption(func(provider *di.ProvideParams) {
+		provider.Order = order
+	})
+}
+
 // ParameterBag is a provider parameter bag. It stores a construction parameters. It is a alternative way to
 // configure type.
 //
@@ -162,10 +1100,124 @@ func Name(name string) ExtractOption {
 	})
 }
 
+// OfReflectType extracts definition of type known at runtime only. The target must be a pointer to a type the definition
+// type is assignable to, for example, pointer to empty interface.
+//
+//   var handler interface{}
+//   container.Extract(&handler, inject.OfReflectType(reflect.TypeOf(&UserHandler{})))
+func OfReflectType(typ reflect.Type) ExtractOption {
+	return extractOption(func(eo *di.ExtractParams) {
+		eo.Type = typ
+	})
+}
+
+// Tag selects definition that has the tag. Several tags are intersected: the definition must have all of them.
+// If several definitions match, the primary one is used. Definition with exactly requested tags has priority.
+//
+//   container.Extract(&client, inject.Tag("tier", "premium"), inject.Tag("region", "eu"))
+func Tag(key, value string) ExtractOption {
+	return extractOption(func(eo *di.ExtractParams) {
+		if eo.Tags == nil {
+			eo.Tags = di.Tags{}
+		}
+		eo.Tags[key] = value
+	})
+}
+
+// Group extracts named collection into slice or map target. See inject.IntoGroup().
+//
+//   var middlewares []Middleware
+//   container.Extract(&middlewares, inject.Group("middleware"))
+func Group(name string) ExtractOption {
+	return extractOption(func(eo *di.ExtractParams) {
+		eo.Group = name
+	})
+}
+
+// Fresh creates a new instance of the extracted definition instead of a cached singleton. The cached singleton and
+// other consumers are not affected. Dependencies of the fresh instance are resolved as usual: singletons come from
+// the cache, prototypes are created.
+//
+//   var client *http.Client
+//   container.Extract(&client, inject.Fresh()) // one-off client
+//
+// Cleanup of the fresh instance runs on container cleanup.
+func Fresh() ExtractOption {
+	return extractOption(func(eo *di.ExtractParams) {
+		eo.IsFresh = true
+	})
+}
+
+// BuildOption modifies default build behavior. It is passed to Build() among extras. See inject.PreferExtras().
+type BuildOption interface {
+	apply(params *di.BuildParams)
+}
+
+// BUILD OPTIONS.
+
+// PreferExtras fills build parameters from extras even if their types resolve from the container.
+//
+//   processor, err := container.Build(NewOrderProcessor, testDB, orderID, inject.PreferExtras())
+func PreferExtras() BuildOption {
+	return buildOption(func(params *di.BuildParams) {
+		params.IsPreferExtras = true
+	})
+}
+
+// DumpOption modifies default dump behavior. See inject.Verbose().
+type DumpOption interface {
+	apply(params *di.DumpParams)
+}
+
+// DUMP OPTIONS.
+
+// Verbose prints full type names in the dump instead of truncated ones.
+func Verbose() DumpOption {
+	return dumpOption(func(params *di.DumpParams) {
+		params.IsVerbose = true
+	})
+}
+
+// ReleaseOption modifies default release behavior. See inject.RejectResolution().
+type ReleaseOption interface {
+	apply(params *di.ReleaseParams)
+}
+
+// RELEASE OPTIONS.
+
+// RejectResolution fails resolutions that create instances after ReleaseInstances() with di.ErrReleased instead of
+// creating instances again.
+func RejectResolution() ReleaseOption {
+	return releaseOption(func(params *di.ReleaseParams) {
+		params.IsRejectResolution = true
+	})
+}
+
 type option func(container *Container)
 
 func (o option) apply(container *Container) { o(container) }
 
+// optionGroup is a group of options, see Options().
+type optionGroup []Option
+
+func (g optionGroup) apply(container *Container) {
+	for _, opt := range g.flatten(nil) {
+		opt.apply(container)
+	}
+}
+
+// flatten appends options of the group and its nested groups to flat in order of registration.
+func (g optionGroup) flatten(flat []Option) []Option {
+	for _, opt := range g {
+		if group, ok := opt.(optionGroup); ok {
+			flat = group.flatten(flat)
+			continue
+		}
+		flat = append(flat, opt)
+	}
+	return flat
+}
+
 type provideOption func(provider *di.ProvideParams)
 
 func (o provideOption) apply(provider *di.ProvideParams) { o(provider) }
@@ -174,6 +1226,18 @@ type extractOption func(eo *di.ExtractParams)
 
 func (o extractOption) apply(eo *di.ExtractParams) { o(eo) }
 
+type buildOption func(params *di.BuildParams)
+
+func (o buildOption) apply(params *di.BuildParams) { o(params) }
+
+type dumpOption func(params *di.DumpParams)
+
+func (o dumpOption) apply(params *di.DumpParams) { o(params) }
+
+type releaseOption func(params *di.ReleaseParams)
+
+func (o releaseOption) apply(params *di.ReleaseParams) { o(params) }
+
 type extractOptions struct {
 	name   string
 	target interface{}
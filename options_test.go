@@ -3,6 +3,7 @@ package inject
 import (
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -19,6 +20,14 @@ func TestProvideOptions(t *testing.T) {
 		WithName("test"),
 		As(new(http.Handler)),
 		Prototype(),
+		Order(1),
+		Primary(),
+		RetryOnError(),
+		Retry(3, time.Second),
+		ExponentialBackoff(),
+		Exclusively(),
+		IntoGroup("middleware"),
+		WithTag("tier", "premium"),
 		ParameterBag{
 			"test": "test",
 		},
@@ -27,25 +36,60 @@ func TestProvideOptions(t *testing.T) {
 	}
 
 	require.Equal(t, &di.ProvideParams{
-		Name:        "test",
-		Interfaces:  []interface{}{new(http.Handler)},
-		IsPrototype: true,
+		Name:                 "test",
+		Interfaces:           []interface{}{new(http.Handler)},
+		IsPrototype:          true,
+		Order:                1,
+		IsPrimary:            true,
+		IsRetryOnError:       true,
+		RetryAttempts:        3,
+		RetryBackoff:         time.Second,
+		IsExponentialBackoff: true,
+		IsExclusive:          true,
+		Groups:               []string{"middleware"},
+		Tags:                 di.Tags{"tier": "premium"},
 		Parameters: map[string]interface{}{
 			"test": "test",
 		},
 	}, opts)
 }
 
+func TestReplaceKeepsOptions(t *testing.T) {
+	shared := make([]ProvideOption, 1, 2)
+	shared[0] = WithName("test")
+	Replace(func() *http.ServeMux { return &http.ServeMux{} }, shared...)
+	require.Nil(t, shared[:2][1])
+}
+
+func TestDumpOptions(t *testing.T) {
+	opts := &di.DumpParams{}
+
+	for _, opt := range []DumpOption{
+		Verbose(),
+	} {
+		opt.apply(opts)
+	}
+
+	require.Equal(t, &di.DumpParams{IsVerbose: true}, opts)
+}
+
 func TestExtractOptions(t *testing.T) {
 	opts := &di.ExtractParams{}
 
 	for _, opt := range []ExtractOption{
 		Name("test"),
+		Tag("tier", "premium"),
+		Tag("region", "eu"),
+		Fresh(),
+		Group("middleware"),
 	} {
 		opt.apply(opts)
 	}
 
 	require.Equal(t, &di.ExtractParams{
-		Name: "test",
+		Name:    "test",
+		Tags:    di.Tags{"tier": "premium", "region": "eu"},
+		IsFresh: true,
+		Group:   "middleware",
 	}, opts)
 }
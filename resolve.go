@@ -0,0 +1,54 @@
+package inject
+
+import (
+	"errors"
+
+	"github.com/defval/inject/v2/di"
+)
+
+// Resolve extracts instance of type T.
+//
+//   server, err := inject.Resolve[*http.Server](container)
+func Resolve[T any](r Resolver, options ...ExtractOption) (T, error) {
+	var value T
+	err := r.Extract(&value, options...)
+	return value, err
+}
+
+// ResolveNamed extracts instance of type T of definition with name like Resolve() with Name() option.
+//
+//   primary, err := inject.ResolveNamed[*sql.DB](container, "primary")
+func ResolveNamed[T any](r Resolver, name string, options ...ExtractOption) (T, error) {
+	return Resolve[T](r, append(options[:len(options):len(options)], Name(name))...)
+}
+
+// TryResolve extracts instance of type T and reports whether T exists in the container. If T is not provided,
+// TryResolve returns false and nil error. Errors of instance building are returned with true.
+//
+//   tracer, ok, err := inject.TryResolve[Tracer](container)
+func TryResolve[T any](r Resolver, options ...ExtractOption) (T, bool, error) {
+	var value T
+	err := r.Extract(&value, options...)
+	if notProvided(err) {
+		return value, false, nil
+	}
+	return value, true, err
+}
+
+// ResolveOr extracts instance of type T or returns fallback if T is not provided. The fallback is not provided into
+// the container.
+//
+//   tracer, err := inject.ResolveOr[Tracer](container, noopTracer{})
+func ResolveOr[T any](r Resolver, fallback T, options ...ExtractOption) (T, error) {
+	value, ok, err := TryResolve[T](r, options...)
+	if !ok {
+		return fallback, nil
+	}
+	return value, err
+}
+
+// notProvided checks that error is caused by the extracted type itself, not by its dependency.
+func notProvided(err error) bool {
+	var notFound ErrNotFound
+	return errors.As(err, &notFound) && len(di.DependencyPath(err)) == 1
+}